@@ -0,0 +1,62 @@
+// Command dev-id-token mints an ID token for offline development, to use in place of a Google ID
+// token when the API server runs with IDENTITY_KEYS_DIR.
+//
+//	go run ./cmd/dev-id-token -email juan_delacruz@dlsu.edu.ph
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/golang-jwt/jwt/v5"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the account to sign in as")
+	audience := flag.String("aud", os.Getenv("GOOGLE_CLIENT_ID"), "client ID the token is issued to")
+	hostedDomain := flag.String("hd", "", "hosted domain of the account (defaults to the domain of -email)")
+	unverified := flag.Bool("unverified", false, "mark the email as not verified")
+	lifetime := flag.Duration("ttl", time.Hour, "how long the token is valid")
+	flag.Parse()
+
+	dir := os.Getenv("IDENTITY_KEYS_DIR")
+	if dir == "" {
+		log.Fatal("IDENTITY_KEYS_DIR is not set")
+	}
+	if *email == "" || *audience == "" {
+		log.Fatal("-email and -aud (or GOOGLE_CLIENT_ID) are required")
+	}
+	if *hostedDomain == "" {
+		if _, domain, ok := strings.Cut(*email, "@"); ok && domain != "gmail.com" {
+			*hostedDomain = domain
+		}
+	}
+
+	keys, err := auth.LoadKeySet(dir, "")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	now := time.Now()
+	token, err := keys.Sign(&auth.IDTokenClaims{
+		Email:         *email,
+		EmailVerified: !*unverified,
+		HostedDomain:  *hostedDomain,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   *email,
+			Audience:  jwt.ClaimStrings{*audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(*lifetime)),
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,168 @@
+// Command roster-import compares a roster spreadsheet against the members and prints what would
+// change. With -apply it imports the roster in one transaction: new members are added, changed
+// members are updated, and active members missing from the roster are deactivated. A roster that
+// is empty or deactivates most of the members is refused unless -allow-mass-removal is passed.
+//
+//	go run ./cmd/roster-import -file roster.xlsx
+//	go run ./cmd/roster-import -file roster.xlsx -apply -actor admin@dlsu.edu.ph
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/roster"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	file := flag.String("file", "", "roster file to import (.csv or .xlsx)")
+	format := flag.String("format", "", "format of the file, csv or xlsx (default: from the file extension)")
+	apply := flag.Bool("apply", false, "import the roster instead of only printing the diff")
+	allowMassRemoval := flag.Bool("allow-mass-removal", false, fmt.Sprintf("apply a roster that is empty or deactivates more than %.0f%% of the active members", roster.MaxRemovedShare*100))
+	actor := flag.String("actor", "", "email of the admin running the import, for the audit log (required with -apply)")
+	flag.Parse()
+
+	if *file == "" {
+		log.Fatal("-file is required")
+	}
+	if *apply && *actor == "" {
+		log.Fatal("-actor is required with -apply")
+	}
+	if *format == "" {
+		*format = roster.FormatOf(*file, "")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	t, err := roster.Read(f, *format)
+	f.Close()
+	if err != nil {
+		log.Fatalf("failed to read roster: %v", err)
+	}
+
+	db := database.New()
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	tx, err := db.GetConnection().BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	q := repository.New(tx)
+
+	diff, err := roster.Compare(ctx, q, t)
+	if err != nil {
+		var fileErr *roster.FileError
+		if errors.As(err, &fileErr) {
+			log.Fatalf("invalid roster: %v", fileErr)
+		}
+		log.Fatalf("failed to compare roster: %v", err)
+	}
+
+	if err := printDiff(diff); err != nil {
+		log.Fatal(err)
+	}
+	if diff.HasErrors() {
+		log.Fatalf("roster has %d errors; nothing was imported", len(diff.Errors))
+	}
+	if !*apply {
+		fmt.Println("\nDry run; pass -apply to import.")
+		return
+	}
+
+	if err := roster.Apply(ctx, q, diff, *allowMassRemoval); err != nil {
+		if errors.Is(err, roster.ErrMassRemoval) {
+			log.Fatalf("roster is empty or deactivates too many members (%d); check the file, or pass -allow-mass-removal", len(diff.Removed))
+		}
+		log.Fatalf("failed to import roster: %v", err)
+	}
+	if err := audit.Record(ctx, q, audit.Event{
+		ActorEmail: *actor,
+		Action:     "members.import",
+		Route:      "cmd/roster-import",
+		Outcome:    audit.OutcomeSuccess,
+		Details:    diff.Summary(),
+	}); err != nil {
+		log.Fatalf("failed to record audit event: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("failed to commit roster import: %v", err)
+	}
+	fmt.Println("\nRoster imported.")
+}
+
+func printDiff(d *roster.Diff) error {
+	fmt.Printf("%d new, %d changed, %d removed, %d unchanged\n", len(d.New), len(d.Changed), len(d.Removed), d.Unchanged)
+	if len(d.IgnoredColumns) > 0 {
+		fmt.Printf("Ignored columns: %s\n", strings.Join(d.IgnoredColumns, ", "))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(d.Errors) > 0 {
+		fmt.Fprintln(w, "\nROW\tCOLUMN\tERROR")
+		for _, e := range d.Errors {
+			fmt.Fprintf(w, "%d\t%s\t%s\n", e.Row, e.Column, e.Error)
+		}
+	}
+	if len(d.New) > 0 {
+		fmt.Fprintln(w, "\nNEW\tID\tEMAIL\tNAME")
+		for _, e := range d.New {
+			fmt.Fprintf(w, "+\t%d\t%s\t%s\n", e.ID, e.Email, e.FullName)
+		}
+	}
+	if len(d.Changed) > 0 {
+		fmt.Fprintln(w, "\nCHANGED\tID\tEMAIL\tCHANGES")
+		for _, e := range d.Changed {
+			fmt.Fprintf(w, "~\t%d\t%s\t%s\n", e.ID, e.Email, describeChanges(e))
+		}
+	}
+	if len(d.Removed) > 0 {
+		fmt.Fprintln(w, "\nREMOVED\tID\tEMAIL\tNAME")
+		for _, e := range d.Removed {
+			fmt.Fprintf(w, "-\t%d\t%s\t%s\n", e.ID, e.Email, e.FullName)
+		}
+	}
+	return w.Flush()
+}
+
+func describeChanges(e roster.Entry) string {
+	var parts []string
+	if e.Reactivated {
+		parts = append(parts, "reactivated")
+	}
+	fields := make([]string, 0, len(e.Changes))
+	for field := range e.Changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		c := e.Changes[field]
+		parts = append(parts, fmt.Sprintf("%s: %s -> %s", field, show(c.From), show(c.To)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func show(s *string) string {
+	if s == nil {
+		return "(empty)"
+	}
+	return fmt.Sprintf("%q", *s)
+}
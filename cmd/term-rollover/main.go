@@ -0,0 +1,129 @@
+// Command term-rollover records the current committee, position and house of every active member
+// as their assignment in a term, so that it is kept when members move on the next term. Run it at
+// the end of the term, before the new assignments are imported. Without -apply it only prints what
+// would be recorded.
+//
+//	go run ./cmd/term-rollover
+//	go run ./cmd/term-rollover -term 12 -start 2026-05-04 -end 2026-08-15 -apply -actor admin@dlsu.edu.ph
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	termFlag := flag.String("term", "current", `id of the term to record, or "current"`)
+	start := flag.String("start", "", "first day of the term, as 2006-01-02 (default: the term's start date)")
+	end := flag.String("end", "", "last day of the term, as 2006-01-02 (default: the term's end date, or today)")
+	replace := flag.Bool("replace", false, "record the term again if it was already recorded")
+	apply := flag.Bool("apply", false, "record the term instead of only printing what would be recorded")
+	actor := flag.String("actor", "", "email of the admin running the rollover, for the audit log (required with -apply)")
+	flag.Parse()
+
+	if *apply && *actor == "" {
+		log.Fatal("-actor is required with -apply")
+	}
+	startedOn, err := parseDate(*start)
+	if err != nil {
+		log.Fatalf("invalid -start: %v", err)
+	}
+	endedOn, err := parseDate(*end)
+	if err != nil {
+		log.Fatalf("invalid -end: %v", err)
+	}
+
+	db := database.New()
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	tx, err := db.GetConnection().BeginTx(ctx, nil)
+	if err != nil {
+		log.Fatalf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+	q := repository.New(tx)
+
+	t, err := term.Lookup(ctx, q, *termFlag)
+	if err != nil {
+		log.Fatalf("failed to get term: %v", err)
+	}
+	if !startedOn.Valid {
+		startedOn = t.StartsOn
+	}
+	if !endedOn.Valid {
+		endedOn = t.EndsOn
+	}
+	if !endedOn.Valid {
+		endedOn, _ = parseDate(time.Now().Format(time.DateOnly))
+	}
+	active, err := q.CountMembers(ctx, repository.CountMembersParams{})
+	if err != nil {
+		log.Fatalf("failed to count members: %v", err)
+	}
+	recorded, err := q.CountTermSnapshot(ctx, t.ID)
+	if err != nil {
+		log.Fatalf("failed to count recorded members: %v", err)
+	}
+
+	fmt.Printf("Term %d: %s, %s to %s\n", t.ID, term.Name(t), showDate(startedOn), showDate(endedOn))
+	fmt.Printf("%d active members to record\n", active)
+	if recorded > 0 {
+		fmt.Printf("%d members already recorded for this term\n", recorded)
+	}
+	if !*apply {
+		fmt.Println("\nDry run; pass -apply to record the term.")
+		return
+	}
+
+	n, err := term.Rollover(ctx, q, t.ID, startedOn, endedOn, *replace)
+	if err != nil {
+		log.Fatalf("failed to record term: %v", err)
+	}
+	if err := audit.Record(ctx, q, audit.Event{
+		ActorEmail: *actor,
+		Action:     "terms.rollover",
+		TargetType: audit.TargetTerm,
+		TargetID:   fmt.Sprint(t.ID),
+		Route:      "cmd/term-rollover",
+		Outcome:    audit.OutcomeSuccess,
+		Details:    map[string]any{"recorded": n, "replaced": recorded},
+	}); err != nil {
+		log.Fatalf("failed to record audit event: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Fatalf("failed to commit term rollover: %v", err)
+	}
+	fmt.Printf("\nRecorded %d members.\n", n)
+}
+
+func parseDate(s string) (sql.NullTime, error) {
+	if s == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+func showDate(t sql.NullTime) string {
+	if !t.Valid {
+		return "(unknown)"
+	}
+	return t.Time.Format(time.DateOnly)
+}
@@ -0,0 +1,115 @@
+// Command usage-report prints how much each project uses each API route, from the usage buckets
+// recorded by the API server.
+//
+//	go run ./cmd/usage-report -days 30 -project Links
+//	go run ./cmd/usage-report -term current
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	days := flag.Int("days", 30, "number of days to report on, ending now")
+	termFlag := flag.String("term", "", `report on a term instead, as a term id or "current"`)
+	project := flag.String("project", "", "only report on this project")
+	route := flag.String("route", "", `only report on this route, such as "GET /members"`)
+	asCSV := flag.Bool("csv", false, "print CSV instead of a table")
+	flag.Parse()
+
+	if *days < 1 {
+		log.Fatal("-days must be at least 1")
+	}
+
+	db := database.New()
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	q := repository.New(db.GetConnection())
+
+	until := time.Now()
+	f := analytics.Filter{
+		Since:   until.AddDate(0, 0, -*days),
+		Until:   until,
+		Project: *project,
+		Route:   *route,
+	}
+	if *termFlag != "" {
+		t, err := term.Lookup(ctx, q, *termFlag)
+		if err != nil {
+			log.Fatalf("failed to get term: %v", err)
+		}
+		if f.Since, f.Until, err = term.Window(t); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	usage, err := analytics.Report(ctx, q, f)
+	if err != nil {
+		log.Fatalf("failed to build usage report: %v", err)
+	}
+
+	if *asCSV {
+		err = writeCSV(usage)
+	} else {
+		err = writeTable(usage, f)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeTable(usage []analytics.RouteUsage, f analytics.Filter) error {
+	fmt.Printf("Usage from %s to %s\n\n", f.Since.Format(time.DateTime), f.Until.Format(time.DateTime))
+	if len(usage) == 0 {
+		fmt.Println("No usage recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "PROJECT\tROUTE\tKEYS\tREQUESTS\tERROR %\tAVG MS\tMAX MS\tLAST SEEN\t")
+	for _, u := range usage {
+		project := u.Project
+		if project == "" {
+			project = "(none)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.1f%%\t%.0f\t%d\t%s\t\n",
+			project, u.Route, u.KeyCount, u.RequestCount, u.ErrorRate*100, u.AvgLatencyMs, u.MaxLatencyMs, u.LastSeen.Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+func writeCSV(usage []analytics.RouteUsage) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"project", "route", "key_count", "request_count", "error_count", "avg_latency_ms", "max_latency_ms", "last_seen"})
+	for _, u := range usage {
+		w.Write([]string{
+			u.Project,
+			u.Route,
+			strconv.FormatInt(u.KeyCount, 10),
+			strconv.FormatInt(u.RequestCount, 10),
+			strconv.FormatInt(u.ErrorCount, 10),
+			strconv.FormatFloat(u.AvgLatencyMs, 'f', 1, 64),
+			strconv.FormatInt(u.MaxLatencyMs, 10),
+			u.LastSeen.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
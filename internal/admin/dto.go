@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// KeyResponse describes any API key in the org, without its hash.
+type KeyResponse struct {
+	ApiKeyID      int32                  `json:"api_key_id"`
+	MemberEmail   string                 `json:"member_email"`
+	Project       helpers.NullableString `json:"project"`
+	AllowedOrigin helpers.NullableString `json:"allowed_origin"`
+	IsDev         bool                   `json:"is_dev"`
+	IsAdmin       bool                   `json:"is_admin"`
+	Scopes        []string               `json:"scopes"`
+	CreatedAt     helpers.NullableTime   `json:"created_at"`
+	ExpiresAt     helpers.NullableTime   `json:"expires_at"`
+	LastUsedAt    helpers.NullableTime   `json:"last_used_at"`
+	RequestCount  int64                  `json:"request_count"`
+}
+
+func toKeyResponse(k repository.ListAllAPIKeysRow) KeyResponse {
+	return KeyResponse{
+		ApiKeyID:      k.ApiKeyID,
+		MemberEmail:   k.MemberEmail,
+		Project:       helpers.NullableString{NullString: k.Project},
+		AllowedOrigin: helpers.NullableString{NullString: k.AllowedOrigin},
+		IsDev:         k.IsDev,
+		IsAdmin:       k.IsAdmin,
+		Scopes:        auth.ParseScopes(k.Scopes),
+		CreatedAt:     helpers.NullableTime{NullTime: k.CreatedAt},
+		ExpiresAt:     helpers.NullableTime{NullTime: k.ExpiresAt},
+		LastUsedAt:    helpers.NullableTime{NullTime: k.LastUsedAt},
+		RequestCount:  k.RequestCount,
+	}
+}
+
+// KeyUsageResponse is the usage of a single API key.
+type KeyUsageResponse struct {
+	ApiKeyID     int32                  `json:"api_key_id"`
+	MemberEmail  string                 `json:"member_email"`
+	Project      helpers.NullableString `json:"project"`
+	CreatedAt    helpers.NullableTime   `json:"created_at"`
+	ExpiresAt    helpers.NullableTime   `json:"expires_at"`
+	LastUsedAt   helpers.NullableTime   `json:"last_used_at"`
+	RequestCount int64                  `json:"request_count"`
+	Daily        []DayUsage             `json:"daily"`
+}
+
+// DayUsage is the number of requests an API key made on one day, and how many of them were rate limited.
+type DayUsage struct {
+	Date             string `json:"date"`
+	RequestCount     int64  `json:"request_count"`
+	RateLimitedCount int64  `json:"rate_limited_count"`
+}
+
+func toKeyUsageResponse(u repository.GetAPIKeyUsageRow, daily []repository.ListAPIKeyDailyUsageRow) KeyUsageResponse {
+	days := make([]DayUsage, 0, len(daily))
+	for _, d := range daily {
+		days = append(days, DayUsage{
+			Date:             d.UsageDate.Format(time.DateOnly),
+			RequestCount:     d.RequestCount,
+			RateLimitedCount: d.RateLimitedCount,
+		})
+	}
+
+	return KeyUsageResponse{
+		ApiKeyID:     u.ApiKeyID,
+		MemberEmail:  u.MemberEmail,
+		Project:      helpers.NullableString{NullString: u.Project},
+		CreatedAt:    helpers.NullableTime{NullTime: u.CreatedAt},
+		ExpiresAt:    helpers.NullableTime{NullTime: u.ExpiresAt},
+		LastUsedAt:   helpers.NullableTime{NullTime: u.LastUsedAt},
+		RequestCount: u.RequestCount,
+		Daily:        days,
+	}
+}
+
+// DailyUsageResponse is the usage of one API key on one day.
+type DailyUsageResponse struct {
+	Date             string                 `json:"date"`
+	ApiKeyID         int32                  `json:"api_key_id"`
+	MemberEmail      helpers.NullableString `json:"member_email"`
+	Project          helpers.NullableString `json:"project"`
+	RequestCount     int64                  `json:"request_count"`
+	RateLimitedCount int64                  `json:"rate_limited_count"`
+}
+
+func toDailyUsageResponse(r repository.ListDailyUsageRow) DailyUsageResponse {
+	return DailyUsageResponse{
+		Date:             r.UsageDate.Format(time.DateOnly),
+		ApiKeyID:         r.ApiKeyID,
+		MemberEmail:      helpers.NullableString{NullString: r.MemberEmail},
+		Project:          helpers.NullableString{NullString: r.Project},
+		RequestCount:     r.RequestCount,
+		RateLimitedCount: r.RateLimitedCount,
+	}
+}
+
+// AuditEventResponse is one entry of the audit log.
+type AuditEventResponse struct {
+	ID            int64                  `json:"id"`
+	ActorEmail    string                 `json:"actor_email"`
+	ActorApiKeyID *int32                 `json:"actor_api_key_id"`
+	Action        string                 `json:"action"`
+	Route         string                 `json:"route"`
+	TargetType    helpers.NullableString `json:"target_type"`
+	TargetID      helpers.NullableString `json:"target_id"`
+	Outcome       string                 `json:"outcome"`
+	StatusCode    int32                  `json:"status_code"`
+	ClientIP      helpers.NullableString `json:"client_ip"`
+	Details       json.RawMessage        `json:"details"`
+	CreatedAt     time.Time              `json:"created_at"`
+}
+
+func toAuditEventResponse(e repository.AuditEvent) AuditEventResponse {
+	r := AuditEventResponse{
+		ID:         e.ID,
+		ActorEmail: e.ActorEmail,
+		Action:     e.Action,
+		Route:      e.Route,
+		TargetType: helpers.NullableString{NullString: e.TargetType},
+		TargetID:   helpers.NullableString{NullString: e.TargetID},
+		Outcome:    e.Outcome,
+		StatusCode: e.StatusCode,
+		ClientIP:   helpers.NullableString{NullString: e.ClientIp},
+		CreatedAt:  e.CreatedAt,
+	}
+	if e.ActorApiKeyID.Valid {
+		r.ActorApiKeyID = &e.ActorApiKeyID.Int32
+	}
+	if e.Details.Valid {
+		r.Details = json.RawMessage(e.Details.String)
+	}
+	return r
+}
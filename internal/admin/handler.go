@@ -0,0 +1,333 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+// pagination reads the limit and offset query parameters.
+func pagination(c echo.Context) (int32, int32, error) {
+	limit, offset := defaultPageSize, 0
+	if v := c.QueryParam("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPageSize {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		limit = n
+	}
+	if v := c.QueryParam("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+		offset = n
+	}
+	return int32(limit), int32(offset), nil
+}
+
+// usageSince reads the days query parameter and returns the first day of that window, today included.
+func usageSince(c echo.Context) (time.Time, error) {
+	days := defaultUsageDays
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxUsageDays {
+			return time.Time{}, fmt.Errorf("days must be between 1 and %d", maxUsageDays)
+		}
+		days = n
+	}
+	y, m, d := time.Now().Date()
+	return time.Date(y, m, d-(days-1), 0, 0, 0, 0, time.Local), nil
+}
+
+// stringParam reads an optional query parameter.
+func stringParam(c echo.Context, name string) sql.NullString {
+	v := c.QueryParam(name)
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+// timeParam reads an optional RFC 3339 timestamp query parameter.
+func timeParam(c echo.Context, name string) (sql.NullTime, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return sql.NullTime{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+// window reads the since and until query parameters, or the term parameter in their place for the
+// dates of a term, such as ?term=current. On failure it returns the status and message to respond with.
+func window(c echo.Context, q *repository.Queries) (sql.NullTime, sql.NullTime, int, string) {
+	if c.QueryParam("term") == "" {
+		since, err := timeParam(c, "since")
+		if err != nil {
+			return sql.NullTime{}, sql.NullTime{}, http.StatusBadRequest, err.Error()
+		}
+		until, err := timeParam(c, "until")
+		if err != nil {
+			return sql.NullTime{}, sql.NullTime{}, http.StatusBadRequest, err.Error()
+		}
+		return since, until, http.StatusOK, ""
+	}
+	if c.QueryParam("since") != "" || c.QueryParam("until") != "" {
+		return sql.NullTime{}, sql.NullTime{}, http.StatusBadRequest, "term cannot be combined with since or until"
+	}
+
+	t, status, msg := term.FromQuery(c, q)
+	if status != http.StatusOK {
+		return sql.NullTime{}, sql.NullTime{}, status, msg
+	}
+	since, until, err := term.Window(t)
+	if err != nil {
+		return sql.NullTime{}, sql.NullTime{}, http.StatusBadRequest, err.Error()
+	}
+	return sql.NullTime{Time: since, Valid: true}, sql.NullTime{Time: until, Valid: true}, http.StatusOK, ""
+}
+
+// ListKeysHandler returns every API key in the org, without hashes.
+func (h *Handler) ListKeysHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	keys, err := q.ListAllAPIKeys(ctx)
+	if err != nil {
+		slog.Error("failed to list api keys", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error listing API keys"})
+	}
+
+	response := make([]KeyResponse, 0, len(keys))
+	for _, k := range keys {
+		response = append(response, toKeyResponse(k))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"keys": response,
+	})
+}
+
+// RevokeKeyHandler deletes any API key. The revocation and its audit entry are written in one
+// transaction so a key is never revoked without a record of who did it.
+func (h *Handler) RevokeKeyHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid API key id"})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error revoking API key"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	key, err := qtx.GetAPIKeyUsage(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+		}
+		slog.Error("failed to get api key", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error revoking API key"})
+	}
+
+	if _, err := qtx.AdminRevokeAPIKey(ctx, key.ApiKeyID); err != nil {
+		slog.Error("failed to revoke api key", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error revoking API key"})
+	}
+
+	audit.SetTarget(c, audit.TargetAPIKey, strconv.Itoa(int(key.ApiKeyID)))
+	event := middlewares.AuditEvent(c, "api_keys.revoke")
+	event.Outcome = audit.OutcomeSuccess
+	event.StatusCode = http.StatusOK
+	event.Details = map[string]any{
+		"member_email": key.MemberEmail,
+		"project":      key.Project.String,
+	}
+	if err := audit.Record(ctx, qtx, event); err != nil {
+		slog.Error("failed to record audit event", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error revoking API key"})
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit api key revocation", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error revoking API key"})
+	}
+	audit.SetRecorded(c)
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":    "API key revoked",
+		"api_key_id": key.ApiKeyID,
+	})
+}
+
+// KeyUsageHandler returns when an API key was last used, how many requests it has made, and its
+// daily request counts over the last `days` days.
+func (h *Handler) KeyUsageHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid API key id"})
+	}
+	audit.SetTarget(c, audit.TargetAPIKey, strconv.Itoa(id))
+
+	since, err := usageSince(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	usage, err := q.GetAPIKeyUsage(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+		}
+		slog.Error("failed to get api key usage", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error getting API key usage"})
+	}
+
+	daily, err := q.ListAPIKeyDailyUsage(ctx, repository.ListAPIKeyDailyUsageParams{
+		ApiKeyID:  usage.ApiKeyID,
+		UsageDate: since,
+	})
+	if err != nil {
+		slog.Error("failed to get daily api key usage", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error getting API key usage"})
+	}
+
+	return c.JSON(http.StatusOK, toKeyUsageResponse(usage, daily))
+}
+
+// DailyUsageHandler returns the daily request counts of every API key over the last `days` days,
+// busiest keys first within each day. Revoked keys keep their history but lose their owner and project.
+func (h *Handler) DailyUsageHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	since, err := usageSince(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	rows, err := q.ListDailyUsage(ctx, since)
+	if err != nil {
+		slog.Error("failed to list daily usage", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error listing usage"})
+	}
+
+	response := make([]DailyUsageResponse, 0, len(rows))
+	for _, r := range rows {
+		response = append(response, toDailyUsageResponse(r))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"since": since.Format(time.DateOnly),
+		"usage": response,
+	})
+}
+
+// ListAuditHandler returns the audit log, newest first. It can be filtered by actor, target and
+// a time window given as RFC 3339 timestamps or as a term.
+func (h *Handler) ListAuditHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	limit, offset, err := pagination(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	since, until, status, msg := window(c, q)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	events, err := q.ListAuditEvents(ctx, repository.ListAuditEventsParams{
+		ActorEmail: stringParam(c, "actor"),
+		TargetType: stringParam(c, "target_type"),
+		TargetID:   stringParam(c, "target_id"),
+		Since:      since,
+		Until:      until,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		slog.Error("failed to list audit events", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error listing audit events"})
+	}
+
+	response := make([]AuditEventResponse, 0, len(events))
+	for _, e := range events {
+		response = append(response, toAuditEventResponse(e))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"events": response,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// AnalyticsHandler returns request counts, error counts and latency per project and route, so RND
+// can see who relies on an endpoint before changing it. The window defaults to the last 30 days.
+func (h *Handler) AnalyticsHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	since, until, status, msg := window(c, q)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	f := analytics.Filter{
+		Since:   since.Time,
+		Until:   until.Time,
+		Project: c.QueryParam("project"),
+		Route:   c.QueryParam("route"),
+	}
+	if !until.Valid {
+		f.Until = time.Now()
+	}
+	if !since.Valid {
+		f.Since = f.Until.AddDate(0, 0, -defaultUsageDays)
+	}
+
+	usage, err := analytics.Report(ctx, q, f)
+	if err != nil {
+		slog.Error("failed to build usage report", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error building usage report"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"since":  f.Since,
+		"until":  f.Until,
+		"routes": usage,
+	})
+}
@@ -0,0 +1,358 @@
+package admin
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDBService is a mock implementation of the database.Service interface.
+type mockDBService struct {
+	db *sql.DB
+}
+
+func (m *mockDBService) Health() map[string]string {
+	return nil
+}
+
+func (m *mockDBService) Close() error {
+	return nil
+}
+
+func (m *mockDBService) GetConnection() *sql.DB {
+	return m.db
+}
+
+var adminKey = repository.ApiKey{ApiKeyID: 1, MemberEmail: "admin@dlsu.edu.ph", IsAdmin: true}
+
+func TestListKeysHandler(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middlewares.APIKeyContextKey, adminKey)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys").
+		WillReturnRows(sqlmock.NewRows([]string{"api_key_id", "member_email", "project", "allowed_origin", "is_dev", "is_admin", "scopes", "created_at", "expires_at", "last_used_at", "request_count"}).
+			AddRow(7, "test@dlsu.edu.ph", "Links", "https://links.app.dlsu-lscs.org", false, false, "members:read", time.Now(), nil, time.Now(), 42))
+
+	h := NewHandler(&mockDBService{db: db})
+
+	if assert.NoError(t, h.ListKeysHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp map[string][]map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		assert.Len(t, resp["keys"], 1)
+		assert.Equal(t, float64(42), resp["keys"][0]["request_count"])
+		assert.NotContains(t, resp["keys"][0], "api_key_hash")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRevokeKeyHandler(t *testing.T) {
+	usageColumns := []string{"api_key_id", "member_email", "project", "created_at", "expires_at", "last_used_at", "request_count"}
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/admin/keys/7", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/admin/keys/:id")
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c, rec := newContext()
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE api_key_id = ?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(usageColumns).AddRow(7, "test@dlsu.edu.ph", "Links", time.Now(), nil, nil, 0))
+		mock.ExpectExec("DELETE FROM api_keys WHERE api_key_id = ?").
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO audit_events").
+			WithArgs(adminKey.MemberEmail, adminKey.ApiKeyID, "api_keys.revoke", "DELETE /admin/keys/:id", "api_key", "7", "success", http.StatusOK, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.RevokeKeyHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		c, rec := newContext()
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE api_key_id = ?").
+			WithArgs(7).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.RevokeKeyHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestListAuditHandler(t *testing.T) {
+	auditColumns := []string{"id", "actor_email", "actor_api_key_id", "action", "route", "target_type", "target_id", "outcome", "status_code", "client_ip", "details", "created_at"}
+
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?actor=admin@dlsu.edu.ph&target_type=api_key&since=2026-01-01T00:00:00Z&limit=10&offset=20", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT (.+) FROM audit_events").
+			WithArgs(adminKey.MemberEmail, adminKey.MemberEmail, "api_key", "api_key", nil, nil, since, since, nil, nil, 10, 20).
+			WillReturnRows(sqlmock.NewRows(auditColumns).
+				AddRow(1, adminKey.MemberEmail, adminKey.ApiKeyID, "api_keys.revoke", "DELETE /admin/keys/:id", "api_key", "7", "success", 200, "203.0.113.7", `{"member_email":"test@dlsu.edu.ph"}`, time.Now()))
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.ListAuditHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var resp map[string][]map[string]interface{}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			assert.Len(t, resp["events"], 1)
+			assert.Equal(t, "test@dlsu.edu.ph", resp["events"][0]["details"].(map[string]interface{})["member_email"])
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?limit=1000", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.ListAuditHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("invalid since", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?since=yesterday", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.ListAuditHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestKeyUsageHandler(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/keys/7/usage?days=7", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+	c.Set(middlewares.APIKeyContextKey, adminKey)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE api_key_id = ?").
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"api_key_id", "member_email", "project", "created_at", "expires_at", "last_used_at", "request_count"}).
+			AddRow(7, "test@dlsu.edu.ph", "Links", time.Now(), nil, time.Now(), 42))
+	mock.ExpectQuery("SELECT (.+) FROM api_key_daily_usage").
+		WithArgs(7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"usage_date", "request_count", "rate_limited_count"}).
+			AddRow(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), 40, 2))
+
+	h := NewHandler(&mockDBService{db: db})
+
+	if assert.NoError(t, h.KeyUsageHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp KeyUsageResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		assert.Equal(t, []DayUsage{{Date: "2026-10-18", RequestCount: 40, RateLimitedCount: 2}}, resp.Daily)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestDailyUsageHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/usage", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM api_key_daily_usage").
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"usage_date", "api_key_id", "member_email", "project", "request_count", "rate_limited_count"}).
+				AddRow(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), 7, "test@dlsu.edu.ph", "Links", 40, 2).
+				AddRow(time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), 3, nil, nil, 5, 0))
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DailyUsageHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var resp struct {
+				Usage []map[string]interface{} `json:"usage"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			assert.Len(t, resp.Usage, 2)
+			assert.Equal(t, "Links", resp.Usage[0]["project"])
+			assert.Equal(t, "", resp.Usage[1]["project"])
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("invalid days", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/usage?days=0", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.DailyUsageHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestAnalyticsHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics?since=2026-09-01T00:00:00Z&until=2026-10-01T00:00:00Z&route=GET%20/members", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT (.+) FROM api_usage_buckets").
+			WithArgs(since, until, nil, nil, "GET /members", "GET /members").
+			WillReturnRows(sqlmock.NewRows([]string{"project", "route", "key_count", "request_count", "error_count", "total_latency_ms", "max_latency_ms", "last_seen"}).
+				AddRow("Links", "GET /members", 1, 10, 1, 100, 30, until))
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AnalyticsHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			var resp struct {
+				Routes []map[string]interface{} `json:"routes"`
+			}
+			json.Unmarshal(rec.Body.Bytes(), &resp)
+			assert.Len(t, resp.Routes, 1)
+			assert.Equal(t, float64(10), resp.Routes[0]["avg_latency_ms"])
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - term", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics?term=12", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		since := time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2026, 8, 16, 0, 0, 0, 0, time.UTC)
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "term", "start_year", "end_year", "starts_on", "ends_on"}).
+				AddRow(12, 3, 2025, 2026, since, time.Date(2026, 8, 15, 0, 0, 0, 0, time.UTC)))
+		mock.ExpectQuery("SELECT (.+) FROM api_usage_buckets").
+			WithArgs(since, until, nil, nil, nil, nil).
+			WillReturnRows(sqlmock.NewRows([]string{"project", "route", "key_count", "request_count", "error_count", "total_latency_ms", "max_latency_ms", "last_seen"}))
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AnalyticsHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("term with since", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics?term=current&since=2026-09-01T00:00:00Z", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.AnalyticsHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("invalid until", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/analytics?until=tomorrow", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.AnalyticsHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/roster"
+	"github.com/labstack/echo/v4"
+)
+
+// ImportResponse is the diff of a roster import, and whether it was applied.
+type ImportResponse struct {
+	Applied bool `json:"applied"`
+	*roster.Diff
+}
+
+// readRoster reads the roster file of an import request, uploaded either as the "file" field of
+// a multipart form or as the request body.
+func readRoster(c echo.Context) (*roster.Table, error) {
+	format := strings.ToLower(c.QueryParam("format"))
+
+	var body io.Reader = c.Request().Body
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			return nil, &roster.FileError{Message: "missing file"}
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		body = f
+		if format == "" {
+			format = roster.FormatOf(fh.Filename, fh.Header.Get(echo.HeaderContentType))
+		}
+	} else if format == "" {
+		format = roster.FormatOf("", c.Request().Header.Get(echo.HeaderContentType))
+	}
+	if format == "" {
+		return nil, &roster.FileError{Message: "cannot tell the file format; pass ?format=csv or ?format=xlsx"}
+	}
+
+	t, err := roster.Read(body, format)
+	if err != nil {
+		return nil, &roster.FileError{Message: err.Error()}
+	}
+	return t, nil
+}
+
+// ImportMembersHandler compares a roster file against the members and returns the diff. With
+// ?apply=true it also applies the diff, in one transaction, unless any row is invalid. A roster
+// with no rows, or one that deactivates more than roster.MaxRemovedShare of the active members, is
+// only applied with ?allow_mass_removal=true. The import and its audit entry are written together.
+func (h *Handler) ImportMembersHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+	apply := c.QueryParam("apply") == "true"
+	allowMassRemoval := c.QueryParam("allow_mass_removal") == "true"
+
+	t, err := readRoster(c)
+	if err != nil {
+		var fileErr *roster.FileError
+		if errors.As(err, &fileErr) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fileErr.Message})
+		}
+		slog.Error("failed to read roster", "error", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot read file"})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error importing roster"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	diff, err := roster.Compare(ctx, qtx, t)
+	if err != nil {
+		var fileErr *roster.FileError
+		if errors.As(err, &fileErr) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fileErr.Message})
+		}
+		slog.Error("failed to compare roster", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error importing roster"})
+	}
+	if diff.HasErrors() {
+		return c.JSON(http.StatusUnprocessableEntity, ImportResponse{Diff: diff})
+	}
+	if !apply {
+		return c.JSON(http.StatusOK, ImportResponse{Diff: diff})
+	}
+
+	if err := roster.Apply(ctx, qtx, diff, allowMassRemoval); err != nil {
+		if errors.Is(err, roster.ErrMassRemoval) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": fmt.Sprintf("roster is empty or deactivates too many members (%d); check the file, or pass allow_mass_removal=true", len(diff.Removed)),
+			})
+		}
+		return memberError(c, err, "import")
+	}
+
+	event := middlewares.AuditEvent(c, "members.import")
+	event.Outcome = audit.OutcomeSuccess
+	event.StatusCode = http.StatusOK
+	event.Details = diff.Summary()
+	if err := audit.Record(ctx, qtx, event); err != nil {
+		slog.Error("failed to record audit event", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error importing roster"})
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit roster import", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error importing roster"})
+	}
+	audit.SetRecorded(c)
+
+	return c.JSON(http.StatusOK, ImportResponse{Applied: true, Diff: diff})
+}
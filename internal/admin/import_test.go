@@ -0,0 +1,171 @@
+package admin
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const importCSV = "ID Number,Full Name,Email,Committee\n12345678,Juan Dela Cruz,juan@dlsu.edu.ph,RND\n"
+
+func expectImportLookups(mock sqlmock.Sqlmock) {
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM committees").
+		WillReturnRows(sqlmock.NewRows([]string{"committee_id", "committee_name", "committee_head", "division_id"}).
+			AddRow("RND", "Research and Development", nil, nil))
+	mock.ExpectQuery("SELECT position_id, position_name FROM positions").
+		WillReturnRows(sqlmock.NewRows([]string{"position_id", "position_name"}))
+	mock.ExpectQuery("SELECT id, name, description FROM houses").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}))
+	mock.ExpectQuery("SELECT (.+) FROM members\\s+ORDER BY id").
+		WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+			AddRow(11111111, "Ana Santos", nil, "ana@dlsu.edu.ph", nil, nil, "RND", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+	mock.ExpectQuery("SELECT email, member_id FROM member_emails").
+		WillReturnRows(sqlmock.NewRows([]string{"email", "member_id"}))
+}
+
+func TestImportMembersHandler(t *testing.T) {
+	t.Run("dry run", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectImportLookups(mock)
+		mock.ExpectRollback()
+
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		fw, _ := mw.CreateFormFile("file", "roster.csv")
+		fw.Write([]byte(importCSV))
+		mw.Close()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/members/import", &body)
+		req.Header.Set(echo.HeaderContentType, mw.FormDataContentType())
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.ImportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{
+				"applied": false,
+				"new": [{"row": 2, "id": 12345678, "email": "juan@dlsu.edu.ph", "full_name": "Juan Dela Cruz"}],
+				"changed": [],
+				"removed": [{"id": 11111111, "email": "ana@dlsu.edu.ph", "full_name": "Ana Santos"}],
+				"unchanged": 0,
+				"errors": [],
+				"ignored_columns": []
+			}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectImportLookups(mock)
+		mock.ExpectExec("INSERT INTO members").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE members SET status = 'INACTIVE'").WithArgs(11111111).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at").WithArgs("ana@dlsu.edu.ph").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO audit_events").
+			WithArgs("admin@dlsu.edu.ph", 1, "members.import", sqlmock.AnyArg(), nil, nil, "success", 200, sqlmock.AnyArg(),
+				`{"changed":0,"new":1,"removed":1,"unchanged":0}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		e := echo.New()
+		// The roster deactivates the only other active member
+		req := httptest.NewRequest(http.MethodPost, "/admin/members/import?apply=true&allow_mass_removal=true", strings.NewReader(importCSV))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.ImportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"applied":true`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - empty roster", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectImportLookups(mock)
+		mock.ExpectRollback()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/members/import?apply=true&format=csv",
+			strings.NewReader("ID Number,Full Name,Email\n"))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.ImportMembersHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), "allow_mass_removal=true")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - row errors", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectImportLookups(mock)
+		mock.ExpectRollback()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/members/import?apply=true&format=csv",
+			strings.NewReader("id,full_name,email\n12345678,Juan,not-an-email\n"))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.ImportMembersHandler(c)) {
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"error":"invalid email"`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - unknown format", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/members/import", strings.NewReader(importCSV))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.ImportMembersHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/go-sql-driver/mysql"
+	"github.com/labstack/echo/v4"
+)
+
+type AddMemberEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// MemberEmailResponse is another Google account a member can sign in with.
+type MemberEmailResponse struct {
+	Email     string               `json:"email"`
+	CreatedAt helpers.NullableTime `json:"created_at"`
+}
+
+// memberID reads the :id path parameter and checks that the member exists. On failure it has
+// already written the response.
+func memberID(c echo.Context, q *repository.Queries) (int32, bool, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, false, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	if _, err := q.CheckIdIfMember(c.Request().Context(), int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+		}
+		slog.Error("failed to check member id", "error", err)
+		return 0, false, c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return int32(id), true, nil
+}
+
+// ListMemberEmailsHandler returns the other accounts a member can sign in with.
+func (h *Handler) ListMemberEmailsHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	id, ok, err := memberID(c, q)
+	if !ok {
+		return err
+	}
+
+	emails, err := q.ListMemberEmails(c.Request().Context(), id)
+	if err != nil {
+		slog.Error("failed to list member emails", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error listing emails"})
+	}
+
+	response := make([]MemberEmailResponse, 0, len(emails))
+	for _, e := range emails {
+		response = append(response, MemberEmailResponse{Email: e.Email, CreatedAt: helpers.NullableTime{NullTime: e.CreatedAt}})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"member_id": id,
+		"emails":    response,
+	})
+}
+
+// AddMemberEmailHandler lets a member sign in with another Google account, such as a personal
+// Gmail address. The email cannot already belong to any member.
+func (h *Handler) AddMemberEmailHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	id, ok, err := memberID(c, q)
+	if !ok {
+		return err
+	}
+
+	var req AddMemberEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot read body"})
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid email"})
+	}
+
+	if _, err := q.CheckEmailIfMember(ctx, email); err != sql.ErrNoRows {
+		if err != nil {
+			slog.Error("failed to check member email", "error", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Email already belongs to a member"})
+	}
+	if _, err := q.GetMemberEmailBySecondaryEmail(ctx, email); err != sql.ErrNoRows {
+		if err != nil {
+			slog.Error("failed to check member email", "error", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Email already belongs to a member"})
+	}
+
+	if err := q.AddMemberEmail(ctx, repository.AddMemberEmailParams{Email: email, MemberID: id}); err != nil {
+		// Added by a concurrent request since the checks above
+		var mysqlErr *mysql.MySQLError
+		if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+			return c.JSON(http.StatusConflict, map[string]string{"error": "Email already belongs to a member"})
+		}
+		slog.Error("failed to add member email", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error adding email"})
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"member_id": id,
+		"email":     email,
+	})
+}
+
+// DeleteMemberEmailHandler stops a member from signing in with one of their other accounts, and
+// ends the member's sessions, since a session does not record which account started it.
+func (h *Handler) DeleteMemberEmailHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting email"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	m, err := qtx.AdminGetMemberForUpdate(ctx, int32(id))
+	if err == sql.ErrNoRows || (err == nil && m.Status != repository.MembersStatusACTIVE) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+	}
+	if err != nil {
+		slog.Error("failed to get member", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting email"})
+	}
+
+	deleted, err := qtx.DeleteMemberEmail(ctx, repository.DeleteMemberEmailParams{
+		MemberID: m.ID,
+		Email:    strings.ToLower(c.Param("email")),
+	})
+	if err != nil {
+		slog.Error("failed to delete member email", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting email"})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Email not found"})
+	}
+	revoked, err := qtx.RevokeMemberSessions(ctx, m.Email)
+	if err != nil {
+		slog.Error("failed to revoke member sessions", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting email"})
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit member email deletion", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting email"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":          "Email removed",
+		"member_id":        m.ID,
+		"sessions_revoked": revoked,
+	})
+}
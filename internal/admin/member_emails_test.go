@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/go-sql-driver/mysql"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddMemberEmailHandler(t *testing.T) {
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/admin/members/12345678/emails", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("12345678")
+		c.Set(middlewares.APIKeyContextKey, adminKey)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12345678))
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WithArgs("juan@gmail.com").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT m.email FROM member_emails e").
+			WithArgs("juan@gmail.com").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("INSERT INTO member_emails").
+			WithArgs("juan@gmail.com", 12345678).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		c, rec := newContext(`{"email": " Juan@Gmail.com "}`)
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AddMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.JSONEq(t, `{"member_id": 12345678, "email": "juan@gmail.com"}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - email of another member", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12345678))
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WithArgs("other@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("other@dlsu.edu.ph"))
+
+		c, rec := newContext(`{"email": "other@dlsu.edu.ph"}`)
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AddMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - added concurrently", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12345678))
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT m.email FROM member_emails e").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("INSERT INTO member_emails").
+			WithArgs("juan@gmail.com", 12345678).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'juan@gmail.com' for key 'PRIMARY'"})
+
+		c, rec := newContext(`{"email": "juan@gmail.com"}`)
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AddMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - invalid email", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12345678))
+
+		c, rec := newContext(`{"email": "Juan <juan@gmail.com>"}`)
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AddMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("fail - unknown member", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").
+			WillReturnError(sql.ErrNoRows)
+
+		c, rec := newContext(`{"email": "juan@gmail.com"}`)
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.AddMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestDeleteMemberEmailHandler(t *testing.T) {
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/admin/members/12345678/emails/juan@gmail.com", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "email")
+		c.SetParamValues("12345678", "Juan@gmail.com")
+		return c, rec
+	}
+	expectMember := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", "Juan", "juan@dlsu.edu.ph", nil, "MEM", "RND", "CCS", nil, nil, nil, nil, nil, 1, "ACTIVE", nil))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectMember(mock)
+		mock.ExpectExec("DELETE FROM member_emails").
+			WithArgs(12345678, "juan@gmail.com").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE member_email = ?").
+			WithArgs("juan@dlsu.edu.ph").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		c, rec := newContext()
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeleteMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"success": "Email removed", "member_id": 12345678, "sessions_revoked": 2}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - unknown email", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectMember(mock)
+		mock.ExpectExec("DELETE FROM member_emails").
+			WithArgs(12345678, "juan@gmail.com").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		c, rec := newContext()
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeleteMemberEmailHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
@@ -0,0 +1,537 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/member"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/go-sql-driver/mysql"
+	"github.com/labstack/echo/v4"
+)
+
+// MySQL error numbers
+const (
+	errDuplicateEntry     = 1062
+	errRowIsReferenced    = 1451
+	errNoReferencedRowFor = 1452
+)
+
+// AdminMemberResponse is a member as admins see it, including deactivated members.
+type AdminMemberResponse struct {
+	ID            int32                  `json:"id"`
+	FullName      string                 `json:"full_name"`
+	Nickname      helpers.NullableString `json:"nickname"`
+	Email         string                 `json:"email"`
+	Telegram      helpers.NullableString `json:"telegram"`
+	PositionID    helpers.NullableString `json:"position_id"`
+	CommitteeID   helpers.NullableString `json:"committee_id"`
+	College       helpers.NullableString `json:"college"`
+	Program       helpers.NullableString `json:"program"`
+	Discord       helpers.NullableString `json:"discord"`
+	Interests     helpers.NullableString `json:"interests"`
+	ContactNumber helpers.NullableString `json:"contact_number"`
+	FbLink        helpers.NullableString `json:"fb_link"`
+	HouseID       *int32                 `json:"house_id"`
+	Status        string                 `json:"status"`
+	DeactivatedAt helpers.NullableTime   `json:"deactivated_at"`
+}
+
+func toAdminMemberResponse(m repository.Member) AdminMemberResponse {
+	r := AdminMemberResponse{
+		ID:            m.ID,
+		FullName:      m.FullName,
+		Nickname:      helpers.NullableString{NullString: m.Nickname},
+		Email:         m.Email,
+		Telegram:      helpers.NullableString{NullString: m.Telegram},
+		PositionID:    helpers.NullableString{NullString: m.PositionID},
+		CommitteeID:   helpers.NullableString{NullString: m.CommitteeID},
+		College:       helpers.NullableString{NullString: m.College},
+		Program:       helpers.NullableString{NullString: m.Program},
+		Discord:       helpers.NullableString{NullString: m.Discord},
+		Interests:     helpers.NullableString{NullString: m.Interests},
+		ContactNumber: helpers.NullableString{NullString: m.ContactNumber},
+		FbLink:        helpers.NullableString{NullString: m.FbLink},
+		Status:        string(m.Status),
+		DeactivatedAt: helpers.NullableTime{NullTime: m.DeactivatedAt},
+	}
+	if m.HouseID.Valid {
+		r.HouseID = &m.HouseID.Int32
+	}
+	return r
+}
+
+// memberFieldError is a field of a member request that cannot be applied, with the status to respond with.
+type memberFieldError struct {
+	status  int
+	message string
+}
+
+func (e *memberFieldError) Error() string {
+	return e.message
+}
+
+func badField(format string, args ...any) error {
+	return &memberFieldError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// decodeString reads a field that is a string or null. Blank strings are read as null.
+func decodeString(field string, raw json.RawMessage) (sql.NullString, error) {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return sql.NullString{}, badField("%s must be a string or null", field)
+	}
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return sql.NullString{}, nil
+	}
+	return sql.NullString{String: strings.TrimSpace(*value), Valid: true}, nil
+}
+
+// applyMemberFields sets the fields present in a request body on m, validating each of them.
+// Fields that are left out are not changed.
+func applyMemberFields(ctx context.Context, q *repository.Queries, m *repository.Member, raw map[string]json.RawMessage) error {
+	// Sorted so the first error reported does not depend on map order
+	fields := make([]string, 0, len(raw))
+	for field := range raw {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		value := raw[field]
+		switch field {
+		case "id":
+			// Only read when creating a member
+		case "status", "deactivated_at":
+			return badField("%s is changed by deactivating or reactivating the member", field)
+
+		case "full_name":
+			name, err := decodeString(field, value)
+			if err != nil {
+				return err
+			}
+			if !name.Valid {
+				return badField("full_name is required")
+			}
+			if utf8.RuneCountInString(name.String) > 255 {
+				return badField("full_name must be at most 255 characters")
+			}
+			m.FullName = name.String
+
+		case "email":
+			email, err := decodeString(field, value)
+			if err != nil {
+				return err
+			}
+			address := strings.ToLower(email.String)
+			if addr, err := mail.ParseAddress(address); !email.Valid || err != nil || addr.Address != address {
+				return badField("Invalid email")
+			}
+			m.Email = address
+
+		case "nickname", "telegram", "discord", "interests", "contact_number", "fb_link":
+			s, err := decodeString(field, value)
+			if err != nil {
+				return err
+			}
+			if s.Valid {
+				if s.String, err = member.ValidateProfileField(field, s.String); err != nil {
+					return badField("%s", err.Error())
+				}
+			}
+			switch field {
+			case "nickname":
+				m.Nickname = s
+			case "telegram":
+				m.Telegram = s
+			case "discord":
+				m.Discord = s
+			case "interests":
+				m.Interests = s
+			case "contact_number":
+				m.ContactNumber = s
+			case "fb_link":
+				m.FbLink = s
+			}
+
+		case "college", "program":
+			s, err := decodeString(field, value)
+			if err != nil {
+				return err
+			}
+			if utf8.RuneCountInString(s.String) > 255 {
+				return badField("%s must be at most 255 characters", field)
+			}
+			if field == "college" {
+				m.College = s
+			} else {
+				m.Program = s
+			}
+
+		case "position_id":
+			s, err := decodeString(field, value)
+			if err != nil {
+				return err
+			}
+			if s.Valid {
+				if exists, err := q.PositionExists(ctx, s.String); err != nil {
+					return err
+				} else if !exists {
+					return badField("position %q does not exist", s.String)
+				}
+			}
+			m.PositionID = s
+
+		case "committee_id":
+			s, err := decodeString(field, value)
+			if err != nil {
+				return err
+			}
+			if s.Valid {
+				if exists, err := q.CommitteeExists(ctx, s.String); err != nil {
+					return err
+				} else if !exists {
+					return badField("committee %q does not exist", s.String)
+				}
+			}
+			m.CommitteeID = s
+
+		case "house_id":
+			var id *int32
+			if err := json.Unmarshal(value, &id); err != nil {
+				return badField("house_id must be a number or null")
+			}
+			if id != nil {
+				if exists, err := q.HouseExists(ctx, *id); err != nil {
+					return err
+				} else if !exists {
+					return badField("house %d does not exist", *id)
+				}
+			}
+			m.HouseID = sql.NullInt32{Valid: id != nil}
+			if id != nil {
+				m.HouseID.Int32 = *id
+			}
+
+		default:
+			return badField("unknown field %s", field)
+		}
+	}
+	return nil
+}
+
+// checkEmailAvailable fails if email is the primary or secondary email of a member other than id.
+func checkEmailAvailable(ctx context.Context, q *repository.Queries, email string, id int32) error {
+	owner, err := q.GetMemberIDByEmail(ctx, email)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == nil && owner != id {
+		return &memberFieldError{status: http.StatusConflict, message: "Email already belongs to a member"}
+	}
+	if _, err := q.GetMemberEmailBySecondaryEmail(ctx, email); err != sql.ErrNoRows {
+		if err != nil {
+			return err
+		}
+		return &memberFieldError{status: http.StatusConflict, message: "Email already belongs to a member"}
+	}
+	return nil
+}
+
+// readMemberBody reads the JSON object of a create or update request. On failure it has already
+// written the response.
+func readMemberBody(c echo.Context) (map[string]json.RawMessage, bool, error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot read body"})
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return nil, false, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+	}
+	return raw, true, nil
+}
+
+// memberError writes the response for an error from applying or saving member fields.
+func memberError(c echo.Context, err error, action string) error {
+	var fieldErr *memberFieldError
+	if errors.As(err, &fieldErr) {
+		return c.JSON(fieldErr.status, map[string]string{"error": fieldErr.message})
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errDuplicateEntry:
+			return c.JSON(http.StatusConflict, map[string]string{"error": "A member with this id or email already exists"})
+		case errRowIsReferenced:
+			return c.JSON(http.StatusConflict, map[string]string{"error": "The member's email is still used by their API keys, sessions or OAuth clients; revoke them before changing it"})
+		case errNoReferencedRowFor:
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "The position, committee or house does not exist"})
+		}
+	}
+	slog.Error("failed to "+action+" member", "error", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error saving member"})
+}
+
+// GetMemberHandler returns a member, whether active or deactivated.
+func (h *Handler) GetMemberHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	m, err := q.AdminGetMember(c.Request().Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+		}
+		slog.Error("failed to get member", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, toAdminMemberResponse(m))
+}
+
+// CreateMemberHandler adds a member. The id (the member's ID number), full_name and email are
+// required; position_id, committee_id and house_id must exist.
+func (h *Handler) CreateMemberHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	raw, ok, err := readMemberBody(c)
+	if !ok {
+		return err
+	}
+
+	var id int32
+	if err := json.Unmarshal(raw["id"], &id); err != nil || id <= 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id must be a positive number"})
+	}
+	if _, ok := raw["full_name"]; !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "full_name is required"})
+	}
+	if _, ok := raw["email"]; !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "email is required"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(int(id)))
+
+	if _, err := q.AdminGetMember(ctx, id); err != sql.ErrNoRows {
+		if err != nil {
+			return memberError(c, err, "check")
+		}
+		return c.JSON(http.StatusConflict, map[string]string{"error": "A member with this id already exists"})
+	}
+
+	m := repository.Member{ID: id}
+	if err := applyMemberFields(ctx, q, &m, raw); err != nil {
+		return memberError(c, err, "validate")
+	}
+	if err := checkEmailAvailable(ctx, q, m.Email, id); err != nil {
+		return memberError(c, err, "check")
+	}
+
+	if err := q.CreateMember(ctx, repository.CreateMemberParams{
+		ID:            m.ID,
+		FullName:      m.FullName,
+		Nickname:      m.Nickname,
+		Email:         m.Email,
+		Telegram:      m.Telegram,
+		PositionID:    m.PositionID,
+		CommitteeID:   m.CommitteeID,
+		College:       m.College,
+		Program:       m.Program,
+		Discord:       m.Discord,
+		Interests:     m.Interests,
+		ContactNumber: m.ContactNumber,
+		FbLink:        m.FbLink,
+		HouseID:       m.HouseID,
+	}); err != nil {
+		return memberError(c, err, "create")
+	}
+
+	created, err := q.AdminGetMember(ctx, id)
+	if err != nil {
+		return memberError(c, err, "get")
+	}
+	return c.JSON(http.StatusCreated, toAdminMemberResponse(created))
+}
+
+// UpdateMemberHandler changes the fields of a member present in the body. Fields set to null are
+// cleared.
+func (h *Handler) UpdateMemberHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	raw, ok, err := readMemberBody(c)
+	if !ok {
+		return err
+	}
+	if _, ok := raw["id"]; ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id cannot be changed"})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error saving member"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	m, err := qtx.AdminGetMemberForUpdate(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+		}
+		return memberError(c, err, "get")
+	}
+
+	previousEmail := m.Email
+	if err := applyMemberFields(ctx, qtx, &m, raw); err != nil {
+		return memberError(c, err, "validate")
+	}
+	if m.Email != previousEmail {
+		if err := checkEmailAvailable(ctx, qtx, m.Email, m.ID); err != nil {
+			return memberError(c, err, "check")
+		}
+	}
+
+	if err := qtx.UpdateMember(ctx, repository.UpdateMemberParams{
+		FullName:      m.FullName,
+		Nickname:      m.Nickname,
+		Email:         m.Email,
+		Telegram:      m.Telegram,
+		PositionID:    m.PositionID,
+		CommitteeID:   m.CommitteeID,
+		College:       m.College,
+		Program:       m.Program,
+		Discord:       m.Discord,
+		Interests:     m.Interests,
+		ContactNumber: m.ContactNumber,
+		FbLink:        m.FbLink,
+		HouseID:       m.HouseID,
+		ID:            m.ID,
+	}); err != nil {
+		return memberError(c, err, "update")
+	}
+	if err := tx.Commit(); err != nil {
+		return memberError(c, err, "update")
+	}
+
+	return c.JSON(http.StatusOK, toAdminMemberResponse(m))
+}
+
+// DeactivateMemberHandler soft-deletes a member: they can no longer sign in or use their API keys,
+// and are left out of member lookups, but their events and keys are kept. Their sessions are ended.
+func (h *Handler) DeactivateMemberHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deactivating member"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	m, err := qtx.AdminGetMemberForUpdate(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+		}
+		slog.Error("failed to get member", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deactivating member"})
+	}
+	if m.Status == repository.MembersStatusINACTIVE {
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success":   "Member already deactivated",
+			"member_id": m.ID,
+		})
+	}
+
+	if _, err := qtx.DeactivateMember(ctx, m.ID); err != nil {
+		slog.Error("failed to deactivate member", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deactivating member"})
+	}
+	revoked, err := qtx.RevokeMemberSessions(ctx, m.Email)
+	if err != nil {
+		slog.Error("failed to revoke member sessions", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deactivating member"})
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit member deactivation", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deactivating member"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":          "Member deactivated",
+		"member_id":        m.ID,
+		"sessions_revoked": revoked,
+	})
+}
+
+// ReactivateMemberHandler restores a deactivated member.
+func (h *Handler) ReactivateMemberHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	reactivated, err := q.ReactivateMember(ctx, int32(id))
+	if err != nil {
+		slog.Error("failed to reactivate member", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error reactivating member"})
+	}
+	if reactivated == 0 {
+		if _, err := q.AdminGetMember(ctx, int32(id)); err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+		} else if err != nil {
+			slog.Error("failed to get member", "error", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error reactivating member"})
+		}
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"success":   "Member already active",
+			"member_id": id,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":   "Member reactivated",
+		"member_id": id,
+	})
+}
@@ -0,0 +1,311 @@
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/go-sql-driver/mysql"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var adminMemberColumns = []string{
+	"id", "full_name", "nickname", "email", "telegram", "position_id", "committee_id", "college", "program",
+	"discord", "interests", "contact_number", "fb_link", "house_id", "status", "deactivated_at",
+}
+
+func newMemberContext(method, body, id string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/admin/members", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+	c.Set(middlewares.APIKeyContextKey, adminKey)
+	return c, rec
+}
+
+func TestCreateMemberHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = ?").
+			WithArgs(12345678).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM committees").
+			WithArgs("RND").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM houses").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM positions").
+			WithArgs("MEM").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery("SELECT id FROM members WHERE email = ?").
+			WithArgs("juan@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT m.email FROM member_emails e").
+			WithArgs("juan@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("INSERT INTO members").
+			WithArgs(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, "MEM", "RND", nil, nil, "juan.dc", nil, "+639171234567", nil, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = ?").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, "MEM", "RND", nil, nil, "juan.dc", nil, "+639171234567", nil, 1, "ACTIVE", nil))
+
+		c, rec := newMemberContext(http.MethodPost, `{
+			"id": 12345678, "full_name": "Juan Dela Cruz", "email": "Juan@DLSU.edu.ph",
+			"position_id": "MEM", "committee_id": "RND", "house_id": 1,
+			"discord": "Juan.DC", "contact_number": "0917 123 4567"
+		}`, "")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.CreateMemberHandler(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"ACTIVE"`)
+			assert.Contains(t, rec.Body.String(), `"house_id":1`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - unknown committee", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = ?").
+			WithArgs(12345678).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM committees").
+			WithArgs("XYZ").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		c, rec := newMemberContext(http.MethodPost, `{"id": 12345678, "full_name": "Juan", "email": "juan@dlsu.edu.ph", "committee_id": "XYZ"}`, "")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.CreateMemberHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), `committee \"XYZ\" does not exist`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - email taken", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = ?").
+			WithArgs(12345678).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT id FROM members WHERE email = ?").
+			WithArgs("juan@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11111111))
+
+		c, rec := newMemberContext(http.MethodPost, `{"id": 12345678, "full_name": "Juan", "email": "juan@dlsu.edu.ph"}`, "")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.CreateMemberHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - missing email", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		c, rec := newMemberContext(http.MethodPost, `{"id": 12345678, "full_name": "Juan"}`, "")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.CreateMemberHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestUpdateMemberHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", "Juan", "juan@dlsu.edu.ph", nil, "MEM", "RND", "CCS", nil, nil, nil, nil, nil, 1, "ACTIVE", nil))
+		mock.ExpectQuery("SELECT EXISTS\\(SELECT 1 FROM positions").
+			WithArgs("VP").
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectExec("UPDATE members").
+			WithArgs("Juan Dela Cruz", "Juan", "juan@dlsu.edu.ph", nil, "VP", "RND", "CCS", nil, nil, nil, nil, nil, nil, 12345678).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		c, rec := newMemberContext(http.MethodPatch, `{"position_id": "VP", "house_id": null}`, "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.UpdateMemberHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"position_id":"VP"`)
+			assert.Contains(t, rec.Body.String(), `"house_id":null`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - email still referenced", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+		mock.ExpectQuery("SELECT id FROM members WHERE email = ?").
+			WithArgs("juan.delacruz@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT m.email FROM member_emails e").
+			WithArgs("juan.delacruz@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectExec("UPDATE members").
+			WillReturnError(&mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row"})
+		mock.ExpectRollback()
+
+		c, rec := newMemberContext(http.MethodPatch, `{"email": "juan.delacruz@dlsu.edu.ph"}`, "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.UpdateMemberHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - status", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+		mock.ExpectRollback()
+
+		c, rec := newMemberContext(http.MethodPatch, `{"status": "INACTIVE"}`, "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.UpdateMemberHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
+
+func TestDeactivateMemberHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+		mock.ExpectExec("UPDATE members SET status = 'INACTIVE'").
+			WithArgs(12345678).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at").
+			WithArgs("juan@dlsu.edu.ph").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		c, rec := newMemberContext(http.MethodDelete, "", "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeactivateMemberHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"success": "Member deactivated", "member_id": 12345678, "sessions_revoked": 2}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("already deactivated", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "INACTIVE", time.Now()))
+		mock.ExpectRollback()
+
+		c, rec := newMemberContext(http.MethodDelete, "", "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeactivateMemberHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), "already deactivated")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = \\? FOR UPDATE").
+			WithArgs(12345678).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		c, rec := newMemberContext(http.MethodDelete, "", "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeactivateMemberHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
+
+func TestReactivateMemberHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE members SET status = 'ACTIVE'").
+		WithArgs(12345678).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	c, rec := newMemberContext(http.MethodPost, "", "12345678")
+	h := NewHandler(&mockDBService{db: db})
+
+	if assert.NoError(t, h.ReactivateMemberHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"success": "Member reactivated", "member_id": 12345678}`, rec.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
@@ -0,0 +1,266 @@
+// Package analytics aggregates API usage per key, project and route into time buckets.
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+const (
+	// DefaultBucketSize is the width of a usage bucket when USAGE_BUCKET_SIZE is not set.
+	DefaultBucketSize = time.Hour
+	// FlushInterval is how often the recorder writes its counters to the database.
+	FlushInterval = time.Minute
+)
+
+// BucketSizeFromEnv reads USAGE_BUCKET_SIZE (a Go duration such as "1h"), falling back to DefaultBucketSize.
+func BucketSizeFromEnv() time.Duration {
+	v := os.Getenv("USAGE_BUCKET_SIZE")
+	if v == "" {
+		return DefaultBucketSize
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		slog.Warn("invalid usage bucket size, using default", "value", v, "default", DefaultBucketSize)
+		return DefaultBucketSize
+	}
+	return d
+}
+
+type bucketKey struct {
+	start    time.Time
+	apiKeyID int32
+	route    string
+}
+
+type counter struct {
+	project        string
+	requests       int64
+	errors         int64
+	totalLatencyMs int64
+	maxLatencyMs   int64
+}
+
+// keyUse is the use of an API key since the last flush.
+type keyUse struct {
+	requests int64
+	lastUsed time.Time
+}
+
+type dayKey struct {
+	date     time.Time
+	apiKeyID int32
+}
+
+// dayCounter is a key's requests on one day since the last flush.
+type dayCounter struct {
+	requests    int64
+	rateLimited int64
+}
+
+// Recorder counts requests in memory and periodically adds them to api_usage_buckets,
+// api_key_daily_usage and the request_count and last_used_at of each key, so tracking usage costs
+// one write per key and route per flush instead of one per request. It is safe for concurrent use.
+type Recorder struct {
+	bucketSize time.Duration
+
+	mu       sync.Mutex
+	counters map[bucketKey]*counter
+	keys     map[int32]*keyUse
+	days     map[dayKey]*dayCounter
+}
+
+// NewRecorder creates a Recorder that aggregates requests into buckets of the given size.
+func NewRecorder(bucketSize time.Duration) *Recorder {
+	return &Recorder{
+		bucketSize: bucketSize,
+		counters:   make(map[bucketKey]*counter),
+		keys:       make(map[int32]*keyUse),
+		days:       make(map[dayKey]*dayCounter),
+	}
+}
+
+// Record counts one request made with an API key. Responses with a status of 400 or above count as errors.
+func (r *Recorder) Record(apiKeyID int32, project, route string, status int, latency time.Duration, at time.Time) {
+	k := bucketKey{start: at.UTC().Truncate(r.bucketSize), apiKeyID: apiKeyID, route: route}
+	ms := latency.Milliseconds()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.counters[k]
+	if !ok {
+		c = &counter{project: project}
+		r.counters[k] = c
+	}
+	c.requests++
+	if status >= http.StatusBadRequest {
+		c.errors++
+	}
+	c.totalLatencyMs += ms
+	c.maxLatencyMs = max(c.maxLatencyMs, ms)
+}
+
+// Touch counts one request authenticated with an API key, for the key's request_count and last_used_at.
+func (r *Recorder) Touch(apiKeyID int32, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.keys[apiKeyID]
+	if !ok {
+		u = &keyUse{}
+		r.keys[apiKeyID] = u
+	}
+	u.requests++
+	if at.After(u.lastUsed) {
+		u.lastUsed = at
+	}
+}
+
+// CountDay adds a request to the key's daily usage on the (server local) date of at, as a
+// rate-limited request if it was turned away by the rate limiter.
+func (r *Recorder) CountDay(apiKeyID int32, rateLimited bool, at time.Time) {
+	y, m, d := at.Date()
+	k := dayKey{date: time.Date(y, m, d, 0, 0, 0, 0, time.UTC), apiKeyID: apiKeyID}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.days[k]
+	if !ok {
+		c = &dayCounter{}
+		r.days[k] = c
+	}
+	if rateLimited {
+		c.rateLimited++
+	} else {
+		c.requests++
+	}
+}
+
+// Flush writes the counters recorded since the last flush. Counters that could not be written
+// are kept for the next flush.
+func (r *Recorder) Flush(ctx context.Context, q *repository.Queries) error {
+	r.mu.Lock()
+	pending := r.counters
+	r.counters = make(map[bucketKey]*counter)
+	keys := r.keys
+	r.keys = make(map[int32]*keyUse)
+	days := r.days
+	r.days = make(map[dayKey]*dayCounter)
+	r.mu.Unlock()
+
+	var errs []error
+	for k, c := range pending {
+		err := q.RecordUsageBucket(ctx, repository.RecordUsageBucketParams{
+			BucketStart:    k.start,
+			ApiKeyID:       k.apiKeyID,
+			Project:        c.project,
+			Route:          k.route,
+			RequestCount:   c.requests,
+			ErrorCount:     c.errors,
+			TotalLatencyMs: c.totalLatencyMs,
+			MaxLatencyMs:   c.maxLatencyMs,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			r.restore(k, c)
+		}
+	}
+	for id, u := range keys {
+		err := q.TouchAPIKey(ctx, repository.TouchAPIKeyParams{
+			LastUsedAt:   sql.NullTime{Time: u.lastUsed, Valid: true},
+			RequestCount: u.requests,
+			ApiKeyID:     id,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			r.restoreKey(id, u)
+		}
+	}
+	for k, c := range days {
+		err := q.RecordAPIKeyDailyUsage(ctx, repository.RecordAPIKeyDailyUsageParams{
+			ApiKeyID:         k.apiKeyID,
+			UsageDate:        k.date,
+			RequestCount:     c.requests,
+			RateLimitedCount: c.rateLimited,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			r.restoreDay(k, c)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// restore merges a counter that failed to flush back into the pending counters.
+func (r *Recorder) restore(k bucketKey, c *counter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur, ok := r.counters[k]
+	if !ok {
+		r.counters[k] = c
+		return
+	}
+	cur.requests += c.requests
+	cur.errors += c.errors
+	cur.totalLatencyMs += c.totalLatencyMs
+	cur.maxLatencyMs = max(cur.maxLatencyMs, c.maxLatencyMs)
+}
+
+// restoreKey merges the use of a key that failed to flush back into the pending use.
+func (r *Recorder) restoreKey(id int32, u *keyUse) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur, ok := r.keys[id]
+	if !ok {
+		r.keys[id] = u
+		return
+	}
+	cur.requests += u.requests
+	if u.lastUsed.After(cur.lastUsed) {
+		cur.lastUsed = u.lastUsed
+	}
+}
+
+// restoreDay merges a daily counter that failed to flush back into the pending counters.
+func (r *Recorder) restoreDay(k dayKey, c *dayCounter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cur, ok := r.days[k]
+	if !ok {
+		r.days[k] = c
+		return
+	}
+	cur.requests += c.requests
+	cur.rateLimited += c.rateLimited
+}
+
+// Run flushes the recorder every interval until ctx is done. The caller is expected to Flush
+// once more after the server has stopped taking requests.
+func (r *Recorder) Run(ctx context.Context, q *repository.Queries, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Flush(ctx, q); err != nil {
+				slog.Error("failed to flush usage analytics", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderFlush(t *testing.T) {
+	at := time.Date(2026, 10, 18, 8, 15, 0, 0, time.UTC)
+	bucket := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+
+	t.Run("aggregates per bucket", func(t *testing.T) {
+		r := NewRecorder(time.Hour)
+		r.Record(7, "Links", "GET /members", http.StatusOK, 20*time.Millisecond, at)
+		r.Record(7, "Links", "GET /members", http.StatusTooManyRequests, 40*time.Millisecond, at.Add(10*time.Minute))
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO api_usage_buckets").
+			WithArgs(bucket, 7, "Links", "GET /members", 2, 1, 60, 40).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, r.Flush(context.Background(), repository.New(db)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// Nothing left to write
+		assert.NoError(t, r.Flush(context.Background(), repository.New(db)))
+	})
+
+	t.Run("keeps counters that failed to flush", func(t *testing.T) {
+		r := NewRecorder(time.Hour)
+		r.Record(7, "Links", "GET /members", http.StatusOK, 20*time.Millisecond, at)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO api_usage_buckets").
+			WillReturnError(errors.New("connection refused"))
+		assert.Error(t, r.Flush(context.Background(), repository.New(db)))
+
+		r.Record(7, "Links", "GET /members", http.StatusOK, 10*time.Millisecond, at)
+		mock.ExpectExec("INSERT INTO api_usage_buckets").
+			WithArgs(bucket, 7, "Links", "GET /members", 2, 0, 30, 20).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, r.Flush(context.Background(), repository.New(db)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestRecorderTouch(t *testing.T) {
+	at := time.Date(2026, 10, 18, 8, 15, 0, 0, time.UTC)
+
+	r := NewRecorder(time.Hour)
+	r.Touch(7, at.Add(time.Minute))
+	r.Touch(7, at)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE api_keys SET last_used_at").
+		WithArgs(at.Add(time.Minute), 2, 7).
+		WillReturnError(errors.New("connection refused"))
+	assert.Error(t, r.Flush(context.Background(), repository.New(db)))
+
+	// Uses that failed to flush are merged into the next flush
+	r.Touch(7, at.Add(2*time.Minute))
+	mock.ExpectExec("UPDATE api_keys SET last_used_at").
+		WithArgs(at.Add(2*time.Minute), 3, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.Flush(context.Background(), repository.New(db)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecorderCountDay(t *testing.T) {
+	r := NewRecorder(time.Hour)
+	r.CountDay(7, false, time.Date(2026, 10, 18, 8, 15, 0, 0, time.UTC))
+	r.CountDay(7, false, time.Date(2026, 10, 18, 23, 59, 0, 0, time.UTC))
+	r.CountDay(7, true, time.Date(2026, 10, 18, 23, 59, 30, 0, time.UTC))
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO api_key_daily_usage").
+		WithArgs(7, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, r.Flush(context.Background(), repository.New(db)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	since := time.Date(2026, 9, 18, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT (.+) FROM api_usage_buckets").
+		WithArgs(since, until, "Links", "Links", nil, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"project", "route", "key_count", "request_count", "error_count", "total_latency_ms", "max_latency_ms", "last_seen"}).
+			AddRow("Links", "GET /members", 2, 100, 5, 2000, 90, until))
+
+	usage, err := Report(context.Background(), repository.New(db), Filter{Since: since, Until: until, Project: "Links"})
+	if assert.NoError(t, err) && assert.Len(t, usage, 1) {
+		assert.Equal(t, 0.05, usage[0].ErrorRate)
+		assert.Equal(t, 20.0, usage[0].AvgLatencyMs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
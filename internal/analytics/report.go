@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// Filter selects the usage to report on. Empty Project and Route match everything.
+type Filter struct {
+	Since   time.Time
+	Until   time.Time
+	Project string
+	Route   string
+}
+
+// RouteUsage is how much one project used one route over the report window.
+type RouteUsage struct {
+	Project      string    `json:"project"`
+	Route        string    `json:"route"`
+	KeyCount     int64     `json:"key_count"`
+	RequestCount int64     `json:"request_count"`
+	ErrorCount   int64     `json:"error_count"`
+	ErrorRate    float64   `json:"error_rate"`
+	AvgLatencyMs float64   `json:"avg_latency_ms"`
+	MaxLatencyMs int64     `json:"max_latency_ms"`
+	LastSeen     time.Time `json:"last_seen"`
+}
+
+// Report returns the usage of each route by each project, busiest first. Only flushed buckets are
+// included, and a bucket counts if it starts inside the window.
+func Report(ctx context.Context, q *repository.Queries, f Filter) ([]RouteUsage, error) {
+	rows, err := q.ListUsageByRoute(ctx, repository.ListUsageByRouteParams{
+		Since:   f.Since,
+		Until:   f.Until,
+		Project: sql.NullString{String: f.Project, Valid: f.Project != ""},
+		Route:   sql.NullString{String: f.Route, Valid: f.Route != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]RouteUsage, 0, len(rows))
+	for _, r := range rows {
+		u := RouteUsage{
+			Project:      r.Project,
+			Route:        r.Route,
+			KeyCount:     r.KeyCount,
+			RequestCount: r.RequestCount,
+			ErrorCount:   r.ErrorCount,
+			MaxLatencyMs: r.MaxLatencyMs,
+			LastSeen:     r.LastSeen,
+		}
+		if r.RequestCount > 0 {
+			u.ErrorRate = float64(r.ErrorCount) / float64(r.RequestCount)
+			u.AvgLatencyMs = float64(r.TotalLatencyMs) / float64(r.RequestCount)
+		}
+		usage = append(usage, u)
+	}
+	return usage, nil
+}
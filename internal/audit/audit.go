@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// Outcomes of an audited operation.
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+)
+
+// Target types used across handlers.
+const (
+	TargetAPIKey      = "api_key"
+	TargetMember      = "member"
+	TargetOAuthClient = "oauth_client"
+	TargetSession     = "member_session"
+	TargetTerm        = "term"
+)
+
+const (
+	targetContextKey   = "audit_target"
+	recordedContextKey = "audit_recorded"
+)
+
+// Event is a single entry of the audit log.
+type Event struct {
+	ActorEmail    string
+	ActorAPIKeyID int32 // 0 when the actor signed in with Google instead of an API key
+	Action        string
+	Route         string
+	TargetType    string
+	TargetID      string
+	Outcome       string
+	StatusCode    int
+	ClientIP      string
+	Details       map[string]any
+}
+
+type target struct {
+	targetType string
+	targetID   string
+}
+
+// SetTarget records which entity the current request operates on, for the audit middleware to pick up.
+func SetTarget(c echo.Context, targetType, targetID string) {
+	c.Set(targetContextKey, target{targetType: targetType, targetID: targetID})
+}
+
+// TargetFromContext returns the entity set with SetTarget, if any.
+func TargetFromContext(c echo.Context) (string, string) {
+	t, _ := c.Get(targetContextKey).(target)
+	return t.targetType, t.targetID
+}
+
+// SetRecorded marks the current request as already in the audit log, for handlers that record
+// their event in the same transaction as their change, so that the audit middleware does not
+// record it again.
+func SetRecorded(c echo.Context) {
+	c.Set(recordedContextKey, true)
+}
+
+// Recorded reports whether SetRecorded was called for the current request.
+func Recorded(c echo.Context) bool {
+	recorded, _ := c.Get(recordedContextKey).(bool)
+	return recorded
+}
+
+// OutcomeOf maps an HTTP status code to an audit outcome.
+func OutcomeOf(status int) string {
+	switch {
+	case status < http.StatusBadRequest:
+		return OutcomeSuccess
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	default:
+		return OutcomeFailure
+	}
+}
+
+// Record writes e to the audit log using q, so callers can make it part of their own transaction.
+func Record(ctx context.Context, q *repository.Queries, e Event) error {
+	var details sql.NullString
+	if e.Details != nil {
+		b, err := json.Marshal(e.Details)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit event details: %w", err)
+		}
+		details = sql.NullString{String: string(b), Valid: true}
+	}
+
+	return q.RecordAuditEvent(ctx, repository.RecordAuditEventParams{
+		ActorEmail:    e.ActorEmail,
+		ActorApiKeyID: sql.NullInt32{Int32: e.ActorAPIKeyID, Valid: e.ActorAPIKeyID != 0},
+		Action:        e.Action,
+		Route:         e.Route,
+		TargetType:    sql.NullString{String: e.TargetType, Valid: e.TargetType != ""},
+		TargetID:      sql.NullString{String: e.TargetID, Valid: e.TargetID != ""},
+		Outcome:       e.Outcome,
+		StatusCode:    int32(e.StatusCode),
+		ClientIp:      sql.NullString{String: e.ClientIP, Valid: e.ClientIP != ""},
+		Details:       details,
+	})
+}
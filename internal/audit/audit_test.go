@@ -0,0 +1,58 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutcomeOf(t *testing.T) {
+	assert.Equal(t, OutcomeSuccess, OutcomeOf(http.StatusOK))
+	assert.Equal(t, OutcomeDenied, OutcomeOf(http.StatusUnauthorized))
+	assert.Equal(t, OutcomeDenied, OutcomeOf(http.StatusForbidden))
+	assert.Equal(t, OutcomeFailure, OutcomeOf(http.StatusNotFound))
+	assert.Equal(t, OutcomeFailure, OutcomeOf(http.StatusInternalServerError))
+}
+
+func TestTarget(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	targetType, targetID := TargetFromContext(c)
+	assert.Empty(t, targetType)
+	assert.Empty(t, targetID)
+
+	SetTarget(c, TargetMember, "12345678")
+	targetType, targetID = TargetFromContext(c)
+	assert.Equal(t, TargetMember, targetType)
+	assert.Equal(t, "12345678", targetID)
+}
+
+func TestRecord(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO audit_events").
+		WithArgs("admin@dlsu.edu.ph", nil, "api_keys.issue", "POST /request-key", TargetAPIKey, "7", OutcomeSuccess, 200, "203.0.113.7", `{"project":"Links"}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = Record(t.Context(), repository.New(db), Event{
+		ActorEmail: "admin@dlsu.edu.ph",
+		Action:     "api_keys.issue",
+		Route:      "POST /request-key",
+		TargetType: TargetAPIKey,
+		TargetID:   "7",
+		Outcome:    OutcomeSuccess,
+		StatusCode: http.StatusOK,
+		ClientIP:   "203.0.113.7",
+		Details:    map[string]any{"project": "Links"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// APIKeyResponse describes an API key without its hash.
+type APIKeyResponse struct {
+	ApiKeyID      int32                  `json:"api_key_id"`
+	MemberEmail   string                 `json:"member_email"`
+	Project       helpers.NullableString `json:"project"`
+	AllowedOrigin helpers.NullableString `json:"allowed_origin"`
+	IsDev         bool                   `json:"is_dev"`
+	IsAdmin       bool                   `json:"is_admin"`
+	Scopes        []string               `json:"scopes"`
+	CreatedAt     helpers.NullableTime   `json:"created_at"`
+	ExpiresAt     helpers.NullableTime   `json:"expires_at"`
+}
+
+func toAPIKeyResponse(k repository.ListAPIKeysByEmailRow) APIKeyResponse {
+	return APIKeyResponse{
+		ApiKeyID:      k.ApiKeyID,
+		MemberEmail:   k.MemberEmail,
+		Project:       helpers.NullableString{NullString: k.Project},
+		AllowedOrigin: helpers.NullableString{NullString: k.AllowedOrigin},
+		IsDev:         k.IsDev,
+		IsAdmin:       k.IsAdmin,
+		Scopes:        ParseScopes(k.Scopes),
+		CreatedAt:     helpers.NullableTime{NullTime: k.CreatedAt},
+		ExpiresAt:     helpers.NullableTime{NullTime: k.ExpiresAt},
+	}
+}
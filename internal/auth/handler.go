@@ -1,41 +1,77 @@
 package auth
 
 import (
-	"crypto/sha256"
+	"context"
 	"database/sql"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"net/url"
-	"strings"
+	"strconv"
 
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
 	"github.com/dlsu-lscs/lscs-core-api/internal/database"
 	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
 	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
 	"github.com/labstack/echo/v4"
 )
 
 type RequestKeyRequest struct {
-	Email         string `json:"email" validate:"required,email"`
-	Project       string `json:"project"`
-	AllowedOrigin string `json:"allowed_origin"`
-	IsDev         bool   `json:"is_dev"`
-	IsAdmin       bool   `json:"is_admin"`
+	Email         string   `json:"email" validate:"required,email"`
+	Project       string   `json:"project"`
+	AllowedOrigin string   `json:"allowed_origin"`
+	IsDev         bool     `json:"is_dev"`
+	IsAdmin       bool     `json:"is_admin"`
+	Scopes        []string `json:"scopes"`
 }
 
 type Handler struct {
 	authService Service
 	dbService   database.Service
+	policy      *policy.Engine
 }
 
-func NewHandler(authService Service, dbService database.Service) *Handler {
+func NewHandler(authService Service, dbService database.Service, policyEngine *policy.Engine) *Handler {
 	return &Handler{
 		authService: authService,
 		dbService:   dbService,
+		policy:      policyEngine,
 	}
 }
 
+// issuerError is a failed API key eligibility check, shaped as the HTTP response to send.
+type issuerError struct {
+	status int
+	body   map[string]string
+}
+
+func (e *issuerError) Error() string {
+	return e.body["error"]
+}
+
+// loadKeyIssuer returns the member behind email if the policy lets them hold API keys.
+// Ineligible members are reported as an *issuerError.
+func (h *Handler) loadKeyIssuer(ctx context.Context, q *repository.Queries, email string) (repository.GetMemberInfoRow, error) {
+	memberInfo, err := q.GetMemberInfo(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return memberInfo, &issuerError{status: http.StatusNotFound, body: map[string]string{
+				"error": "Not an LSCS member",
+				"state": "absent",
+				"email": email,
+			}}
+		}
+		return memberInfo, err
+	}
+
+	if !h.policy.Allows(policy.ActionRequestAPIKey, policy.SubjectFromMember(memberInfo)) {
+		return memberInfo, &issuerError{status: http.StatusForbidden, body: map[string]string{"error": "User is not allowed to request API keys"}}
+	}
+
+	return memberInfo, nil
+}
+
 func (h *Handler) RequestKeyHandler(c echo.Context) error {
 	dbconn := h.dbService.GetConnection()
 	q := repository.New(dbconn)
@@ -51,38 +87,27 @@ func (h *Handler) RequestKeyHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot read body"})
 	}
 
-	// Check if user is an LSCS member and in RND
-	memberInfo, err := q.GetMemberInfo(ctx, email)
+	// Check if user is an LSCS member allowed to hold API keys
+	memberInfo, err := h.loadKeyIssuer(ctx, q, email)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			response := map[string]string{
-				"error": "Not an LSCS member",
-				"state": "absent",
-				"email": email,
-			}
-			return c.JSON(http.StatusNotFound, response)
+		var ie *issuerError
+		if errors.As(err, &ie) {
+			return c.JSON(ie.status, ie.body)
 		}
 		slog.Error("error checking email", "error", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
-
-	// only allow RND members and those that are AVPs and above
-	if helpers.NullStringToString(memberInfo.CommitteeName) != "Research and Development" {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "User is not a member of Research and Development"})
+	if req.IsAdmin && !h.policy.Allows(policy.ActionRequestAdminKey, policy.SubjectFromMember(memberInfo)) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "User is not allowed to request admin API keys"})
 	}
 
-	allowedPositions := map[string]bool{
-		"PRES": true,
-		"EVP":  true,
-		"VP":   true,
-		"AVP":  true,
-		"CT":   false,
-		"JO":   false,
-		"MEM":  false,
-	}
-	pos := helpers.NullStringToString(memberInfo.PositionID)
-	if !allowedPositions[pos] {
-		return c.JSON(http.StatusForbidden, map[string]string{"error": "User must be AVP or higher"})
+	// Admin keys can do everything, other keys only get the scopes they ask for
+	scopes := KnownScopes
+	if !req.IsAdmin {
+		scopes, err = ValidateScopes(req.Scopes)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
 	}
 
 	var allowedOriginForDB sql.NullString
@@ -92,49 +117,50 @@ func (h *Handler) RequestKeyHandler(c echo.Context) error {
 		allowedOriginForDB = sql.NullString{Valid: false}
 		isDevForDB = false
 	} else if req.IsDev {
-		if !strings.HasPrefix(req.AllowedOrigin, "http://localhost") {
+		if !helpers.IsLocalhostOrigin(req.AllowedOrigin) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "For dev keys, allowed_origin must start with http://localhost"})
 		}
 		allowedOriginForDB = sql.NullString{Valid: false}
 		isDevForDB = true
 	} else {
 		// Production key
-		// TODO: if "is_dev: true" (have expiry time for API_KEY token)
-		// TODO: only "is_admin: true" API_KEY do not expire
 		if req.AllowedOrigin == "" {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "allowed_origin is required for production keys"})
 		}
-		_, err := url.ParseRequestURI(req.AllowedOrigin)
-		if err != nil {
+		// Store the origin the way browsers send it, so it can be compared against the Origin header
+		origin := helpers.NormalizeOrigin(req.AllowedOrigin)
+		if origin == "" {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid URL for allowed_origin"})
 		}
-		if strings.HasPrefix(req.AllowedOrigin, "http://localhost") {
+		if helpers.IsLocalhostOrigin(origin) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "localhost is not a valid origin for production keys"})
 		}
 
-		exists, err := q.CheckAllowedOriginExists(ctx, sql.NullString{String: req.AllowedOrigin, Valid: true})
+		exists, err := q.CheckAllowedOriginExists(ctx, sql.NullString{String: origin, Valid: true})
 		if err != nil {
 			slog.Error("failed to check allowed origin", "error", err)
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error checking origin"})
 		}
 		if exists {
-			return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("API key for origin %s already exists", req.AllowedOrigin)})
+			return c.JSON(http.StatusConflict, map[string]string{"error": fmt.Sprintf("API key for origin %s already exists", origin)})
 		}
 
-		allowedOriginForDB = sql.NullString{String: req.AllowedOrigin, Valid: true}
+		allowedOriginForDB = sql.NullString{String: origin, Valid: true}
 		isDevForDB = false
 	}
 
+	// Dev and production keys expire, admin keys are permanent unless configured otherwise
+	expiresAt := h.authService.KeyExpiry(ClassOf(isDevForDB, req.IsAdmin))
+
 	// Generate JWT
-	tokenString, err := h.authService.GenerateJWT(memberInfo.Email)
+	tokenString, err := h.authService.GenerateJWT(memberInfo.Email, scopes, expiresAt)
 	if err != nil {
 		slog.Error("failed to generate token", "error", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error generating token"})
 	}
 
 	// Hash the token
-	hash := sha256.Sum256([]byte(tokenString))
-	hashStr := hex.EncodeToString(hash[:])
+	hashStr := HashAPIKey(tokenString)
 
 	// Handle nullable project field
 	var projectForDB sql.NullString
@@ -152,18 +178,26 @@ func (h *Handler) RequestKeyHandler(c echo.Context) error {
 		AllowedOrigin: allowedOriginForDB,
 		IsDev:         isDevForDB,
 		IsAdmin:       req.IsAdmin,
-		ExpiresAt:     sql.NullTime{Valid: false},
+		Scopes:        FormatScopes(scopes),
+		ExpiresAt:     sql.NullTime{Time: expiresAt, Valid: !expiresAt.IsZero()},
 	}
 
-	err = q.StoreAPIKey(ctx, params)
+	apiKeyID, err := q.StoreAPIKey(ctx, params)
 	if err != nil {
 		slog.Error("failed to store api key", "error", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error storing API key"})
 	}
+	audit.SetTarget(c, audit.TargetAPIKey, strconv.FormatInt(apiKeyID, 10))
 
 	response := map[string]interface{}{
-		"email":   memberInfo.Email,
-		"api_key": tokenString,
+		"api_key_id": apiKeyID,
+		"email":      memberInfo.Email,
+		"api_key":    tokenString,
+		"scopes":     scopes,
+		"expires_at": nil,
+	}
+	if !expiresAt.IsZero() {
+		response["expires_at"] = expiresAt
 	}
 
 	return c.JSON(http.StatusOK, response)
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 )
@@ -16,10 +18,17 @@ import (
 // mockAuthService is a mock implementation of the auth.Service interface.
 type mockAuthService struct{}
 
-func (m *mockAuthService) GenerateJWT(email string) (string, error) {
+func (m *mockAuthService) GenerateJWT(email string, scopes []string, expiresAt time.Time) (string, error) {
 	return "test_jwt_token", nil
 }
 
+func (m *mockAuthService) KeyExpiry(class KeyClass) time.Time {
+	if class == KeyClassAdmin {
+		return time.Time{}
+	}
+	return time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+}
+
 // mockDBService is a mock implementation of the database.Service interface.
 type mockDBService struct {
 	db *sql.DB
@@ -37,88 +46,133 @@ func (m *mockDBService) GetConnection() *sql.DB {
 	return m.db
 }
 
+var memberInfoColumns = []string{
+	"id", "email", "full_name", "nickname", "committee_id", "committee_name", "division_id", "division_name",
+	"position_id", "position_name", "house_name", "contact_number", "college", "program", "interests", "discord", "fb_link", "telegram",
+}
+
 func TestRequestKeyHandler(t *testing.T) {
 	t.Run("success - RND member", func(t *testing.T) {
 		e := echo.New()
-		reqBody := EmailRequest{Email: "test@dlsu.edu.ph"}
+		email := "test@dlsu.edu.ph"
+		reqBody := RequestKeyRequest{Project: "Test Project", AllowedOrigin: "https://test.dlsu-lscs.org"}
 		jsonBody, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/request-key", bytes.NewReader(jsonBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		c.Set("user_email", email)
 
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		mock.ExpectQuery("SELECT (.+) FROM members m").
-			WithArgs(reqBody.Email).
-			WillReturnRows(sqlmock.NewRows([]string{"email", "full_name", "committee_name", "division_name", "position_name", "committee_id", "division_id", "house_name"}).
-				AddRow(reqBody.Email, "Test User", "Research and Development", "", "", "", "", ""))
+			WithArgs(email).
+			WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+				AddRow(1, email, "Test User", nil, "RND", "Research and Development", "INT", "Internals", "AVP", "Assistant Vice President", nil, nil, nil, nil, nil, nil, nil, nil))
+
+		mock.ExpectQuery("SELECT EXISTS").
+			WithArgs(reqBody.AllowedOrigin).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 		mock.ExpectExec("INSERT INTO api_keys").
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		dbService := &mockDBService{db: db}
 		authService := &mockAuthService{}
-		h := NewHandler(authService, dbService)
+		h := NewHandler(authService, dbService, policy.New(policy.DefaultRules()))
 
 		if assert.NoError(t, h.RequestKeyHandler(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
 			var resp map[string]interface{}
 			json.Unmarshal(rec.Body.Bytes(), &resp)
-			assert.Equal(t, reqBody.Email, resp["email"])
+			assert.Equal(t, email, resp["email"])
 			assert.Equal(t, "test_jwt_token", resp["api_key"])
+			assert.Equal(t, "2030-01-01T00:00:00Z", resp["expires_at"])
 		}
 	})
 
 	t.Run("fail - non-RND member", func(t *testing.T) {
 		e := echo.New()
-		reqBody := EmailRequest{Email: "test@dlsu.edu.ph"}
+		email := "test@dlsu.edu.ph"
+		reqBody := RequestKeyRequest{Project: "Test Project", AllowedOrigin: "https://test.dlsu-lscs.org"}
 		jsonBody, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/request-key", bytes.NewReader(jsonBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		c.Set("user_email", email)
 
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		mock.ExpectQuery("SELECT (.+) FROM members m").
-			WithArgs(reqBody.Email).
-			WillReturnRows(sqlmock.NewRows([]string{"email", "full_name", "committee_name", "division_name", "position_name", "committee_id", "division_id", "house_name"}).
-				AddRow(reqBody.Email, "Test User", "Not RND", "", "", "", "", ""))
+			WithArgs(email).
+			WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+				AddRow(1, email, "Test User", nil, "PUB", "Publicity", "EXT", "Externals", "AVP", "Assistant Vice President", nil, nil, nil, nil, nil, nil, nil, nil))
 
 		dbService := &mockDBService{db: db}
 		authService := &mockAuthService{}
-		h := NewHandler(authService, dbService)
+		h := NewHandler(authService, dbService, policy.New(policy.DefaultRules()))
+
+		if assert.NoError(t, h.RequestKeyHandler(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("fail - admin key not allowed by policy", func(t *testing.T) {
+		e := echo.New()
+		email := "test@dlsu.edu.ph"
+		jsonBody, _ := json.Marshal(RequestKeyRequest{Project: "Test Project", IsAdmin: true})
+		req := httptest.NewRequest(http.MethodPost, "/request-key", bytes.NewReader(jsonBody))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_email", email)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members m").
+			WithArgs(email).
+			WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+				AddRow(1, email, "Test User", nil, "RND", "Research and Development", "INT", "Internals", "MEM", "Member", nil, nil, nil, nil, nil, nil, nil, nil))
+
+		// Every RND member may hold keys, but not admin keys
+		rules := []policy.Rule{{Action: policy.ActionRequestAPIKey, Committees: []string{"RND"}}}
+		h := NewHandler(&mockAuthService{}, &mockDBService{db: db}, policy.New(rules))
 
 		if assert.NoError(t, h.RequestKeyHandler(c)) {
 			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		}
 	})
 
 	t.Run("fail - not an LSCS member", func(t *testing.T) {
 		e := echo.New()
-		reqBody := EmailRequest{Email: "test@dlsu.edu.ph"}
+		email := "test@dlsu.edu.ph"
+		reqBody := RequestKeyRequest{Project: "Test Project", AllowedOrigin: "https://test.dlsu-lscs.org"}
 		jsonBody, _ := json.Marshal(reqBody)
 		req := httptest.NewRequest(http.MethodPost, "/request-key", bytes.NewReader(jsonBody))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
 		c := e.NewContext(req, rec)
+		c.Set("user_email", email)
 
 		db, mock, err := sqlmock.New()
 		assert.NoError(t, err)
 		defer db.Close()
 
 		mock.ExpectQuery("SELECT (.+) FROM members m").
-			WithArgs(reqBody.Email).
+			WithArgs(email).
 			WillReturnError(sql.ErrNoRows)
 
 		dbService := &mockDBService{db: db}
 		authService := &mockAuthService{}
-		h := NewHandler(authService, dbService)
+		h := NewHandler(authService, dbService, policy.New(policy.DefaultRules()))
 
 		if assert.NoError(t, h.RequestKeyHandler(c)) {
 			assert.Equal(t, http.StatusNotFound, rec.Code)
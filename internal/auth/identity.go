@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// DefaultHostedDomain is the Google Workspace domain members sign in with.
+const DefaultHostedDomain = "dlsu.edu.ph"
+
+// ErrInvalidIDToken is returned for ID tokens that are malformed, expired, badly signed or meant
+// for another audience.
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// Identity is who an ID token says its bearer is.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// HostedDomain is the Google Workspace domain of the account, empty for personal accounts.
+	HostedDomain string
+	Audience     string
+}
+
+// IdentityVerifier checks an ID token and returns the identity it vouches for. Errors wrap
+// ErrInvalidIDToken. Whether the identity may sign in is up to SignInRules.
+type IdentityVerifier interface {
+	Verify(ctx context.Context, idToken string) (Identity, error)
+}
+
+// identityRules are the checks every IdentityVerifier applies once a token's signature is valid.
+type identityRules struct {
+	audiences []string
+}
+
+func (r identityRules) check(id Identity) error {
+	if !slices.Contains(r.audiences, id.Audience) {
+		return fmt.Errorf("%w: unexpected audience %q", ErrInvalidIDToken, id.Audience)
+	}
+	return nil
+}
+
+// IdentityVerifierFromEnv builds the verifier for Google ID tokens. Tokens must be issued to
+// GOOGLE_CLIENT_ID or one of the comma-separated client IDs in GOOGLE_AUDIENCES.
+//
+// For tests and offline development, IDENTITY_KEYS_DIR replaces Google with the keys in that
+// directory, read the same way as JWT_KEYS_DIR.
+func IdentityVerifierFromEnv() (IdentityVerifier, error) {
+	var audiences []string
+	for _, aud := range append([]string{os.Getenv("GOOGLE_CLIENT_ID")}, strings.Split(os.Getenv("GOOGLE_AUDIENCES"), ",")...) {
+		if aud = strings.TrimSpace(aud); aud != "" && !slices.Contains(audiences, aud) {
+			audiences = append(audiences, aud)
+		}
+	}
+	if len(audiences) == 0 {
+		return nil, errors.New("GOOGLE_CLIENT_ID is not set")
+	}
+
+	if dir := os.Getenv("IDENTITY_KEYS_DIR"); dir != "" {
+		keys, err := LoadKeySet(dir, "")
+		if err != nil {
+			return nil, fmt.Errorf("IDENTITY_KEYS_DIR: %w", err)
+		}
+		return NewStaticVerifier(keys, audiences), nil
+	}
+	return NewGoogleVerifier(audiences), nil
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/idtoken"
+)
+
+type googleVerifier struct {
+	identityRules
+	validate func(ctx context.Context, idToken, audience string) (*idtoken.Payload, error)
+}
+
+// NewGoogleVerifier verifies Google ID tokens issued to any of audiences.
+func NewGoogleVerifier(audiences []string) IdentityVerifier {
+	return &googleVerifier{
+		identityRules: identityRules{audiences: audiences},
+		validate:      idtoken.Validate,
+	}
+}
+
+func (v *googleVerifier) Verify(ctx context.Context, idToken string) (Identity, error) {
+	// The audience is checked against the whole list below
+	payload, err := v.validate(ctx, idToken, "")
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	id := Identity{
+		Subject:  payload.Subject,
+		Audience: payload.Audience,
+	}
+	id.Email, _ = payload.Claims["email"].(string)
+	id.EmailVerified, _ = payload.Claims["email_verified"].(bool)
+	id.HostedDomain, _ = payload.Claims["hd"].(string)
+
+	if err := v.check(id); err != nil {
+		return Identity{}, err
+	}
+	return id, nil
+}
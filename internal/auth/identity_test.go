@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/idtoken"
+)
+
+func testIdentityKeys(t *testing.T) *KeySet {
+	dir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	writePrivateKey(t, dir, "dev", priv)
+	keys, err := LoadKeySet(dir, "")
+	require.NoError(t, err)
+	return keys
+}
+
+func idToken(t *testing.T, keys *KeySet, audience, hd string, expiresAt time.Time) string {
+	token, err := keys.Sign(&IDTokenClaims{
+		Email:         "test@dlsu.edu.ph",
+		EmailVerified: true,
+		HostedDomain:  hd,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "1234567890",
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func TestStaticVerifier(t *testing.T) {
+	keys := testIdentityKeys(t)
+	verifier := NewStaticVerifier(keys, []string{"web-client", "mobile-client"})
+	inAnHour := time.Now().Add(time.Hour)
+
+	t.Run("valid token", func(t *testing.T) {
+		id, err := verifier.Verify(context.Background(), idToken(t, keys, "web-client", "dlsu.edu.ph", inAnHour))
+		require.NoError(t, err)
+		assert.Equal(t, Identity{
+			Subject:       "1234567890",
+			Email:         "test@dlsu.edu.ph",
+			EmailVerified: true,
+			HostedDomain:  "dlsu.edu.ph",
+			Audience:      "web-client",
+		}, id)
+	})
+
+	t.Run("any accepted audience", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), idToken(t, keys, "mobile-client", "dlsu.edu.ph", inAnHour))
+		assert.NoError(t, err)
+	})
+
+	t.Run("other audience", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), idToken(t, keys, "someone-else", "dlsu.edu.ph", inAnHour))
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("personal account", func(t *testing.T) {
+		// Whether personal accounts can sign in is up to SignInRules
+		id, err := verifier.Verify(context.Background(), idToken(t, keys, "web-client", "", inAnHour))
+		require.NoError(t, err)
+		assert.Empty(t, id.HostedDomain)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), idToken(t, keys, "web-client", "dlsu.edu.ph", time.Now().Add(-time.Minute)))
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("signed with another key", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), idToken(t, testIdentityKeys(t), "web-client", "dlsu.edu.ph", inAnHour))
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestGoogleVerifier(t *testing.T) {
+	verifier := &googleVerifier{
+		identityRules: identityRules{audiences: []string{"web-client", "mobile-client"}},
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		verifier.validate = func(ctx context.Context, token, audience string) (*idtoken.Payload, error) {
+			assert.Empty(t, audience)
+			return &idtoken.Payload{
+				Subject:  "1234567890",
+				Audience: "mobile-client",
+				Claims:   map[string]interface{}{"email": "test@dlsu.edu.ph", "email_verified": true, "hd": "dlsu.edu.ph"},
+			}, nil
+		}
+		id, err := verifier.Verify(context.Background(), "token")
+		require.NoError(t, err)
+		assert.Equal(t, "test@dlsu.edu.ph", id.Email)
+		assert.True(t, id.EmailVerified)
+		assert.Equal(t, "dlsu.edu.ph", id.HostedDomain)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		verifier.validate = func(ctx context.Context, token, audience string) (*idtoken.Payload, error) {
+			return nil, errors.New("token expired")
+		}
+		_, err := verifier.Verify(context.Background(), "token")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+
+	t.Run("other audience", func(t *testing.T) {
+		verifier.validate = func(ctx context.Context, token, audience string) (*idtoken.Payload, error) {
+			return &idtoken.Payload{Audience: "someone-else", Claims: map[string]interface{}{}}, nil
+		}
+		_, err := verifier.Verify(context.Background(), "token")
+		assert.ErrorIs(t, err, ErrInvalidIDToken)
+	})
+}
+
+func TestIdentityVerifierFromEnv(t *testing.T) {
+	t.Run("requires a client id", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLIENT_ID", "")
+		t.Setenv("GOOGLE_AUDIENCES", "")
+		_, err := IdentityVerifierFromEnv()
+		assert.Error(t, err)
+	})
+
+	t.Run("google", func(t *testing.T) {
+		t.Setenv("GOOGLE_CLIENT_ID", "web-client")
+		t.Setenv("GOOGLE_AUDIENCES", "mobile-client, web-client")
+		t.Setenv("IDENTITY_KEYS_DIR", "")
+		v, err := IdentityVerifierFromEnv()
+		require.NoError(t, err)
+		g, ok := v.(*googleVerifier)
+		require.True(t, ok)
+		assert.Equal(t, []string{"web-client", "mobile-client"}, g.audiences)
+	})
+
+	t.Run("static keys", func(t *testing.T) {
+		dir := t.TempDir()
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		writePrivateKey(t, dir, "dev", priv)
+
+		t.Setenv("GOOGLE_CLIENT_ID", "web-client")
+		t.Setenv("IDENTITY_KEYS_DIR", dir)
+		v, err := IdentityVerifierFromEnv()
+		require.NoError(t, err)
+		_, ok := v.(*staticVerifier)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,21 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// JWKSHandler serves the public keys API keys are signed with, so other services can verify
+// them without holding a secret. It serves an empty set when tokens are signed with JWT_SECRET.
+func JWKSHandler(keys *KeySet) echo.HandlerFunc {
+	set := JWKSet{Keys: []JWK{}}
+	if keys != nil {
+		set = keys.JWKS()
+	}
+
+	return func(c echo.Context) error {
+		c.Response().Header().Set("Cache-Control", "public, max-age=300")
+		return c.JSON(http.StatusOK, set)
+	}
+}
@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// ListKeysHandler returns the API keys of the signed-in member.
+func (h *Handler) ListKeysHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+	email := c.Get("user_email").(string)
+
+	keys, err := q.ListAPIKeysByEmail(c.Request().Context(), email)
+	if err != nil {
+		slog.Error("failed to list api keys", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error listing API keys"})
+	}
+
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		response = append(response, toAPIKeyResponse(k))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"keys": response,
+	})
+}
+
+// RevokeKeyHandler deletes one of the signed-in member's API keys.
+func (h *Handler) RevokeKeyHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+	email := c.Get("user_email").(string)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid API key id"})
+	}
+	audit.SetTarget(c, audit.TargetAPIKey, strconv.Itoa(id))
+
+	deleted, err := q.RevokeAPIKey(c.Request().Context(), repository.RevokeAPIKeyParams{
+		ApiKeyID:    int32(id),
+		MemberEmail: email,
+	})
+	if err != nil {
+		slog.Error("failed to revoke api key", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error revoking API key"})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success":    "API key revoked",
+		"api_key_id": id,
+	})
+}
+
+// RotateKeyHandler issues a replacement for one of the signed-in member's API keys and revokes
+// the old one in the same transaction. The new key keeps the project, origin, flags and scopes of the old
+// key and gets a fresh lifetime. The member must still be allowed to hold API keys, and admin
+// keys can only be rotated by members who may still request them.
+func (h *Handler) RotateKeyHandler(c echo.Context) error {
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+	ctx := c.Request().Context()
+	email := c.Get("user_email").(string)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid API key id"})
+	}
+	audit.SetTarget(c, audit.TargetAPIKey, strconv.Itoa(id))
+
+	memberInfo, err := h.loadKeyIssuer(ctx, q, email)
+	if err != nil {
+		var ie *issuerError
+		if errors.As(err, &ie) {
+			return c.JSON(ie.status, ie.body)
+		}
+		slog.Error("error checking email", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error rotating API key"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	old, err := qtx.GetAPIKeyByIDAndEmail(ctx, repository.GetAPIKeyByIDAndEmailParams{
+		ApiKeyID:    int32(id),
+		MemberEmail: memberInfo.Email,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+		}
+		slog.Error("failed to get api key", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error rotating API key"})
+	}
+	if old.IsAdmin && !h.policy.Allows(policy.ActionRequestAdminKey, policy.SubjectFromMember(memberInfo)) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "User is not allowed to request admin API keys"})
+	}
+
+	// The old key has to go first since allowed_origin is unique. A concurrent rotation or
+	// revocation of the same key may have deleted it since it was read; only one of them may
+	// issue a replacement.
+	deleted, err := qtx.RevokeAPIKey(ctx, repository.RevokeAPIKeyParams{
+		ApiKeyID:    old.ApiKeyID,
+		MemberEmail: old.MemberEmail,
+	})
+	if err != nil {
+		slog.Error("failed to revoke api key", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error rotating API key"})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "API key not found"})
+	}
+
+	expiresAt := h.authService.KeyExpiry(ClassOf(old.IsDev, old.IsAdmin))
+	tokenString, err := h.authService.GenerateJWT(old.MemberEmail, ParseScopes(old.Scopes), expiresAt)
+	if err != nil {
+		slog.Error("failed to generate token", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error generating token"})
+	}
+
+	newID, err := qtx.StoreAPIKey(ctx, repository.StoreAPIKeyParams{
+		MemberEmail:   old.MemberEmail,
+		ApiKeyHash:    HashAPIKey(tokenString),
+		Project:       old.Project,
+		AllowedOrigin: old.AllowedOrigin,
+		IsDev:         old.IsDev,
+		IsAdmin:       old.IsAdmin,
+		Scopes:        old.Scopes,
+		ExpiresAt:     sql.NullTime{Time: expiresAt, Valid: !expiresAt.IsZero()},
+	})
+	if err != nil {
+		slog.Error("failed to store api key", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error storing API key"})
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit api key rotation", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error rotating API key"})
+	}
+
+	response := map[string]interface{}{
+		"api_key_id":         newID,
+		"revoked_api_key_id": old.ApiKeyID,
+		"email":              old.MemberEmail,
+		"api_key":            tokenString,
+		"scopes":             ParseScopes(old.Scopes),
+		"expires_at":         nil,
+	}
+	if !expiresAt.IsZero() {
+		response["expires_at"] = expiresAt
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
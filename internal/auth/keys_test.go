@@ -0,0 +1,180 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var apiKeyColumns = []string{"api_key_id", "member_email", "project", "allowed_origin", "is_dev", "is_admin", "scopes", "created_at", "expires_at"}
+
+func TestListKeysHandler(t *testing.T) {
+	email := "test@dlsu.edu.ph"
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/keys", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_email", email)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE member_email = ?").
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(2, email, "Links", "https://links.app.dlsu-lscs.org", false, false, "members:read committees:read", time.Now(), time.Now().Add(time.Hour)).
+			AddRow(1, email, nil, nil, false, true, "members:read members:pii committees:read events:write", time.Now(), nil))
+
+	h := NewHandler(&mockAuthService{}, &mockDBService{db: db}, policy.New(policy.DefaultRules()))
+
+	if assert.NoError(t, h.ListKeysHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp map[string][]map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		assert.Len(t, resp["keys"], 2)
+		assert.NotContains(t, resp["keys"][0], "api_key_hash")
+		assert.Nil(t, resp["keys"][1]["expires_at"])
+	}
+}
+
+func TestRevokeKeyHandler(t *testing.T) {
+	email := "test@dlsu.edu.ph"
+
+	newContext := func() (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/keys/7", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("7")
+		c.Set("user_email", email)
+		return c, rec
+	}
+
+	t.Run("success", func(t *testing.T) {
+		c, rec := newContext()
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("DELETE FROM api_keys").
+			WithArgs(7, email).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		h := NewHandler(&mockAuthService{}, &mockDBService{db: db}, policy.New(policy.DefaultRules()))
+
+		if assert.NoError(t, h.RevokeKeyHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("fail - someone else's key", func(t *testing.T) {
+		c, rec := newContext()
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("DELETE FROM api_keys").
+			WithArgs(7, email).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		h := NewHandler(&mockAuthService{}, &mockDBService{db: db}, policy.New(policy.DefaultRules()))
+
+		if assert.NoError(t, h.RevokeKeyHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+	})
+}
+
+func TestRotateKeyHandler(t *testing.T) {
+	email := "test@dlsu.edu.ph"
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/keys/7/rotate", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+	c.Set("user_email", email)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM members m").
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(1, email, "Test User", nil, "RND", "Research and Development", "INT", "Internals", "AVP", "Assistant Vice President", nil, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE api_key_id = ?").
+		WithArgs(7, email).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(7, email, "Links", "https://links.app.dlsu-lscs.org", false, false, "members:read committees:read", time.Now(), time.Now().Add(time.Hour)))
+	mock.ExpectExec("DELETE FROM api_keys").
+		WithArgs(7, email).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO api_keys").
+		WillReturnResult(sqlmock.NewResult(8, 1))
+	mock.ExpectCommit()
+
+	h := NewHandler(&mockAuthService{}, &mockDBService{db: db}, policy.New(policy.DefaultRules()))
+
+	if assert.NoError(t, h.RotateKeyHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var resp map[string]interface{}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		assert.Equal(t, float64(8), resp["api_key_id"])
+		assert.Equal(t, float64(7), resp["revoked_api_key_id"])
+		assert.Equal(t, "test_jwt_token", resp["api_key"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestRotateKeyHandlerAlreadyRevoked(t *testing.T) {
+	email := "test@dlsu.edu.ph"
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/keys/7/rotate", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("7")
+	c.Set("user_email", email)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM members m").
+		WithArgs(email).
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(1, email, "Test User", nil, "RND", "Research and Development", "INT", "Internals", "AVP", "Assistant Vice President", nil, nil, nil, nil, nil, nil, nil, nil))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM api_keys WHERE api_key_id = ?").
+		WithArgs(7, email).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+			AddRow(7, email, "Links", nil, true, false, "members:read", time.Now(), nil))
+	// A concurrent rotation deleted the key after it was read, so no second replacement is issued
+	mock.ExpectExec("DELETE FROM api_keys").
+		WithArgs(7, email).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	h := NewHandler(&mockAuthService{}, &mockDBService{db: db}, policy.New(policy.DefaultRules()))
+
+	if assert.NoError(t, h.RotateKeyHandler(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
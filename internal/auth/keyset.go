@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing keys.
+const minRSABits = 2048
+
+// SigningKey is one asymmetric key of a KeySet. Keys loaded from a public key file can only verify.
+type SigningKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	Public crypto.PublicKey
+	signer crypto.Signer
+}
+
+// CanSign reports whether the private half of the key is available.
+func (k *SigningKey) CanSign() bool {
+	return k.signer != nil
+}
+
+// KeySet holds the keys tokens are verified with, one of which new tokens are signed with.
+// Keys are identified by the kid header of the tokens they sign.
+type KeySet struct {
+	signing *SigningKey
+	keys    map[string]*SigningKey
+}
+
+// NewKeySet creates a KeySet that signs with the key whose ID is signingKID.
+func NewKeySet(keys []*SigningKey, signingKID string) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey, len(keys))}
+	for _, k := range keys {
+		if _, ok := ks.keys[k.ID]; ok {
+			return nil, fmt.Errorf("duplicate jwt key id %q", k.ID)
+		}
+		ks.keys[k.ID] = k
+	}
+
+	signing, ok := ks.keys[signingKID]
+	if !ok {
+		return nil, fmt.Errorf("jwt signing key %q not found", signingKID)
+	}
+	if !signing.CanSign() {
+		return nil, fmt.Errorf("jwt signing key %q has no private key", signingKID)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+// LoadKeySet reads every .pem file in dir as a key named after the file, so keys/2026-10.pem has
+// the ID "2026-10". Files can hold a PKCS #8 private key (RSA or Ed25519), which can sign and
+// verify, or a PKIX public key, which can only verify. If signingKID is empty, dir must hold
+// exactly one private key.
+func LoadKeySet(dir, signingKID string) (*KeySet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no .pem files in jwt keys directory %s", dir)
+	}
+
+	var keys []*SigningKey
+	var private []string
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		k, err := ParseSigningKey(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		keys = append(keys, k)
+		if k.CanSign() {
+			private = append(private, id)
+		}
+	}
+
+	if signingKID == "" {
+		if len(private) != 1 {
+			return nil, fmt.Errorf("jwt keys directory %s has %d private keys, set JWT_SIGNING_KID to pick one", dir, len(private))
+		}
+		signingKID = private[0]
+	}
+
+	return NewKeySet(keys, signingKID)
+}
+
+// KeySetFromEnv loads the keys in JWT_KEYS_DIR and signs with JWT_SIGNING_KID. It returns a nil
+// KeySet if JWT_KEYS_DIR is not set, in which case tokens are signed with JWT_SECRET.
+func KeySetFromEnv() (*KeySet, error) {
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		return nil, nil
+	}
+	return LoadKeySet(dir, os.Getenv("JWT_SIGNING_KID"))
+}
+
+// ParseSigningKey parses a PEM encoded PKCS #8 private key or PKIX public key.
+func ParseSigningKey(id string, data []byte) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	k := &SigningKey{ID: id}
+	switch block.Type {
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := priv.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", priv)
+		}
+		k.signer = signer
+		k.Public = signer.Public()
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.Public = pub
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q, expected PRIVATE KEY or PUBLIC KEY", block.Type)
+	}
+
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSABits)
+		}
+		k.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", pub)
+	}
+	return k, nil
+}
+
+// SigningKID returns the ID of the key new tokens are signed with.
+func (ks *KeySet) SigningKID() string {
+	return ks.signing.ID
+}
+
+// SigningAlg returns the JWS algorithm of the signing key, such as "EdDSA".
+func (ks *KeySet) SigningAlg() string {
+	return ks.signing.Method.Alg()
+}
+
+// Sign signs claims with the signing key and sets the kid header.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.signer)
+}
+
+// Key returns the public key a token must be verified with, based on its kid and alg headers.
+func (ks *KeySet) Key(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	k, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown jwt key id %q", kid)
+	}
+	if token.Method.Alg() != k.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+	}
+	return k.Public, nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is a JSON Web Key Set.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key in the set, sorted by ID.
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+		switch pub := k.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// Keyfunc returns the jwt.Keyfunc API keys are verified with. Tokens with a kid header are checked
+// against keys; tokens without one are legacy HS256 keys checked against secret, which is only
+// allowed while a secret is configured.
+func Keyfunc(secret string, keys *KeySet) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Header["kid"]; ok && keys != nil {
+			return keys.Key(token)
+		}
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		if secret == "" {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return []byte(secret), nil
+	}
+}
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePrivateKey(t *testing.T, dir, id string, key any) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, dir, id, "PRIVATE KEY", der)
+}
+
+func writePublicKey(t *testing.T, dir, id string, key any) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	writePEM(t, dir, id, "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, dir, id, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), data, 0o600))
+}
+
+func verify(tokenString string, keyfunc jwt.Keyfunc) (*JwtCustomClaims, error) {
+	claims := new(JwtCustomClaims)
+	_, err := jwt.ParseWithClaims(tokenString, claims, keyfunc)
+	return claims, err
+}
+
+func TestLoadKeySet(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	t.Run("single private key is the signing key", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "2026-10", edKey)
+
+		ks, err := LoadKeySet(dir, "")
+		require.NoError(t, err)
+		assert.Equal(t, "2026-10", ks.SigningKID())
+	})
+
+	t.Run("several private keys need a signing kid", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "old", rsaKey)
+		writePrivateKey(t, dir, "new", edKey)
+
+		_, err := LoadKeySet(dir, "")
+		assert.Error(t, err)
+
+		ks, err := LoadKeySet(dir, "new")
+		require.NoError(t, err)
+		assert.Equal(t, "new", ks.SigningKID())
+	})
+
+	t.Run("public keys cannot sign", func(t *testing.T) {
+		dir := t.TempDir()
+		writePublicKey(t, dir, "old", edKey.Public())
+
+		_, err := LoadKeySet(dir, "old")
+		assert.Error(t, err)
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		_, err := LoadKeySet(t.TempDir(), "")
+		assert.Error(t, err)
+	})
+
+	t.Run("weak RSA key", func(t *testing.T) {
+		weak, err := rsa.GenerateKey(rand.Reader, 1024)
+		require.NoError(t, err)
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "weak", weak)
+
+		_, err = LoadKeySet(dir, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyRotation(t *testing.T) {
+	_, oldKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	// Before rotation, only the old key exists
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "old", oldKey)
+	before, err := LoadKeySet(dir, "old")
+	require.NoError(t, err)
+
+	oldToken, err := NewService("", before, DefaultKeyLifetimes()).GenerateJWT("test@dlsu.edu.ph", DefaultScopes, time.Time{})
+	require.NoError(t, err)
+
+	// Rotate: sign with the new key and keep only the public half of the old one
+	writePrivateKey(t, dir, "new", newKey)
+	writePublicKey(t, dir, "old", oldKey.Public())
+	after, err := LoadKeySet(dir, "new")
+	require.NoError(t, err)
+
+	newToken, err := NewService("", after, DefaultKeyLifetimes()).GenerateJWT("test@dlsu.edu.ph", DefaultScopes, time.Time{})
+	require.NoError(t, err)
+
+	keyfunc := Keyfunc("", after)
+
+	t.Run("new tokens carry the new kid", func(t *testing.T) {
+		token, _, err := jwt.NewParser().ParseUnverified(newToken, new(JwtCustomClaims))
+		require.NoError(t, err)
+		assert.Equal(t, "new", token.Header["kid"])
+		assert.Equal(t, "RS256", token.Method.Alg())
+
+		claims, err := verify(newToken, keyfunc)
+		assert.NoError(t, err)
+		assert.Equal(t, "test@dlsu.edu.ph", claims.Email)
+	})
+
+	t.Run("old tokens verify until the old key is retired", func(t *testing.T) {
+		_, err := verify(oldToken, keyfunc)
+		assert.NoError(t, err)
+
+		require.NoError(t, os.Remove(filepath.Join(dir, "old.pem")))
+		retired, err := LoadKeySet(dir, "new")
+		require.NoError(t, err)
+
+		_, err = verify(oldToken, Keyfunc("", retired))
+		assert.Error(t, err)
+	})
+
+	t.Run("mismatched algorithm is rejected", func(t *testing.T) {
+		// An HS256 token signed with the RSA public key must not verify against it
+		der, err := x509.MarshalPKIXPublicKey(newKey.Public())
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &JwtCustomClaims{Email: "attacker@example.com"})
+		token.Header["kid"] = "new"
+		forged, err := token.SignedString(der)
+		require.NoError(t, err)
+
+		_, err = verify(forged, keyfunc)
+		assert.Error(t, err)
+	})
+}
+
+func TestKeyfuncLegacySecret(t *testing.T) {
+	tokenString, err := NewService("secret", nil, DefaultKeyLifetimes()).GenerateJWT("test@dlsu.edu.ph", DefaultScopes, time.Time{})
+	require.NoError(t, err)
+
+	_, err = verify(tokenString, Keyfunc("secret", nil))
+	assert.NoError(t, err)
+
+	// Once JWT_SECRET is unset, HS256 keys stop working
+	_, err = verify(tokenString, Keyfunc("", nil))
+	assert.Error(t, err)
+}
+
+func TestJWKSHandler(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "a", edKey)
+	writePublicKey(t, dir, "b", rsaKey.Public())
+	ks, err := LoadKeySet(dir, "a")
+	require.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, JWKSHandler(ks)(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var set JWKSet
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+		require.Len(t, set.Keys, 2)
+		assert.Equal(t, JWK{Kty: "OKP", Kid: "a", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey))}, set.Keys[0])
+		assert.Equal(t, "RSA", set.Keys[1].Kty)
+		assert.Equal(t, "AQAB", set.Keys[1].E)
+		assert.NotContains(t, rec.Body.String(), `"d"`)
+	}
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Scopes an API key can be granted. They are stored space-separated in api_keys.scopes.
+const (
+	ScopeMembersRead    = "members:read"
+	ScopeMembersPII     = "members:pii"
+	ScopeCommitteesRead = "committees:read"
+	ScopeEventsWrite    = "events:write"
+)
+
+// KnownScopes lists every scope that can be granted. Admin keys get all of them.
+var KnownScopes = []string{
+	ScopeMembersRead,
+	ScopeMembersPII,
+	ScopeCommitteesRead,
+	ScopeEventsWrite,
+}
+
+// DefaultScopes are granted when a key is requested without any scopes.
+var DefaultScopes = []string{
+	ScopeMembersRead,
+	ScopeCommitteesRead,
+}
+
+// ParseScopes splits a space-separated scopes column into its scopes.
+func ParseScopes(scopes string) []string {
+	return strings.Fields(scopes)
+}
+
+// FormatScopes joins scopes into the space-separated form stored in the database.
+func FormatScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
+
+// HasScope reports whether scopes contains scope.
+func HasScope(scopes []string, scope string) bool {
+	return slices.Contains(scopes, scope)
+}
+
+// ValidateScopes checks requested scopes against KnownScopes and removes duplicates.
+// An empty request yields DefaultScopes.
+func ValidateScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return slices.Clone(DefaultScopes), nil
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for _, scope := range requested {
+		if !HasScope(KnownScopes, scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		if !HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateScopes(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		scopes, err := ValidateScopes(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultScopes, scopes)
+	})
+
+	t.Run("deduplicates", func(t *testing.T) {
+		scopes, err := ValidateScopes([]string{ScopeMembersRead, ScopeMembersPII, ScopeMembersRead})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{ScopeMembersRead, ScopeMembersPII}, scopes)
+	})
+
+	t.Run("unknown scope", func(t *testing.T) {
+		_, err := ValidateScopes([]string{"members:write"})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes := ParseScopes(FormatScopes([]string{ScopeMembersRead, ScopeCommitteesRead}))
+	assert.Equal(t, []string{ScopeMembersRead, ScopeCommitteesRead}, scopes)
+	assert.True(t, HasScope(scopes, ScopeCommitteesRead))
+	assert.False(t, HasScope(scopes, ScopeMembersPII))
+	assert.Empty(t, ParseScopes(""))
+}
@@ -1,44 +1,160 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // JwtCustomClaims are custom claims extending default ones.
 type JwtCustomClaims struct {
-	Email string `json:"email"`
+	Email  string   `json:"email"`
+	Scopes []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// KeyClass is the kind of an API key, which decides how long it lives.
+type KeyClass string
+
+const (
+	KeyClassDev        KeyClass = "dev"
+	KeyClassProduction KeyClass = "production"
+	KeyClassAdmin      KeyClass = "admin"
+)
+
+// ClassOf returns the class of a key from its is_dev and is_admin flags.
+func ClassOf(isDev, isAdmin bool) KeyClass {
+	switch {
+	case isAdmin:
+		return KeyClassAdmin
+	case isDev:
+		return KeyClassDev
+	default:
+		return KeyClassProduction
+	}
+}
+
+// KeyLifetimes holds how long each class of API key stays valid.
+// A zero duration means keys of that class never expire.
+type KeyLifetimes struct {
+	Dev        time.Duration
+	Production time.Duration
+	Admin      time.Duration
+}
+
+// DefaultKeyLifetimes returns the lifetimes used when none are configured:
+// 30 days for dev keys, a year for production keys, and no expiry for admin keys.
+func DefaultKeyLifetimes() KeyLifetimes {
+	return KeyLifetimes{
+		Dev:        30 * 24 * time.Hour,
+		Production: 365 * 24 * time.Hour,
+		Admin:      0,
+	}
+}
+
+// KeyLifetimesFromEnv reads API_KEY_DEV_TTL, API_KEY_PRODUCTION_TTL and API_KEY_ADMIN_TTL
+// (Go durations such as "720h", or "0" for no expiry), falling back to DefaultKeyLifetimes.
+func KeyLifetimesFromEnv() KeyLifetimes {
+	l := DefaultKeyLifetimes()
+	l.Dev = durationFromEnv("API_KEY_DEV_TTL", l.Dev)
+	l.Production = durationFromEnv("API_KEY_PRODUCTION_TTL", l.Production)
+	l.Admin = durationFromEnv("API_KEY_ADMIN_TTL", l.Admin)
+	return l
+}
+
+// For returns the lifetime of the given key class.
+func (l KeyLifetimes) For(class KeyClass) time.Duration {
+	switch class {
+	case KeyClassAdmin:
+		return l.Admin
+	case KeyClassDev:
+		return l.Dev
+	default:
+		return l.Production
+	}
+}
+
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		slog.Warn("invalid duration in environment, using default", "name", name, "value", v, "default", fallback)
+		return fallback
+	}
+	return d
+}
+
 // Service is the interface for the auth service.
 // It can be used for mocking.
 type Service interface {
-	GenerateJWT(email string) (string, error)
+	GenerateJWT(email string, scopes []string, expiresAt time.Time) (string, error)
+	KeyExpiry(class KeyClass) time.Time
 }
 
 type service struct {
 	jwtSecret []byte
+	keys      *KeySet
+	lifetimes KeyLifetimes
 }
 
-// NewService creates a new auth service.
-func NewService(secret string) Service {
+// NewService creates a new auth service. Tokens are signed with the signing key of keys, or with
+// the HS256 secret if keys is nil.
+func NewService(secret string, keys *KeySet, lifetimes KeyLifetimes) Service {
 	return &service{
 		jwtSecret: []byte(secret),
+		keys:      keys,
+		lifetimes: lifetimes,
+	}
+}
+
+// KeyExpiry returns when a key of the given class issued now should expire,
+// or the zero time if keys of that class do not expire.
+func (s *service) KeyExpiry(class KeyClass) time.Time {
+	lifetime := s.lifetimes.For(class)
+	if lifetime == 0 {
+		return time.Time{}
 	}
+	// JWT exp and MySQL TIMESTAMP both have second precision
+	return time.Now().Add(lifetime).Truncate(time.Second)
 }
 
 // GenerateJWT generates a new JWT token and returns it as a string, as well as the error if has any.
-func (s *service) GenerateJWT(email string) (string, error) {
-	claims := &JwtCustomClaims{
-		Email:            email,
-		RegisteredClaims: jwt.RegisteredClaims{},
+// Every token carries a random ID so that two keys issued to the same member never share a hash.
+// A zero expiresAt produces a token without an exp claim.
+func (s *service) GenerateJWT(email string, scopes []string, expiresAt time.Time) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	claims := &JwtCustomClaims{
+		Email:  email,
+		Scopes: scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       hex.EncodeToString(id),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	if !expiresAt.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+	}
 
-	tokenString, err := token.SignedString(s.jwtSecret)
+	var tokenString string
+	var err error
+	if s.keys != nil {
+		tokenString, err = s.keys.Sign(claims)
+	} else {
+		tokenString, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.jwtSecret)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -46,3 +162,8 @@ func (s *service) GenerateJWT(email string) (string, error) {
 	return tokenString, nil
 }
 
+// HashAPIKey returns the hex-encoded SHA-256 hash of an API key, which is what gets stored in api_keys.
+func HashAPIKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
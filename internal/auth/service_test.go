@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassOf(t *testing.T) {
+	assert.Equal(t, KeyClassAdmin, ClassOf(false, true))
+	assert.Equal(t, KeyClassAdmin, ClassOf(true, true))
+	assert.Equal(t, KeyClassDev, ClassOf(true, false))
+	assert.Equal(t, KeyClassProduction, ClassOf(false, false))
+}
+
+func TestKeyLifetimesFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		assert.Equal(t, DefaultKeyLifetimes(), KeyLifetimesFromEnv())
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("API_KEY_DEV_TTL", "24h")
+		t.Setenv("API_KEY_PRODUCTION_TTL", "invalid")
+		t.Setenv("API_KEY_ADMIN_TTL", "8760h")
+
+		l := KeyLifetimesFromEnv()
+		assert.Equal(t, 24*time.Hour, l.Dev)
+		assert.Equal(t, DefaultKeyLifetimes().Production, l.Production)
+		assert.Equal(t, 8760*time.Hour, l.Admin)
+	})
+}
+
+func TestGenerateJWT(t *testing.T) {
+	s := NewService("secret", nil, DefaultKeyLifetimes())
+
+	parse := func(tokenString string) *JwtCustomClaims {
+		claims := new(JwtCustomClaims)
+		_, err := jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+			return []byte("secret"), nil
+		})
+		assert.NoError(t, err)
+		return claims
+	}
+
+	t.Run("dev key expires", func(t *testing.T) {
+		expiresAt := s.KeyExpiry(KeyClassDev)
+		assert.False(t, expiresAt.IsZero())
+
+		tokenString, err := s.GenerateJWT("test@dlsu.edu.ph", DefaultScopes, expiresAt)
+		assert.NoError(t, err)
+
+		claims := parse(tokenString)
+		assert.Equal(t, "test@dlsu.edu.ph", claims.Email)
+		assert.Equal(t, DefaultScopes, claims.Scopes)
+		if assert.NotNil(t, claims.ExpiresAt) {
+			assert.True(t, expiresAt.Equal(claims.ExpiresAt.Time))
+		}
+	})
+
+	t.Run("admin key does not expire", func(t *testing.T) {
+		expiresAt := s.KeyExpiry(KeyClassAdmin)
+		assert.True(t, expiresAt.IsZero())
+
+		tokenString, err := s.GenerateJWT("test@dlsu.edu.ph", DefaultScopes, expiresAt)
+		assert.NoError(t, err)
+		assert.Nil(t, parse(tokenString).ExpiresAt)
+	})
+
+	t.Run("tokens are unique", func(t *testing.T) {
+		a, err := s.GenerateJWT("test@dlsu.edu.ph", nil, time.Time{})
+		assert.NoError(t, err)
+		b, err := s.GenerateJWT("test@dlsu.edu.ph", nil, time.Time{})
+		assert.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	})
+}
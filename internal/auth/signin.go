@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// Codes of a denied sign-in, returned alongside the error message.
+const (
+	SignInEmailNotVerified = "email_not_verified"
+	SignInDomainNotAllowed = "domain_not_allowed"
+	SignInNotAMember       = "not_a_member"
+)
+
+// SignInError is a denied sign-in. Code is one of the SignIn* codes.
+type SignInError struct {
+	Code    string
+	Message string
+}
+
+func (e *SignInError) Error() string {
+	return e.Message
+}
+
+// SignInRules decide which verified Google accounts can sign in, and as which member.
+type SignInRules struct {
+	// RequireVerifiedEmail rejects accounts whose email Google has not verified.
+	RequireVerifiedEmail bool
+	// HostedDomains are the Google Workspace domains members sign in with. Accounts outside them,
+	// such as personal Gmail accounts, can only sign in through a member_emails entry. Empty
+	// allows any domain.
+	HostedDomains []string
+}
+
+// DefaultSignInRules only lets in verified dlsu.edu.ph accounts.
+func DefaultSignInRules() SignInRules {
+	return SignInRules{
+		RequireVerifiedEmail: true,
+		HostedDomains:        []string{DefaultHostedDomain},
+	}
+}
+
+// SignInRulesFromEnv reads SIGNIN_REQUIRE_VERIFIED_EMAIL (true or false) and
+// SIGNIN_HOSTED_DOMAINS (comma-separated, or empty to allow any domain), falling back to
+// DefaultSignInRules.
+func SignInRulesFromEnv() (SignInRules, error) {
+	r := DefaultSignInRules()
+	if v := os.Getenv("SIGNIN_REQUIRE_VERIFIED_EMAIL"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return r, fmt.Errorf("SIGNIN_REQUIRE_VERIFIED_EMAIL: %w", err)
+		}
+		r.RequireVerifiedEmail = b
+	}
+	if v, ok := os.LookupEnv("SIGNIN_HOSTED_DOMAINS"); ok {
+		r.HostedDomains = nil
+		for _, d := range strings.Split(v, ",") {
+			if d = strings.ToLower(strings.TrimSpace(d)); d != "" {
+				r.HostedDomains = append(r.HostedDomains, d)
+			}
+		}
+	}
+	return r, nil
+}
+
+// allowsDomain reports whether an account of the given hosted domain can sign in on its own.
+func (r SignInRules) allowsDomain(hostedDomain string) bool {
+	return len(r.HostedDomains) == 0 || slices.Contains(r.HostedDomains, strings.ToLower(hostedDomain))
+}
+
+// MemberEmail applies the rules to a verified identity and returns the primary email of the
+// member it signs in as. The account's own email is tried first, then member_emails. Denied
+// sign-ins are returned as a *SignInError.
+func (r SignInRules) MemberEmail(ctx context.Context, q *repository.Queries, id Identity) (string, error) {
+	if r.RequireVerifiedEmail && !id.EmailVerified {
+		return "", &SignInError{Code: SignInEmailNotVerified, Message: "Google account email is not verified"}
+	}
+
+	if r.allowsDomain(id.HostedDomain) {
+		email, err := q.CheckEmailIfMember(ctx, id.Email)
+		if err == nil {
+			return email, nil
+		}
+		if err != sql.ErrNoRows {
+			return "", err
+		}
+	}
+
+	email, err := q.GetMemberEmailBySecondaryEmail(ctx, id.Email)
+	if err == nil {
+		return email, nil
+	}
+	if err != sql.ErrNoRows {
+		return "", err
+	}
+
+	if !r.allowsDomain(id.HostedDomain) {
+		return "", &SignInError{Code: SignInDomainNotAllowed, Message: "Sign in with your DLSU Google account"}
+	}
+	return "", &SignInError{Code: SignInNotAMember, Message: "Not an LSCS member"}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignInRules(t *testing.T) {
+	rules := DefaultSignInRules()
+
+	run := func(t *testing.T, rules SignInRules, id Identity, expect func(sqlmock.Sqlmock)) (string, error) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		if expect != nil {
+			expect(mock)
+		}
+
+		email, err := rules.MemberEmail(context.Background(), repository.New(db), id)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		return email, err
+	}
+
+	code := func(err error) string {
+		se, ok := err.(*SignInError)
+		if !ok {
+			return ""
+		}
+		return se.Code
+	}
+
+	t.Run("member with an institutional account", func(t *testing.T) {
+		email, err := run(t, rules, Identity{Email: "test@dlsu.edu.ph", EmailVerified: true, HostedDomain: "dlsu.edu.ph"}, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+				WithArgs("test@dlsu.edu.ph").
+				WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("test@dlsu.edu.ph"))
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "test@dlsu.edu.ph", email)
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		_, err := run(t, rules, Identity{Email: "test@dlsu.edu.ph", HostedDomain: "dlsu.edu.ph"}, nil)
+		assert.Equal(t, SignInEmailNotVerified, code(err))
+	})
+
+	t.Run("unverified email allowed", func(t *testing.T) {
+		lax := rules
+		lax.RequireVerifiedEmail = false
+		_, err := run(t, lax, Identity{Email: "test@dlsu.edu.ph", HostedDomain: "dlsu.edu.ph"}, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+				WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("test@dlsu.edu.ph"))
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("personal account mapped to a member", func(t *testing.T) {
+		email, err := run(t, rules, Identity{Email: "juan@gmail.com", EmailVerified: true}, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT m.email FROM member_emails e").
+				WithArgs("juan@gmail.com").
+				WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("juan_delacruz@dlsu.edu.ph"))
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "juan_delacruz@dlsu.edu.ph", email)
+	})
+
+	t.Run("personal account", func(t *testing.T) {
+		_, err := run(t, rules, Identity{Email: "juan@gmail.com", EmailVerified: true}, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT m.email FROM member_emails e").
+				WithArgs("juan@gmail.com").
+				WillReturnError(sql.ErrNoRows)
+		})
+		assert.Equal(t, SignInDomainNotAllowed, code(err))
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		_, err := run(t, rules, Identity{Email: "outsider@dlsu.edu.ph", EmailVerified: true, HostedDomain: "dlsu.edu.ph"}, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+				WithArgs("outsider@dlsu.edu.ph").
+				WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery("SELECT m.email FROM member_emails e").
+				WithArgs("outsider@dlsu.edu.ph").
+				WillReturnError(sql.ErrNoRows)
+		})
+		assert.Equal(t, SignInNotAMember, code(err))
+	})
+}
+
+func TestSignInRulesFromEnv(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		rules, err := SignInRulesFromEnv()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultSignInRules(), rules)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		t.Setenv("SIGNIN_REQUIRE_VERIFIED_EMAIL", "false")
+		t.Setenv("SIGNIN_HOSTED_DOMAINS", "dlsu.edu.ph, DLSU.ph")
+		rules, err := SignInRulesFromEnv()
+		require.NoError(t, err)
+		assert.False(t, rules.RequireVerifiedEmail)
+		assert.Equal(t, []string{"dlsu.edu.ph", "dlsu.ph"}, rules.HostedDomains)
+	})
+
+	t.Run("any domain", func(t *testing.T) {
+		t.Setenv("SIGNIN_HOSTED_DOMAINS", "")
+		rules, err := SignInRulesFromEnv()
+		require.NoError(t, err)
+		assert.True(t, rules.allowsDomain(""))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Setenv("SIGNIN_REQUIRE_VERIFIED_EMAIL", "maybe")
+		_, err := SignInRulesFromEnv()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,371 @@
+package committee
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
+	"github.com/labstack/echo/v4"
+)
+
+// orgName labels the root of the org chart diagrams.
+const orgName = "LSCS"
+
+// OrgMember is a member in the org chart, as a head or in a committee's member list.
+type OrgMember struct {
+	ID           int32                  `json:"id"`
+	FullName     string                 `json:"full_name"`
+	Nickname     helpers.NullableString `json:"nickname"`
+	PositionID   helpers.NullableString `json:"position_id"`
+	PositionName helpers.NullableString `json:"position_name"`
+}
+
+// OrgCommittee is a committee with its head and active members.
+type OrgCommittee struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Head        *OrgMember  `json:"head"`
+	MemberCount int64       `json:"member_count"`
+	Members     []OrgMember `json:"members,omitzero"`
+}
+
+// OrgDivision is a division with its head and committees. Its member count is that of its committees.
+type OrgDivision struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Head        *OrgMember     `json:"head"`
+	MemberCount int64          `json:"member_count"`
+	Committees  []OrgCommittee `json:"committees"`
+}
+
+// OrgResponse is the org chart. Committees without a division and active members without a
+// committee are listed on their own.
+type OrgResponse struct {
+	Divisions       []OrgDivision  `json:"divisions"`
+	Committees      []OrgCommittee `json:"committees"`
+	UnassignedCount int64          `json:"unassigned_count"`
+	Unassigned      []OrgMember    `json:"unassigned,omitzero"`
+
+	// past is set for the chart of a past term, whose heads are not recorded.
+	past bool
+}
+
+// orgData is what the org chart is built from.
+type orgData struct {
+	divisions  []repository.Division
+	committees []repository.Committee
+	heads      []repository.ListOrgHeadsRow
+	counts     []repository.CountMembersByCommitteeRow
+	members    []repository.ListOrgMembersRow
+}
+
+func loadOrg(ctx context.Context, q *repository.Queries, withMembers bool) (orgData, error) {
+	var d orgData
+	var err error
+	if d.divisions, err = q.GetAllDivisions(ctx); err != nil {
+		return d, err
+	}
+	if d.committees, err = q.GetAllCommittees(ctx); err != nil {
+		return d, err
+	}
+	if d.heads, err = q.ListOrgHeads(ctx); err != nil {
+		return d, err
+	}
+	if d.counts, err = q.CountMembersByCommittee(ctx); err != nil {
+		return d, err
+	}
+	if withMembers {
+		if d.members, err = q.ListOrgMembers(ctx); err != nil {
+			return d, err
+		}
+	}
+	return d, nil
+}
+
+// loadTermOrg is loadOrg for a past term: the divisions and committees as they are now, with the
+// members recorded for the term. Heads are not recorded per term, so none are loaded.
+func loadTermOrg(ctx context.Context, q *repository.Queries, termID int32, withMembers bool) (orgData, error) {
+	var d orgData
+	var err error
+	if d.divisions, err = q.GetAllDivisions(ctx); err != nil {
+		return d, err
+	}
+	if d.committees, err = q.GetAllCommittees(ctx); err != nil {
+		return d, err
+	}
+	counts, err := q.CountTermMembersByCommittee(ctx, termID)
+	if err != nil {
+		return d, err
+	}
+	for _, c := range counts {
+		d.counts = append(d.counts, repository.CountMembersByCommitteeRow(c))
+	}
+	if withMembers {
+		members, err := q.ListTermOrgMembers(ctx, termID)
+		if err != nil {
+			return d, err
+		}
+		d.members = make([]repository.ListOrgMembersRow, 0, len(members))
+		for _, m := range members {
+			d.members = append(d.members, repository.ListOrgMembersRow(m))
+		}
+	}
+	return d, nil
+}
+
+// buildOrg nests the committees under their divisions, sorted by name. Member lists are only
+// filled in when withMembers is set.
+func buildOrg(d orgData, withMembers bool) OrgResponse {
+	heads := make(map[int32]*OrgMember, len(d.heads))
+	for _, h := range d.heads {
+		heads[h.ID] = &OrgMember{
+			ID:           h.ID,
+			FullName:     h.FullName,
+			Nickname:     helpers.NullableString{NullString: h.Nickname},
+			PositionID:   helpers.NullableString{NullString: h.PositionID},
+			PositionName: helpers.NullableString{NullString: h.PositionName},
+		}
+	}
+
+	counts := make(map[string]int64, len(d.counts))
+	var unassigned int64
+	for _, c := range d.counts {
+		if c.CommitteeID.Valid {
+			counts[c.CommitteeID.String] = c.MemberCount
+		} else {
+			unassigned = c.MemberCount
+		}
+	}
+
+	members := make(map[string][]OrgMember)
+	for _, m := range d.members {
+		members[m.CommitteeID.String] = append(members[m.CommitteeID.String], OrgMember{
+			ID:           m.ID,
+			FullName:     m.FullName,
+			Nickname:     helpers.NullableString{NullString: m.Nickname},
+			PositionID:   helpers.NullableString{NullString: m.PositionID},
+			PositionName: helpers.NullableString{NullString: m.PositionName},
+		})
+	}
+	memberList := func(committeeID string) []OrgMember {
+		if !withMembers {
+			return nil
+		}
+		if list := members[committeeID]; list != nil {
+			return list
+		}
+		return []OrgMember{}
+	}
+
+	divisions := make([]OrgDivision, 0, len(d.divisions))
+	index := make(map[string]int, len(d.divisions))
+	for _, div := range d.divisions {
+		o := OrgDivision{ID: div.DivisionID, Name: div.DivisionName, Committees: []OrgCommittee{}}
+		if div.DivisionHead.Valid {
+			o.Head = heads[div.DivisionHead.Int32]
+		}
+		divisions = append(divisions, o)
+	}
+	sort.Slice(divisions, func(i, j int) bool { return divisions[i].Name < divisions[j].Name })
+	for i, div := range divisions {
+		index[div.ID] = i
+	}
+
+	committees := append([]repository.Committee(nil), d.committees...)
+	sort.Slice(committees, func(i, j int) bool { return committees[i].CommitteeName < committees[j].CommitteeName })
+
+	org := OrgResponse{
+		Divisions:       divisions,
+		Committees:      []OrgCommittee{},
+		UnassignedCount: unassigned,
+		Unassigned:      memberList(""),
+	}
+	for _, c := range committees {
+		o := OrgCommittee{
+			ID:          c.CommitteeID,
+			Name:        c.CommitteeName,
+			MemberCount: counts[c.CommitteeID],
+			Members:     memberList(c.CommitteeID),
+		}
+		if c.CommitteeHead.Valid {
+			o.Head = heads[c.CommitteeHead.Int32]
+		}
+		if i, ok := index[c.DivisionID.String]; ok {
+			org.Divisions[i].Committees = append(org.Divisions[i].Committees, o)
+			org.Divisions[i].MemberCount += o.MemberCount
+		} else {
+			org.Committees = append(org.Committees, o)
+		}
+	}
+	return org
+}
+
+// OrgHandler returns the org chart: divisions with their heads, the committees under each division
+// with their heads, and member counts. include=members adds the active members of each committee,
+// which needs the members:read scope. format=mermaid or format=dot returns the chart as a Mermaid
+// flowchart or a Graphviz graph instead of JSON. ?term= charts the members recorded for a past term.
+func (h *Handler) OrgHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format != "" && format != "json" && format != "mermaid" && format != "dot" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be json, mermaid or dot"})
+	}
+
+	withMembers := false
+	switch c.QueryParam("include") {
+	case "":
+	case "members":
+		if !middlewares.CallerHasScope(c, auth.ScopeMembersRead) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "include=members needs the members:read scope"})
+		}
+		withMembers = true
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "include must be members"})
+	}
+
+	t, past, status, msg := term.PastFromQuery(c, q)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	var d orgData
+	var err error
+	if past {
+		d, err = loadTermOrg(ctx, q, t.ID, withMembers)
+	} else {
+		d, err = loadOrg(ctx, q, withMembers)
+	}
+	if err != nil {
+		slog.Error("failed to load org chart", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	org := buildOrg(d, withMembers)
+	org.past = past
+
+	switch format {
+	case "mermaid":
+		return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, []byte(orgMermaid(org)))
+	case "dot":
+		return c.Blob(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(orgDot(org)))
+	}
+	return c.JSON(http.StatusOK, org)
+}
+
+// orgNode is a box in an org chart diagram, with the lines of its label.
+type orgNode struct {
+	id     string
+	parent string
+	label  []string
+}
+
+var nodeIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func nodeID(kind, id string) string {
+	return kind + "_" + nodeIDUnsafe.ReplaceAllString(id, "_")
+}
+
+// unitLabel is the label of a division or committee box. Past terms have no head line, as their
+// heads are not recorded.
+func unitLabel(org OrgResponse, name string, head *OrgMember, count int64) []string {
+	if org.past {
+		return []string{name, countLine(count)}
+	}
+	if head == nil {
+		return []string{name, "Head: (vacant)", countLine(count)}
+	}
+	return []string{name, "Head: " + head.FullName, countLine(count)}
+}
+
+func countLine(n int64) string {
+	if n == 1 {
+		return "1 member"
+	}
+	return fmt.Sprintf("%d members", n)
+}
+
+func memberLabel(m OrgMember) []string {
+	if m.PositionName.Valid {
+		return []string{m.FullName, m.PositionName.String}
+	}
+	return []string{m.FullName}
+}
+
+// orgNodes lists the boxes of the org chart, each after its parent.
+func orgNodes(org OrgResponse) []orgNode {
+	root := "org"
+	nodes := []orgNode{{id: root, label: []string{orgName}}}
+	addCommittee := func(parent string, c OrgCommittee) {
+		id := nodeID("committee", c.ID)
+		nodes = append(nodes, orgNode{id: id, parent: parent, label: unitLabel(org, c.Name, c.Head, c.MemberCount)})
+		for _, m := range c.Members {
+			nodes = append(nodes, orgNode{id: nodeID("member", fmt.Sprint(m.ID)), parent: id, label: memberLabel(m)})
+		}
+	}
+	for _, div := range org.Divisions {
+		id := nodeID("division", div.ID)
+		nodes = append(nodes, orgNode{id: id, parent: root, label: unitLabel(org, div.Name, div.Head, div.MemberCount)})
+		for _, c := range div.Committees {
+			addCommittee(id, c)
+		}
+	}
+	for _, c := range org.Committees {
+		addCommittee(root, c)
+	}
+	if org.UnassignedCount > 0 {
+		nodes = append(nodes, orgNode{id: "unassigned", parent: root, label: []string{"No committee", countLine(org.UnassignedCount)}})
+		for _, m := range org.Unassigned {
+			nodes = append(nodes, orgNode{id: nodeID("member", fmt.Sprint(m.ID)), parent: "unassigned", label: memberLabel(m)})
+		}
+	}
+	return nodes
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// orgMermaid writes the org chart as a Mermaid flowchart.
+func orgMermaid(org OrgResponse) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, n := range orgNodes(org) {
+		lines := make([]string, len(n.label))
+		for i, l := range n.label {
+			lines[i] = mermaidEscaper.Replace(l)
+		}
+		fmt.Fprintf(&b, "    %s[\"%s\"]\n", n.id, strings.Join(lines, "<br/>"))
+		if n.parent != "" {
+			fmt.Fprintf(&b, "    %s --> %s\n", n.parent, n.id)
+		}
+	}
+	return b.String()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+
+// orgDot writes the org chart as a Graphviz graph.
+func orgDot(org OrgResponse) string {
+	var b strings.Builder
+	b.WriteString("digraph org {\n    rankdir=TB;\n    node [shape=box, style=rounded];\n")
+	for _, n := range orgNodes(org) {
+		lines := make([]string, len(n.label))
+		for i, l := range n.label {
+			lines[i] = dotEscaper.Replace(l)
+		}
+		fmt.Fprintf(&b, "    %s [label=\"%s\"];\n", n.id, strings.Join(lines, `\n`))
+		if n.parent != "" {
+			fmt.Fprintf(&b, "    %s -> %s;\n", n.parent, n.id)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
@@ -0,0 +1,273 @@
+package committee
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newOrgRequest(target, scopes string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middlewares.APIKeyContextKey, repository.ApiKey{ApiKeyID: 7, MemberEmail: "test@dlsu.edu.ph", Scopes: scopes})
+	return c, rec
+}
+
+func expectOrg(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT (.+) FROM divisions d").
+		WillReturnRows(sqlmock.NewRows([]string{"division_id", "division_name", "division_head"}).
+			AddRow("INT", "Internals", 1))
+	mock.ExpectQuery("SELECT (.+) FROM committees c").
+		WillReturnRows(sqlmock.NewRows([]string{"committee_id", "committee_name", "committee_head", "division_id"}).
+			AddRow("RND", "Research and Development", 2, "INT").
+			AddRow("HRD", "Human Resource Development", nil, "INT").
+			AddRow("EXEC", "Executive Board", nil, nil))
+	mock.ExpectQuery("SELECT m.id, m.full_name, m.nickname, m.position_id, p.position_name").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "nickname", "position_id", "position_name"}).
+			AddRow(1, "Maria Santos", nil, "VP", "Vice President").
+			AddRow(2, "Juan Dela Cruz", "Juan", "AVP", "Associate Vice President"))
+	mock.ExpectQuery("SELECT committee_id, COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"committee_id", "member_count"}).
+			AddRow("RND", 12).
+			AddRow("HRD", 3).
+			AddRow(nil, 2))
+}
+
+func TestOrgHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectOrg(mock)
+
+		c, rec := newOrgRequest("/org", "committees:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{
+				"divisions": [
+					{
+						"id": "INT", "name": "Internals", "member_count": 15,
+						"head": {"id": 1, "full_name": "Maria Santos", "nickname": "", "position_id": "VP", "position_name": "Vice President"},
+						"committees": [
+							{"id": "HRD", "name": "Human Resource Development", "head": null, "member_count": 3},
+							{
+								"id": "RND", "name": "Research and Development", "member_count": 12,
+								"head": {"id": 2, "full_name": "Juan Dela Cruz", "nickname": "Juan", "position_id": "AVP", "position_name": "Associate Vice President"}
+							}
+						]
+					}
+				],
+				"committees": [
+					{"id": "EXEC", "name": "Executive Board", "head": null, "member_count": 0}
+				],
+				"unassigned_count": 2
+			}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - deactivated head", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		// RND still names member 2 as its head, but only active heads are loaded
+		mock.ExpectQuery("SELECT (.+) FROM divisions d").
+			WillReturnRows(sqlmock.NewRows([]string{"division_id", "division_name", "division_head"}).
+				AddRow("INT", "Internals", 1))
+		mock.ExpectQuery("SELECT (.+) FROM committees c").
+			WillReturnRows(sqlmock.NewRows([]string{"committee_id", "committee_name", "committee_head", "division_id"}).
+				AddRow("RND", "Research and Development", 2, "INT"))
+		mock.ExpectQuery("SELECT m.id, m.full_name, m.nickname, m.position_id, p.position_name (.+) WHERE m.status = 'ACTIVE'").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "nickname", "position_id", "position_name"}).
+				AddRow(1, "Maria Santos", nil, "VP", "Vice President"))
+		mock.ExpectQuery("SELECT committee_id, COUNT").
+			WillReturnRows(sqlmock.NewRows([]string{"committee_id", "member_count"}).
+				AddRow("RND", 11))
+
+		c, rec := newOrgRequest("/org", "committees:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `{"id":"RND","name":"Research and Development","head":null,"member_count":11}`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - with members", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectOrg(mock)
+		mock.ExpectQuery("SELECT m.id, m.full_name, m.nickname, m.committee_id").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "nickname", "committee_id", "position_id", "position_name"}).
+				AddRow(2, "Juan Dela Cruz", "Juan", "RND", "AVP", "Associate Vice President").
+				AddRow(3, "Pedro Penduko", nil, nil, "MEM", "Member"))
+
+		c, rec := newOrgRequest("/org?include=members", "committees:read members:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"members":[{"id":2,"full_name":"Juan Dela Cruz"`)
+			assert.Contains(t, rec.Body.String(), `"members":[]`)
+			assert.Contains(t, rec.Body.String(), `"unassigned":[{"id":3,"full_name":"Pedro Penduko"`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - mermaid", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectOrg(mock)
+
+		c, rec := newOrgRequest("/org?format=mermaid", "committees:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, `flowchart TD
+    org["LSCS"]
+    division_INT["Internals<br/>Head: Maria Santos<br/>15 members"]
+    org --> division_INT
+    committee_HRD["Human Resource Development<br/>Head: (vacant)<br/>3 members"]
+    division_INT --> committee_HRD
+    committee_RND["Research and Development<br/>Head: Juan Dela Cruz<br/>12 members"]
+    division_INT --> committee_RND
+    committee_EXEC["Executive Board<br/>Head: (vacant)<br/>0 members"]
+    org --> committee_EXEC
+    unassigned["No committee<br/>2 members"]
+    org --> unassigned
+`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - dot", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectOrg(mock)
+
+		c, rec := newOrgRequest("/org?format=dot", "committees:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/vnd.graphviz")
+			assert.Contains(t, rec.Body.String(), `division_INT [label="Internals\nHead: Maria Santos\n15 members"];`)
+			assert.Contains(t, rec.Body.String(), "division_INT -> committee_RND;")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - past term", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		termColumns := []string{"id", "term", "start_year", "end_year", "starts_on", "ends_on"}
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(12, 3, 2025, 2026, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms WHERE term_id").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(85))
+		mock.ExpectQuery("SELECT (.+) FROM divisions d").
+			WillReturnRows(sqlmock.NewRows([]string{"division_id", "division_name", "division_head"}).
+				AddRow("INT", "Internals", 1))
+		mock.ExpectQuery("SELECT (.+) FROM committees c").
+			WillReturnRows(sqlmock.NewRows([]string{"committee_id", "committee_name", "committee_head", "division_id"}).
+				AddRow("RND", "Research and Development", 2, "INT"))
+		mock.ExpectQuery("SELECT committee_id, COUNT(.+) FROM member_terms").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"committee_id", "member_count"}).
+				AddRow("RND", 1))
+		mock.ExpectQuery("SELECT (.+) FROM member_terms mt").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "full_name", "nickname", "committee_id", "position_id", "position_name"}).
+				AddRow(3, "Pedro Penduko", nil, "RND", "CT", "Committee Trainee"))
+
+		c, rec := newOrgRequest("/org?term=12&include=members", "committees:read members:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{
+				"divisions": [
+					{
+						"id": "INT", "name": "Internals", "head": null, "member_count": 1,
+						"committees": [
+							{
+								"id": "RND", "name": "Research and Development", "head": null, "member_count": 1,
+								"members": [{"id": 3, "full_name": "Pedro Penduko", "nickname": "", "position_id": "CT", "position_name": "Committee Trainee"}]
+							}
+						]
+					}
+				],
+				"committees": [],
+				"unassigned_count": 0,
+				"unassigned": []
+			}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("success - past term mermaid", func(t *testing.T) {
+		org := OrgResponse{Committees: []OrgCommittee{{ID: "RND", Name: "Research and Development", MemberCount: 1}}, past: true}
+		assert.Contains(t, orgMermaid(org), `committee_RND["Research and Development<br/>1 member"]`)
+	})
+
+	t.Run("fail - unknown term", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(99).WillReturnError(sql.ErrNoRows)
+
+		c, rec := newOrgRequest("/org?term=99", "committees:read")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - members without members:read", func(t *testing.T) {
+		c, rec := newOrgRequest("/org?include=members", "committees:read")
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("fail - unknown format", func(t *testing.T) {
+		c, rec := newOrgRequest("/org?format=svg", "committees:read")
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.OrgHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestOrgMermaidEscapesLabels(t *testing.T) {
+	org := OrgResponse{Committees: []OrgCommittee{{ID: "R&D", Name: `"Research" <Dev>`}}}
+	assert.Contains(t, orgMermaid(org), `committee_R_D["#quot;Research#quot; #lt;Dev#gt;<br/>Head: (vacant)<br/>0 members"]`)
+	assert.Contains(t, orgDot(org), `committee_R_D [label="\"Research\" <Dev>\nHead: (vacant)\n0 members"];`)
+}
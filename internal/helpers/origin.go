@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"net/url"
+	"strings"
+)
+
+// NormalizeOrigin reduces a URL or origin to its "scheme://host[:port]" form, which is how browsers
+// send the Origin header. It returns an empty string if raw is not an absolute http(s) URL.
+func NormalizeOrigin(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
+
+// IsLocalhostOrigin reports whether origin points to http://localhost on any port.
+func IsLocalhostOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == "http" && u.Hostname() == "localhost"
+}
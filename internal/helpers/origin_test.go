@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeOrigin(t *testing.T) {
+	t.Run("origin", func(t *testing.T) {
+		assert.Equal(t, "https://links.app.dlsu-lscs.org", NormalizeOrigin("https://links.app.dlsu-lscs.org"))
+	})
+
+	t.Run("full url", func(t *testing.T) {
+		assert.Equal(t, "https://links.app.dlsu-lscs.org", NormalizeOrigin("https://Links.app.dlsu-lscs.org/some/page?q=1"))
+	})
+
+	t.Run("with port", func(t *testing.T) {
+		assert.Equal(t, "http://localhost:3000", NormalizeOrigin("http://localhost:3000/"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		assert.Equal(t, "", NormalizeOrigin("links.app.dlsu-lscs.org"))
+		assert.Equal(t, "", NormalizeOrigin("ftp://links.app.dlsu-lscs.org"))
+	})
+}
+
+func TestIsLocalhostOrigin(t *testing.T) {
+	t.Run("localhost", func(t *testing.T) {
+		assert.True(t, IsLocalhostOrigin("http://localhost"))
+		assert.True(t, IsLocalhostOrigin("http://localhost:5173"))
+	})
+
+	t.Run("not localhost", func(t *testing.T) {
+		assert.False(t, IsLocalhostOrigin("https://localhost"))
+		assert.False(t, IsLocalhostOrigin("http://localhost.evil.com"))
+		assert.False(t, IsLocalhostOrigin("https://oms.app.dlsu-lscs.org"))
+	})
+}
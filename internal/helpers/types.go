@@ -3,6 +3,7 @@ package helpers
 import (
 	"database/sql"
 	"encoding/json"
+	"time"
 )
 
 // NullableString is a wrapper for sql.NullString to handle JSON marshaling.
@@ -16,4 +17,30 @@ func (ns NullableString) MarshalJSON() ([]byte, error) {
 		return json.Marshal(ns.String)
 	}
 	return json.Marshal("")
-}
\ No newline at end of file
+}
+
+// NullableTime is a wrapper for sql.NullTime to handle JSON marshaling.
+type NullableTime struct {
+	sql.NullTime
+}
+
+// MarshalJSON implements the json.Marshaler interface to marshal NullableTime as null if not valid.
+func (nt NullableTime) MarshalJSON() ([]byte, error) {
+	if nt.Valid {
+		return json.Marshal(nt.Time)
+	}
+	return json.Marshal(nil)
+}
+
+// NullableDate is a wrapper for sql.NullTime holding a DATE column.
+type NullableDate struct {
+	sql.NullTime
+}
+
+// MarshalJSON implements the json.Marshaler interface to marshal NullableDate as "2006-01-02", or null if not valid.
+func (nd NullableDate) MarshalJSON() ([]byte, error) {
+	if nd.Valid {
+		return json.Marshal(nd.Time.Format(time.DateOnly))
+	}
+	return json.Marshal(nil)
+}
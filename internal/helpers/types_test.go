@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +24,35 @@ func TestNullableString_MarshalJSON(t *testing.T) {
 		assert.Equal(t, `""`, string(b))
 	})
 }
+
+func TestNullableTime_MarshalJSON(t *testing.T) {
+	t.Run("valid time", func(t *testing.T) {
+		nt := NullableTime{sql.NullTime{Time: time.Date(2026, time.October, 18, 8, 0, 0, 0, time.UTC), Valid: true}}
+		b, err := json.Marshal(nt)
+		assert.NoError(t, err)
+		assert.Equal(t, `"2026-10-18T08:00:00Z"`, string(b))
+	})
+
+	t.Run("invalid time", func(t *testing.T) {
+		nt := NullableTime{sql.NullTime{Valid: false}}
+		b, err := json.Marshal(nt)
+		assert.NoError(t, err)
+		assert.Equal(t, `null`, string(b))
+	})
+}
+
+func TestNullableDate_MarshalJSON(t *testing.T) {
+	t.Run("valid date", func(t *testing.T) {
+		nd := NullableDate{sql.NullTime{Time: time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC), Valid: true}}
+		b, err := json.Marshal(nd)
+		assert.NoError(t, err)
+		assert.Equal(t, `"2026-06-01"`, string(b))
+	})
+
+	t.Run("invalid date", func(t *testing.T) {
+		nd := NullableDate{sql.NullTime{Valid: false}}
+		b, err := json.Marshal(nd)
+		assert.NoError(t, err)
+		assert.Equal(t, `null`, string(b))
+	})
+}
@@ -74,7 +74,7 @@ type MemberResponse struct {
 	HouseName     helpers.NullableString `json:"house_name"`
 }
 
-func toMemberResponse(m repository.ListMembersRow) MemberResponse {
+func toMemberResponse(m repository.SearchMembersRow) MemberResponse {
 	return MemberResponse{
 		ID:            m.ID,
 		FullName:      m.FullName,
@@ -0,0 +1,377 @@
+package member
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
+	"github.com/labstack/echo/v4"
+)
+
+// exportField is a column of a member export. PII fields are only exported for keys with the
+// members:pii scope.
+type exportField struct {
+	name  string
+	pii   bool
+	value func(m repository.ExportMembersRow) string
+}
+
+// exportFields are the fields that can be exported, in their default order.
+var exportFields = []exportField{
+	{"id", false, func(m repository.ExportMembersRow) string { return strconv.Itoa(int(m.ID)) }},
+	{"full_name", false, func(m repository.ExportMembersRow) string { return m.FullName }},
+	{"nickname", false, func(m repository.ExportMembersRow) string { return m.Nickname.String }},
+	{"committee_id", false, func(m repository.ExportMembersRow) string { return m.CommitteeID.String }},
+	{"committee_name", false, func(m repository.ExportMembersRow) string { return m.CommitteeName.String }},
+	{"division_id", false, func(m repository.ExportMembersRow) string { return m.DivisionID.String }},
+	{"division_name", false, func(m repository.ExportMembersRow) string { return m.DivisionName.String }},
+	{"position_id", false, func(m repository.ExportMembersRow) string { return m.PositionID.String }},
+	{"position_name", false, func(m repository.ExportMembersRow) string { return m.PositionName.String }},
+	{"house_name", false, func(m repository.ExportMembersRow) string { return m.HouseName.String }},
+	{"email", true, func(m repository.ExportMembersRow) string { return m.Email }},
+	{"contact_number", true, func(m repository.ExportMembersRow) string { return m.ContactNumber.String }},
+	{"telegram", true, func(m repository.ExportMembersRow) string { return m.Telegram.String }},
+	{"discord", true, func(m repository.ExportMembersRow) string { return m.Discord.String }},
+	{"fb_link", true, func(m repository.ExportMembersRow) string { return m.FbLink.String }},
+	{"college", true, func(m repository.ExportMembersRow) string { return m.College.String }},
+	{"program", true, func(m repository.ExportMembersRow) string { return m.Program.String }},
+	{"interests", true, func(m repository.ExportMembersRow) string { return m.Interests.String }},
+}
+
+// exportFormats maps each export format to its content type and file extension.
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"csv":  {"text/csv; charset=utf-8", "csv"},
+	"xlsx": {"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+	"vcf":  {"text/vcard; charset=utf-8", "vcf"},
+}
+
+// selectExportFields returns the fields named in the comma-separated list, or every field the key
+// may export if the list is empty. Asking for a PII field without the scope is an error.
+func selectExportFields(list string, allowPII bool) ([]exportField, int, error) {
+	if strings.TrimSpace(list) == "" {
+		var fields []exportField
+		for _, f := range exportFields {
+			if !f.pii || allowPII {
+				fields = append(fields, f)
+			}
+		}
+		return fields, 0, nil
+	}
+
+	var fields []exportField
+	seen := map[string]bool{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		i := -1
+		for j, f := range exportFields {
+			if f.name == name {
+				i = j
+				break
+			}
+		}
+		if i < 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("unknown field %q", name)
+		}
+		if exportFields[i].pii && !allowPII {
+			return nil, http.StatusForbidden, fmt.Errorf("exporting %s requires the %s scope", name, auth.ScopeMembersPII)
+		}
+		fields = append(fields, exportFields[i])
+	}
+	return fields, 0, nil
+}
+
+// nullIfEmpty turns an unset query parameter into a NULL query argument.
+func nullIfEmpty(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// exportTermMembers is ExportMembers over the roster recorded for a past term, with the committee,
+// position and house each member had then.
+func exportTermMembers(ctx context.Context, q *repository.Queries, termID int32, filters repository.ExportMembersParams) ([]repository.ExportMembersRow, error) {
+	rows, err := q.ExportTermMembers(ctx, repository.ExportTermMembersParams{
+		TermID:      termID,
+		CommitteeID: filters.CommitteeID,
+		DivisionID:  filters.DivisionID,
+		House:       filters.House,
+	})
+	if err != nil {
+		return nil, err
+	}
+	members := make([]repository.ExportMembersRow, len(rows))
+	for i, r := range rows {
+		members[i] = repository.ExportMembersRow(r)
+	}
+	return members, nil
+}
+
+// ExportMembersHandler returns the active members as a CSV, XLSX or vCard file. Members can be
+// filtered by committee, division and house, and the columns chosen with ?fields=. ?term= exports
+// the members recorded for a past term instead.
+func (h *Handler) ExportMembersHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = "csv"
+	}
+	out, ok := exportFormats[format]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "format must be csv, xlsx or vcf"})
+	}
+
+	key, ok := middlewares.APIKeyFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+	}
+	fields, status, err := selectExportFields(c.QueryParam("fields"), middlewares.KeyHasScope(key, auth.ScopeMembersPII))
+	if err != nil {
+		return c.JSON(status, map[string]string{"error": err.Error()})
+	}
+
+	t, past, status, msg := term.PastFromQuery(c, q)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	filters := repository.ExportMembersParams{
+		CommitteeID: nullIfEmpty(c.QueryParam("committee")),
+		DivisionID:  nullIfEmpty(c.QueryParam("division")),
+		House:       nullIfEmpty(c.QueryParam("house")),
+	}
+	var members []repository.ExportMembersRow
+	if past {
+		members, err = exportTermMembers(ctx, q, t.ID, filters)
+	} else {
+		members, err = q.ExportMembers(ctx, filters)
+	}
+	if err != nil {
+		slog.Error("Failed to export members", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export members"})
+	}
+
+	viewer, err := h.viewer(c)
+	if err != nil {
+		slog.Error("Failed to look up the caller", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	privacy, err := allPrivacy(ctx, q, viewer)
+	if err != nil {
+		slog.Error("Failed to get member privacy", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	for i := range members {
+		viewer.redactExport(&members[i], privacy[members[i].ID])
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "csv":
+		err = writeMembersCSV(&buf, fields, members)
+	case "xlsx":
+		err = writeMembersXLSX(&buf, fields, members)
+	case "vcf":
+		writeMembersVCard(&buf, fields, members)
+	}
+	if err != nil {
+		slog.Error("Failed to write member export", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export members"})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="lscs-members.%s"`, out.extension))
+	return c.Blob(http.StatusOK, out.contentType, buf.Bytes())
+}
+
+func writeMembersCSV(buf *bytes.Buffer, fields []exportField, members []repository.ExportMembersRow) error {
+	w := csv.NewWriter(buf)
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+	w.Write(header)
+
+	record := make([]string, len(fields))
+	for _, m := range members {
+		for i, f := range fields {
+			record[i] = csvSafe(f.value(m))
+		}
+		w.Write(record)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// internationalPhonePattern matches a phone number in international format, such as +639171234567.
+var internationalPhonePattern = regexp.MustCompile(`^\+[0-9]{7,15}$`)
+
+// csvSafe keeps spreadsheet apps from running a value as a formula, by quoting values that start
+// like one. Phone numbers such as +639171234567 are left alone.
+func csvSafe(s string) string {
+	if s == "" {
+		return s
+	}
+	switch s[0] {
+	case '=', '@', '-', '\t', '\r':
+		return "'" + s
+	case '+':
+		if !internationalPhonePattern.MatchString(s) {
+			return "'" + s
+		}
+	}
+	return s
+}
+
+// writeMembersVCard writes a vCard 3.0 contact for each member. The name is always included, as
+// a vCard needs one; the other properties follow the selected fields.
+func writeMembersVCard(buf *bytes.Buffer, fields []exportField, members []repository.ExportMembersRow) {
+	selected := map[string]bool{}
+	for _, f := range fields {
+		selected[f.name] = true
+	}
+
+	for _, m := range members {
+		vcardLine(buf, "BEGIN:VCARD")
+		vcardLine(buf, "VERSION:3.0")
+		vcardLine(buf, "FN:"+vcardEscape(m.FullName))
+		// Names are stored whole, so all of it goes in the given name
+		vcardLine(buf, "N:;"+vcardEscape(m.FullName)+";;;")
+		if selected["nickname"] && m.Nickname.String != "" {
+			vcardLine(buf, "NICKNAME:"+vcardEscape(m.Nickname.String))
+		}
+		if selected["email"] {
+			vcardLine(buf, "EMAIL;TYPE=INTERNET:"+vcardEscape(m.Email))
+		}
+		if selected["contact_number"] && m.ContactNumber.String != "" {
+			vcardLine(buf, "TEL;TYPE=CELL:"+vcardEscape(m.ContactNumber.String))
+		}
+		org := "LSCS"
+		if selected["committee_name"] && m.CommitteeName.String != "" {
+			org += ";" + vcardEscape(m.CommitteeName.String)
+		}
+		vcardLine(buf, "ORG:"+org)
+		if selected["position_name"] && m.PositionName.String != "" {
+			vcardLine(buf, "TITLE:"+vcardEscape(m.PositionName.String))
+		}
+		if selected["fb_link"] && m.FbLink.String != "" {
+			vcardLine(buf, "URL:"+vcardEscape(m.FbLink.String))
+		}
+		vcardLine(buf, "END:VCARD")
+	}
+}
+
+var vcardEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func vcardEscape(s string) string {
+	return vcardEscaper.Replace(s)
+}
+
+// vcardLine writes a content line, folded so that no line is longer than 75 bytes (RFC 2425).
+func vcardLine(buf *bytes.Buffer, line string) {
+	limit := 75
+	for len(line) > limit {
+		// Do not split a UTF-8 sequence
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space
+		limit = 74
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// writeMembersXLSX writes the export as a single-sheet workbook. Cells are inline strings, so
+// that IDs and phone numbers are not turned into numbers.
+func writeMembersXLSX(buf *bytes.Buffer, fields []exportField, members []repository.ExportMembersRow) error {
+	var sheet strings.Builder
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	writeRow := func(n int, values []string) {
+		fmt.Fprintf(&sheet, `<row r="%d">`, n)
+		for i, v := range values {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, xlsxColumn(i), n)
+			xml.EscapeText(&sheet, []byte(v))
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		values[i] = f.name
+	}
+	writeRow(1, values)
+	for n, m := range members {
+		for i, f := range fields {
+			values[i] = f.value(m)
+		}
+		writeRow(n+2, values)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Members" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+
+	zw := zip.NewWriter(buf)
+	for _, p := range parts {
+		w, err := zw.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, p.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xlsxColumn returns the letters of the zero-based column i, such as A, Z or AA.
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
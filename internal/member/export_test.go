@@ -0,0 +1,229 @@
+package member
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var readOnlyKey = repository.ApiKey{ApiKeyID: 2, MemberEmail: "dev@dlsu.edu.ph", Scopes: "members:read"}
+
+func exportRows() *sqlmock.Rows {
+	return sqlmock.NewRows(memberInfoColumns).
+		AddRow(123, "juan@dlsu.edu.ph", "Dela Cruz, Juan", "Juan", "RND", "Research and Development", "INT", "Internals",
+			"CT", "Committee Trainee", "Gell-Mann", "+639171234567", "CCS", "BSCS", nil, nil, nil, nil).
+		AddRow(124, "maria@dlsu.edu.ph", "Maria Santos", nil, "RND", "Research and Development", "INT", "Internals",
+			nil, nil, nil, nil, nil, nil, "=HYPERLINK(\"x\")", nil, nil, nil)
+}
+
+func exportRequest(t *testing.T, target string, key repository.ApiKey) (echo.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middlewares.APIKeyContextKey, key)
+	return c, rec
+}
+
+func TestExportMembersHandler(t *testing.T) {
+	adminKey := repository.ApiKey{ApiKeyID: 1, MemberEmail: "admin@dlsu.edu.ph", IsAdmin: true}
+
+	t.Run("csv", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members m").
+			WithArgs("RND", "RND", nil, nil, nil, nil).
+			WillReturnRows(exportRows())
+
+		c, rec := exportRequest(t, "/members/export?committee=RND&fields=id,full_name,contact_number,interests", adminKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, `attachment; filename="lscs-members.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, "id,full_name,contact_number,interests\n"+
+				"123,\"Dela Cruz, Juan\",+639171234567,\n"+
+				"124,Maria Santos,,\"'=HYPERLINK(\"\"x\"\")\"\n", rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("default fields without members:pii", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		// A key without members:pii is redacted as any member, so its owner is not looked up
+		mock.ExpectQuery("SELECT (.+) FROM members m").WillReturnRows(exportRows())
+
+		c, rec := exportRequest(t, "/members/export", readOnlyKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			header, _, _ := strings.Cut(rec.Body.String(), "\n")
+			assert.Equal(t, "id,full_name,nickname,committee_id,committee_name,division_id,division_name,position_id,position_name,house_name", header)
+			assert.NotContains(t, rec.Body.String(), "juan@dlsu.edu.ph")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("past term", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(12, 3, 2025, 2026, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms WHERE term_id").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(85))
+		mock.ExpectQuery("SELECT (.+) FROM member_terms mt").
+			WithArgs(12, nil, nil, nil, nil, "Gell-Mann", "Gell-Mann").
+			WillReturnRows(exportRows())
+
+		c, rec := exportRequest(t, "/members/export?term=12&house=Gell-Mann&fields=id,full_name", adminKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "id,full_name\n123,\"Dela Cruz, Juan\"\n124,Maria Santos\n", rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("vcf", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members m").WillReturnRows(exportRows())
+
+		c, rec := exportRequest(t, "/members/export?format=vcf", adminKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/vcard; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+			card, _, _ := strings.Cut(rec.Body.String(), "END:VCARD\r\n")
+			assert.Equal(t, "BEGIN:VCARD\r\n"+
+				"VERSION:3.0\r\n"+
+				"FN:Dela Cruz\\, Juan\r\n"+
+				"N:;Dela Cruz\\, Juan;;;\r\n"+
+				"NICKNAME:Juan\r\n"+
+				"EMAIL;TYPE=INTERNET:juan@dlsu.edu.ph\r\n"+
+				"TEL;TYPE=CELL:+639171234567\r\n"+
+				"ORG:LSCS;Research and Development\r\n"+
+				"TITLE:Committee Trainee\r\n", card)
+			assert.Equal(t, 2, strings.Count(rec.Body.String(), "BEGIN:VCARD"))
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members m").WillReturnRows(exportRows())
+
+		c, rec := exportRequest(t, "/members/export?format=xlsx&fields=id,full_name", adminKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, f := range zr.File {
+				if f.Name != "xl/worksheets/sheet1.xml" {
+					continue
+				}
+				r, _ := f.Open()
+				sheet, _ := io.ReadAll(r)
+				assert.Contains(t, string(sheet), `<c r="B2" t="inlineStr"><is><t xml:space="preserve">Dela Cruz, Juan</t></is></c>`)
+				return
+			}
+			t.Error("workbook has no sheet")
+		}
+	})
+
+	t.Run("fail - pii field without scope", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		c, rec := exportRequest(t, "/members/export?fields=full_name,email", readOnlyKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+		}
+	})
+
+	t.Run("fail - unknown field", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		c, rec := exportRequest(t, "/members/export?fields=full_name,password", adminKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("fail - unknown format", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		c, rec := exportRequest(t, "/members/export?format=pdf", adminKey)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.ExportMembersHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestVCardLineFolding(t *testing.T) {
+	var buf bytes.Buffer
+	vcardLine(&buf, "NOTE:"+strings.Repeat("é", 60))
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Equal(t, "NOTE:"+strings.Repeat("é", 60), strings.ReplaceAll(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n ", ""))
+}
+
+func TestCSVSafe(t *testing.T) {
+	cases := map[string]string{
+		"":               "",
+		"Juan":           "Juan",
+		"+639171234567":  "+639171234567",
+		"09171234567":    "09171234567",
+		"=SUM(A1)":       "'=SUM(A1)",
+		"@SUM(A1)":       "'@SUM(A1)",
+		"+SUM(A1)":       "'+SUM(A1)",
+		"-2+3":           "'-2+3",
+		"-1":             "'-1",
+		"+1":             "'+1",
+		"+63 917 123 45": "'+63 917 123 45",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, csvSafe(in), in)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"log/slog"
 	"net/http"
+	"strconv"
 
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
 	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
 	"github.com/labstack/echo/v4"
 )
@@ -26,8 +28,20 @@ func (h *Handler) GetMemberInfo(c echo.Context) error {
 		slog.Error("email is not an LSCS member", "err", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Email is not an LSCS member"})
 	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(int(memberInfo.ID)))
 
 	response := toFullInfoMemberResponse(memberInfo)
+	viewer, err := h.viewer(c)
+	if err != nil {
+		slog.Error("Failed to look up the caller", "err", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	privacy, err := privacyFor(ctx, q, viewer, response.ID, response.Email, response.CommitteeID.String)
+	if err != nil {
+		slog.Error("Failed to get member privacy", "err", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	viewer.RedactFull(&response, privacy)
 
 	return c.JSON(http.StatusOK, response)
 }
@@ -43,6 +57,7 @@ func (h *Handler) GetMemberInfoByID(c echo.Context) error {
 		slog.Error("Failed to parse request body", "err", err)
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
 	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(req.Id))
 
 	memberInfo, err := q.GetMemberInfoById(ctx, int32(req.Id))
 	if err != nil {
@@ -51,25 +66,17 @@ func (h *Handler) GetMemberInfoByID(c echo.Context) error {
 	}
 
 	response := toFullInfoMemberResponse(repository.GetMemberInfoRow(memberInfo))
-
-	return c.JSON(http.StatusOK, response)
-}
-
-func (h *Handler) GetAllMembersHandler(c echo.Context) error {
-	ctx := c.Request().Context()
-	dbconn := h.dbService.GetConnection()
-	queries := repository.New(dbconn)
-
-	members, err := queries.ListMembers(ctx)
+	viewer, err := h.viewer(c)
 	if err != nil {
-		slog.Error("Failed to list members", "err", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list members"})
+		slog.Error("Failed to look up the caller", "err", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
-
-	response := make([]MemberResponse, 0, len(members))
-	for _, m := range members {
-		response = append(response, toMemberResponse(m))
+	privacy, err := privacyFor(ctx, q, viewer, response.ID, response.Email, response.CommitteeID.String)
+	if err != nil {
+		slog.Error("Failed to get member privacy", "err", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
 	}
+	viewer.RedactFull(&response, privacy)
 
 	return c.JSON(http.StatusOK, response)
 }
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
 	"github.com/stretchr/testify/assert"
 	"github.com/labstack/echo/v4"
 )
@@ -30,6 +31,14 @@ func (m *mockDBService) GetConnection() *sql.DB {
 	return m.db
 }
 
+var memberInfoColumns = []string{
+	"id", "email", "full_name", "nickname", "committee_id", "committee_name", "division_id", "division_name",
+	"position_id", "position_name", "house_name", "contact_number", "college", "program", "interests", "discord", "fb_link", "telegram",
+}
+
+// defaultPolicy is the policy handlers are tested with.
+var defaultPolicy = policy.New(policy.DefaultRules())
+
 func TestGetMemberInfo(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		e := echo.New()
@@ -44,12 +53,12 @@ func TestGetMemberInfo(t *testing.T) {
 		assert.NoError(t, err)
 		defer db.Close()
 
-		rows := sqlmock.NewRows([]string{"email", "full_name", "committee_name", "division_name", "position_name", "committee_id", "division_id", "house_name"}).
-			AddRow(reqBody.Email, "Test User", "RND", "INT", "Trainee", "RND", "INT", "Gell-Mann")
+		rows := sqlmock.NewRows(memberInfoColumns).
+			AddRow(123, reqBody.Email, "Test User", nil, "RND", "Research and Development", "INT", "Internals", "CT", "Committee Trainee", "Gell-Mann", nil, nil, nil, nil, nil, nil, nil)
 		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs(reqBody.Email).WillReturnRows(rows)
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.GetMemberInfo(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -72,7 +81,7 @@ func TestGetMemberInfo(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs(reqBody.Email).WillReturnError(sql.ErrNoRows)
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.GetMemberInfo(c)) {
 			assert.Equal(t, http.StatusInternalServerError, rec.Code)
@@ -94,12 +103,12 @@ func TestGetMemberInfoByID(t *testing.T) {
 		assert.NoError(t, err)
 		defer db.Close()
 
-		rows := sqlmock.NewRows([]string{"id", "email", "full_name", "committee_name", "division_name", "position_name", "committee_id", "division_id", "house_name"}).
-			AddRow(123, "test@dlsu.edu.ph", "Test User", "RND", "INT", "Trainee", "RND", "INT", "Gell-Mann")
+		rows := sqlmock.NewRows(memberInfoColumns).
+			AddRow(123, "test@dlsu.edu.ph", "Test User", nil, "RND", "Research and Development", "INT", "Internals", "CT", "Committee Trainee", "Gell-Mann", nil, nil, nil, nil, nil, nil, nil)
 		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs(int32(reqBody.Id)).WillReturnRows(rows)
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.GetMemberInfoByID(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -122,7 +131,7 @@ func TestGetMemberInfoByID(t *testing.T) {
 		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs(int32(reqBody.Id)).WillReturnError(sql.ErrNoRows)
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.GetMemberInfoByID(c)) {
 			assert.Equal(t, http.StatusBadRequest, rec.Code)
@@ -142,10 +151,11 @@ func TestGetAllMembersHandler(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "full_name", "nickname", "email", "telegram", "position_id", "committee_id", "college", "program", "discord", "interests", "contact_number", "fb_link", "house_name"}).
 		AddRow(1, "Test User 1", "Test1", "test1@dlsu.edu.ph", "", "MEM", "RND", "CCS", "CS-ST", "", "", "", "", "Gell-Mann")
+	mock.ExpectQuery("SELECT COUNT(.+) FROM members m").WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(1))
 	mock.ExpectQuery("SELECT (.+) FROM members m").WillReturnRows(rows)
 
 	dbService := &mockDBService{db: db}
-	h := NewHandler(dbService)
+	h := NewHandler(dbService, defaultPolicy)
 
 	if assert.NoError(t, h.GetAllMembersHandler(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
@@ -169,7 +179,7 @@ func TestCheckEmailHandler(t *testing.T) {
 		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").WithArgs(reqBody.Email).WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow(reqBody.Email))
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.CheckEmailHandler(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -192,7 +202,7 @@ func TestCheckEmailHandler(t *testing.T) {
 		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").WithArgs(reqBody.Email).WillReturnError(sql.ErrNoRows)
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.CheckEmailHandler(c)) {
 			assert.Equal(t, http.StatusNotFound, rec.Code)
@@ -217,7 +227,7 @@ func TestCheckIDIfMember(t *testing.T) {
 		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").WithArgs(int32(reqBody.Id)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(reqBody.Id))
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.CheckIDIfMember(c)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
@@ -240,7 +250,7 @@ func TestCheckIDIfMember(t *testing.T) {
 		mock.ExpectQuery("SELECT id FROM members WHERE id = ?").WithArgs(int32(reqBody.Id)).WillReturnError(sql.ErrNoRows)
 
 		dbService := &mockDBService{db: db}
-		h := NewHandler(dbService)
+		h := NewHandler(dbService, defaultPolicy)
 
 		if assert.NoError(t, h.CheckIDIfMember(c)) {
 			assert.Equal(t, http.StatusNotFound, rec.Code)
@@ -0,0 +1,237 @@
+package member
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderTotalCount is the number of members matching the filters, across all pages.
+	HeaderTotalCount = "X-Total-Count"
+	// HeaderNextCursor is the cursor of the next page. It is not set on the last page.
+	HeaderNextCursor = "X-Next-Cursor"
+
+	maxListLimit = 500
+)
+
+// listSorts are the columns the members list can be sorted by.
+var listSorts = map[string]bool{"full_name": true, "email": true, "id": true}
+
+// listCursor is where a page of the members list starts: after the member with this sort key
+// and id, in this sort order. It is sent to clients as opaque base64.
+type listCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   int32  `json:"i"`
+}
+
+func (cur listCursor) encode() string {
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeListCursor(s string) (listCursor, error) {
+	var cur listCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cur, err
+	}
+	err = json.Unmarshal(data, &cur)
+	return cur, err
+}
+
+// sortKey returns the value the members list is ordered by, matching sort_key in SearchMembers.
+func sortKey(m repository.SearchMembersRow, sort string) string {
+	switch sort {
+	case "email":
+		return m.Email
+	case "id":
+		return fmt.Sprintf("%010d", m.ID)
+	default:
+		return m.FullName
+	}
+}
+
+// likeContains returns a LIKE pattern matching values that contain s.
+func likeContains(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return sql.NullString{}
+	}
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+	return sql.NullString{String: "%" + s + "%", Valid: true}
+}
+
+// searchMembers counts the active members matching filters and returns the page params selects.
+func searchMembers(ctx context.Context, q *repository.Queries, filters repository.CountMembersParams, params repository.SearchMembersParams) (int64, []repository.SearchMembersRow, error) {
+	total, err := q.CountMembers(ctx, filters)
+	if err != nil {
+		return 0, nil, err
+	}
+	members, err := q.SearchMembers(ctx, params)
+	return total, members, err
+}
+
+// searchTermMembers is searchMembers over the roster recorded for a past term, so that members
+// are filtered by the committee, position and house they had then.
+func searchTermMembers(ctx context.Context, q *repository.Queries, termID int32, filters repository.CountMembersParams, params repository.SearchMembersParams) (int64, []repository.SearchMembersRow, error) {
+	total, err := q.CountTermMembers(ctx, repository.CountTermMembersParams{
+		TermID:      termID,
+		CommitteeID: filters.CommitteeID,
+		DivisionID:  filters.DivisionID,
+		PositionID:  filters.PositionID,
+		House:       filters.House,
+		College:     filters.College,
+		Program:     filters.Program,
+		Search:      filters.Search,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	rows, err := q.SearchTermMembers(ctx, repository.SearchTermMembersParams{
+		SortBy:      params.SortBy,
+		TermID:      termID,
+		CommitteeID: params.CommitteeID,
+		DivisionID:  params.DivisionID,
+		PositionID:  params.PositionID,
+		House:       params.House,
+		College:     params.College,
+		Program:     params.Program,
+		Search:      params.Search,
+		AfterKey:    params.AfterKey,
+		Descending:  params.Descending,
+		AfterID:     params.AfterID,
+		Limit:       params.Limit,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	members := make([]repository.SearchMembersRow, len(rows))
+	for i, r := range rows {
+		members[i] = repository.SearchMembersRow(r)
+	}
+	return total, members, nil
+}
+
+// GetAllMembersHandler lists the active members. They can be filtered, searched and sorted,
+// and paged with ?limit= and ?cursor=. Without a limit, every matching member is returned.
+// ?term= lists the members recorded for a past term instead, with the committee, position and
+// house each one had then.
+func (h *Handler) GetAllMembersHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	queries := repository.New(dbconn)
+
+	order := c.QueryParam("sort")
+	if order == "" {
+		order = "email"
+	}
+	sort := strings.TrimPrefix(order, "-")
+	if !listSorts[sort] {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "sort must be full_name, email or id, optionally prefixed with -"})
+	}
+
+	limit := int32(maxListLimit)
+	paged := c.QueryParam("limit") != "" || c.QueryParam("cursor") != ""
+	if s := c.QueryParam("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxListLimit {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("limit must be between 1 and %d", maxListLimit)})
+		}
+		limit = int32(n)
+	}
+
+	filters := repository.CountMembersParams{
+		CommitteeID: nullIfEmpty(c.QueryParam("committee")),
+		DivisionID:  nullIfEmpty(c.QueryParam("division")),
+		PositionID:  nullIfEmpty(c.QueryParam("position")),
+		House:       nullIfEmpty(c.QueryParam("house")),
+		College:     nullIfEmpty(c.QueryParam("college")),
+		Program:     nullIfEmpty(c.QueryParam("program")),
+		Search:      likeContains(c.QueryParam("q")),
+	}
+	params := repository.SearchMembersParams{
+		SortBy:      sort,
+		CommitteeID: filters.CommitteeID,
+		DivisionID:  filters.DivisionID,
+		PositionID:  filters.PositionID,
+		House:       filters.House,
+		College:     filters.College,
+		Program:     filters.Program,
+		Search:      filters.Search,
+		Descending:  sort != order,
+		Limit:       limit + 1,
+	}
+	if !paged {
+		params.Limit = 1<<31 - 1
+	}
+
+	if s := c.QueryParam("cursor"); s != "" {
+		cur, err := decodeListCursor(s)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid cursor"})
+		}
+		if cur.Sort != order {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "cursor is for a different sort"})
+		}
+		params.AfterKey = sql.NullString{String: cur.Key, Valid: true}
+		params.AfterID = sql.NullInt32{Int32: cur.ID, Valid: true}
+	}
+
+	t, past, status, msg := term.PastFromQuery(c, queries)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	var total int64
+	var members []repository.SearchMembersRow
+	var err error
+	if past {
+		total, members, err = searchTermMembers(ctx, queries, t.ID, filters, params)
+	} else {
+		total, members, err = searchMembers(ctx, queries, filters, params)
+	}
+	if err != nil {
+		slog.Error("Failed to list members", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to list members"})
+	}
+
+	if paged && len(members) > int(limit) {
+		members = members[:limit]
+		last := members[len(members)-1]
+		next := listCursor{Sort: order, Key: sortKey(last, sort), ID: last.ID}
+		c.Response().Header().Set(HeaderNextCursor, next.encode())
+	}
+	c.Response().Header().Set(HeaderTotalCount, strconv.FormatInt(total, 10))
+
+	viewer, err := h.viewer(c)
+	if err != nil {
+		slog.Error("Failed to look up the caller", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	privacy, err := allPrivacy(ctx, queries, viewer)
+	if err != nil {
+		slog.Error("Failed to get member privacy", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	response := make([]MemberResponse, 0, len(members))
+	for _, m := range members {
+		r := toMemberResponse(m)
+		viewer.RedactMember(&r, privacy[m.ID])
+		response = append(response, r)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
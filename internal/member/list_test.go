@@ -0,0 +1,218 @@
+package member
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var listColumns = []string{
+	"id", "full_name", "nickname", "email", "telegram", "position_id", "committee_id", "college", "program",
+	"discord", "interests", "contact_number", "fb_link", "house_name",
+}
+
+func listRequest(target string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec), rec
+}
+
+func TestGetAllMembersHandlerPaging(t *testing.T) {
+	filterArgs := []driver.Value{
+		"RND", "RND", nil, nil, nil, nil, nil, nil, "CCS", "CCS", nil, nil,
+		`%50\%%`, `%50\%%`, `%50\%%`, `%50\%%`,
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT COUNT(.+) FROM members m").
+			WithArgs(filterArgs...).
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(3))
+		args := append([]driver.Value{"full_name"}, filterArgs...)
+		args = append(args, nil, true, nil, nil, nil, nil, true, true, 3)
+		mock.ExpectQuery("SELECT (.+) FROM \\(").
+			WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows(listColumns).
+				AddRow(3, "Carla Lim", nil, "carla@dlsu.edu.ph", nil, nil, "RND", "CCS", nil, nil, nil, nil, nil, nil).
+				AddRow(2, "Ben Reyes", nil, "ben@dlsu.edu.ph", nil, nil, "RND", "CCS", nil, nil, nil, nil, nil, nil).
+				AddRow(1, "Ana Santos", nil, "ana@dlsu.edu.ph", nil, nil, "RND", "CCS", nil, nil, nil, nil, nil, nil))
+
+		c, rec := listRequest("/members?committee=RND&college=CCS&q=50%25&sort=-full_name&limit=2")
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "3", rec.Header().Get(HeaderTotalCount))
+			assert.Equal(t, listCursor{Sort: "-full_name", Key: "Ben Reyes", ID: 2}.encode(), rec.Header().Get(HeaderNextCursor))
+			assert.NotContains(t, rec.Body.String(), "Ana Santos")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT COUNT(.+) FROM members m").WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(3))
+		mock.ExpectQuery("SELECT (.+) FROM \\(").
+			WithArgs("id", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+				"0000000002", false, "0000000002", 2, "0000000002", 2, false, false, 3).
+			WillReturnRows(sqlmock.NewRows(listColumns).
+				AddRow(3, "Carla Lim", nil, "carla@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+
+		cursor := listCursor{Sort: "id", Key: "0000000002", ID: 2}.encode()
+		c, rec := listRequest("/members?sort=id&limit=2&cursor=" + cursor)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get(HeaderNextCursor))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - cursor for another sort", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		cursor := listCursor{Sort: "id", Key: "0000000002", ID: 2}.encode()
+		c, rec := listRequest("/members?sort=email&cursor=" + cursor)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("fail - bad parameters", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		for _, target := range []string{"/members?sort=nickname", "/members?limit=0", "/members?limit=501", "/members?cursor=%21"} {
+			c, rec := listRequest(target)
+			if assert.NoError(t, h.GetAllMembersHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+			}
+		}
+	})
+}
+
+var termColumns = []string{"id", "term", "start_year", "end_year", "starts_on", "ends_on"}
+
+func TestGetAllMembersHandlerTerm(t *testing.T) {
+	noFilters := []driver.Value{nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil}
+
+	t.Run("past term", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(12, 3, 2025, 2026, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms WHERE term_id").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(85))
+		mock.ExpectQuery("SELECT COUNT(.+) FROM member_terms mt").
+			WithArgs(append([]driver.Value{12}, noFilters...)...).
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(1))
+		args := append([]driver.Value{"email", 12}, noFilters...)
+		args = append(args, nil, false, nil, nil, nil, nil, false, false, 1<<31-1)
+		mock.ExpectQuery("SELECT (.+) FROM member_terms mt").
+			WithArgs(args...).
+			WillReturnRows(sqlmock.NewRows(listColumns).
+				AddRow(1, "Ana Santos", nil, "ana@dlsu.edu.ph", nil, "CT", "HRD", "CCS", nil, nil, nil, nil, nil, nil))
+
+		c, rec := listRequest("/members?term=12")
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "1", rec.Header().Get(HeaderTotalCount))
+			assert.Contains(t, rec.Body.String(), `"committee_id":"HRD"`)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("current term by id", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(13).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(13, 1, 2026, 2027, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms WHERE term_id").WithArgs(13).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE starts_on <= CURDATE()").
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(13, 1, 2026, 2027, nil, nil))
+		mock.ExpectQuery("SELECT COUNT(.+) FROM members m").WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(0))
+		mock.ExpectQuery("SELECT (.+) FROM members m").WillReturnRows(sqlmock.NewRows(listColumns))
+
+		c, rec := listRequest("/members?term=13")
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - unknown term", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(99).WillReturnError(sql.ErrNoRows)
+
+		c, rec := listRequest("/members?term=99")
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - term not started", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(14).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(14, 2, 2026, 2027, time.Now().AddDate(0, 2, 0), nil))
+
+		c, rec := listRequest("/members?term=14")
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - invalid term", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		c, rec := listRequest("/members?term=last")
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetAllMembersHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
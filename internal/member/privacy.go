@@ -0,0 +1,257 @@
+package member
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// privacyLevels are the visibilities a member can pick for a contact field, and the lowest tier
+// each one lets see the field. Members themselves and admin keys always see every field.
+var privacyLevels = map[string]struct {
+	visibility repository.MemberPrivacyVisibility
+	tier       Tier
+}{
+	"committee": {repository.MemberPrivacyVisibilityCOMMITTEE, TierCommittee},
+	"officers":  {repository.MemberPrivacyVisibilityOFFICERS, TierOfficer},
+	"nobody":    {repository.MemberPrivacyVisibilityNOBODY, TierSelf},
+}
+
+// Privacy is the lowest tier a member lets see each contact field. Fields without a setting use
+// the default in contactFields.
+type Privacy map[string]Tier
+
+func visibilityTier(v repository.MemberPrivacyVisibility) Tier {
+	for _, level := range privacyLevels {
+		if level.visibility == v {
+			return level.tier
+		}
+	}
+	// An unknown value hides the field rather than showing it
+	return TierSelf
+}
+
+func levelName(t Tier) string {
+	for name, level := range privacyLevels {
+		if level.tier == t {
+			return name
+		}
+	}
+	return "everyone"
+}
+
+// minTier returns the lowest tier that may see field, after the member's settings.
+func (p Privacy) minTier(field string, defaultTier Tier) Tier {
+	if t, ok := p[field]; ok && t > defaultTier {
+		return t
+	}
+	return defaultTier
+}
+
+// privacyFor returns the settings of a member that could change what v sees of them. Settings
+// only ever hide more, so they are not loaded for callers who see none or all of the fields.
+func privacyFor(ctx context.Context, q *repository.Queries, v Viewer, id int32, email, committeeID string) (Privacy, error) {
+	switch v.TierFor(email, committeeID) {
+	case TierCommittee, TierOfficer:
+	default:
+		return nil, nil
+	}
+
+	return loadPrivacy(ctx, q, id)
+}
+
+// allPrivacy returns the settings of every member, by member id, for redacting lists.
+func allPrivacy(ctx context.Context, q *repository.Queries, v Viewer) (map[int32]Privacy, error) {
+	if v.Admin || v.Dev || (v.CommitteeID == "" && !v.Officer) {
+		return nil, nil
+	}
+
+	rows, err := q.ListAllMemberPrivacy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	privacy := map[int32]Privacy{}
+	for _, r := range rows {
+		if privacy[r.MemberID] == nil {
+			privacy[r.MemberID] = Privacy{}
+		}
+		privacy[r.MemberID][r.Field] = visibilityTier(r.Visibility)
+	}
+	return privacy, nil
+}
+
+// privacyResponse lists who may see each contact field, such as {"contact_number": "officers"}.
+func privacyResponse(p Privacy) map[string]string {
+	response := map[string]string{}
+	for _, f := range contactFields {
+		response[f.name] = levelName(p.minTier(f.name, f.minTier))
+	}
+	return response
+}
+
+// signedInMember returns the id of the member signed in to the session, or the status and
+// message to respond with if there is none.
+func signedInMember(c echo.Context, q *repository.Queries) (int32, int, string) {
+	email, ok := c.Get("user_email").(string)
+	if !ok || email == "" {
+		return 0, http.StatusUnauthorized, "Not signed in"
+	}
+	m, err := q.GetMemberInfo(c.Request().Context(), email)
+	if err == sql.ErrNoRows {
+		return 0, http.StatusNotFound, "Email is not an LSCS member"
+	}
+	if err != nil {
+		slog.Error("failed to get member info", "error", err)
+		return 0, http.StatusInternalServerError, "Internal server error"
+	}
+	return m.ID, http.StatusOK, ""
+}
+
+func loadPrivacy(ctx context.Context, q *repository.Queries, id int32) (Privacy, error) {
+	rows, err := q.GetMemberPrivacy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	privacy := Privacy{}
+	for _, r := range rows {
+		privacy[r.Field] = visibilityTier(r.Visibility)
+	}
+	return privacy, nil
+}
+
+// GetPrivacyHandler returns who may see each of the signed-in member's contact fields.
+func (h *Handler) GetPrivacyHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	id, status, msg := signedInMember(c, q)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+
+	privacy, err := loadPrivacy(c.Request().Context(), q, id)
+	if err != nil {
+		slog.Error("failed to get member privacy", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	return c.JSON(http.StatusOK, privacyResponse(privacy))
+}
+
+// parsePrivacyUpdate reads a body such as {"contact_number": "nobody", "discord": null}, where
+// null goes back to the default. A field can only be hidden from more callers than by default.
+func parsePrivacyUpdate(body []byte) (map[string]*Tier, error) {
+	var raw map[string]*string
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return nil, errors.New("Invalid request format")
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+
+	defaults := map[string]Tier{}
+	for _, f := range contactFields {
+		defaults[f.name] = f.minTier
+	}
+
+	fields := make([]string, 0, len(raw))
+	for field := range raw {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	update := map[string]*Tier{}
+	for _, field := range fields {
+		defaultTier, ok := defaults[field]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a contact field", field)
+		}
+		value := raw[field]
+		if value == nil {
+			update[field] = nil
+			continue
+		}
+		level, ok := privacyLevels[strings.ToLower(*value)]
+		if !ok {
+			return nil, fmt.Errorf("%s must be committee, officers or nobody", field)
+		}
+		if level.tier < defaultTier {
+			return nil, fmt.Errorf("%s is only shown to %s by default, and can only be hidden further", field, levelName(defaultTier))
+		}
+		tier := level.tier
+		update[field] = &tier
+	}
+	return update, nil
+}
+
+// UpdatePrivacyHandler changes who may see the signed-in member's contact fields.
+func (h *Handler) UpdatePrivacyHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, status, msg := signedInMember(c, q)
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(int(id)))
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+	}
+	update, err := parsePrivacyUpdate(body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating privacy"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	for _, f := range contactFields {
+		tier, ok := update[f.name]
+		if !ok {
+			continue
+		}
+		if tier == nil || *tier == f.minTier {
+			err = qtx.DeleteMemberPrivacy(ctx, repository.DeleteMemberPrivacyParams{MemberID: id, Field: f.name})
+		} else {
+			err = qtx.SetMemberPrivacy(ctx, repository.SetMemberPrivacyParams{
+				MemberID:   id,
+				Field:      f.name,
+				Visibility: privacyLevels[levelName(*tier)].visibility,
+			})
+		}
+		if err != nil {
+			slog.Error("failed to update member privacy", "error", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating privacy"})
+		}
+	}
+
+	privacy, err := loadPrivacy(ctx, qtx, id)
+	if err != nil {
+		slog.Error("failed to get member privacy", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating privacy"})
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit member privacy update", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating privacy"})
+	}
+
+	return c.JSON(http.StatusOK, privacyResponse(privacy))
+}
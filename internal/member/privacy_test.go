@@ -0,0 +1,124 @@
+package member
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPrivacyRequest(method, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/me/privacy", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_email", "test@dlsu.edu.ph")
+	return c, rec
+}
+
+func expectSignedInMember(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("test@dlsu.edu.ph").
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(123, "test@dlsu.edu.ph", "Test User", nil, "RND", "Research and Development", "INT", "Internals",
+				"MEM", "Member", nil, nil, nil, nil, nil, nil, nil, nil))
+}
+
+func TestGetPrivacyHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	expectSignedInMember(mock)
+	mock.ExpectQuery("SELECT field, visibility FROM member_privacy").WithArgs(123).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "visibility"}).AddRow("contact_number", "NOBODY"))
+
+	c, rec := newPrivacyRequest(http.MethodGet, "")
+	h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+	if assert.NoError(t, h.GetPrivacyHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"contact_number":"nobody","discord":"committee","fb_link":"committee","telegram":"committee"}`, rec.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestUpdatePrivacyHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectSignedInMember(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO member_privacy").WithArgs(123, "discord", "OFFICERS").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE FROM member_privacy").WithArgs(123, "contact_number").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT field, visibility FROM member_privacy").WithArgs(123).
+			WillReturnRows(sqlmock.NewRows([]string{"field", "visibility"}).AddRow("discord", "OFFICERS"))
+		mock.ExpectCommit()
+
+		c, rec := newPrivacyRequest(http.MethodPatch, `{"discord": "officers", "contact_number": null}`)
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdatePrivacyHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"contact_number":"officers","discord":"officers","fb_link":"committee","telegram":"committee"}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	fails := []struct {
+		name, body string
+	}{
+		{"looser than the default", `{"contact_number": "committee"}`},
+		{"unknown level", `{"telegram": "everyone"}`},
+		{"not a contact field", `{"email": "nobody"}`},
+		{"no fields", `{}`},
+	}
+	for _, tc := range fails {
+		t.Run("fail - "+tc.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			expectSignedInMember(mock)
+
+			c, rec := newPrivacyRequest(http.MethodPatch, tc.body)
+			h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+			if assert.NoError(t, h.UpdatePrivacyHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
+
+func TestRedactWithPrivacy(t *testing.T) {
+	r := FullInfoMemberResponse{
+		ID:            123,
+		Email:         "test@dlsu.edu.ph",
+		CommitteeID:   nullable("RND"),
+		ContactNumber: nullable("+639171234567"),
+		Telegram:      nullable("@test"),
+	}
+	privacy := Privacy{"contact_number": TierSelf, "telegram": TierOfficer}
+
+	officer := r
+	Viewer{Email: "vp@dlsu.edu.ph", CommitteeID: "PUB", Officer: true}.RedactFull(&officer, privacy)
+	assert.False(t, officer.ContactNumber.Valid)
+	assert.Equal(t, "@test", officer.Telegram.String)
+
+	// Admin keys and the member themselves ignore the settings
+	admin := r
+	Viewer{Admin: true}.RedactFull(&admin, privacy)
+	assert.Equal(t, "+639171234567", admin.ContactNumber.String)
+
+	self := r
+	Viewer{Email: "test@dlsu.edu.ph"}.RedactFull(&self, privacy)
+	assert.Equal(t, "+639171234567", self.ContactNumber.String)
+}
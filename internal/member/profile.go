@@ -0,0 +1,301 @@
+package member
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// profileValidators are the fields a member can change about themselves, each with the function
+// that validates and normalizes a new value. Everything else about a member is managed by admins.
+var profileValidators = map[string]func(string) (string, error){
+	"nickname":       validateNickname,
+	"telegram":       validateTelegram,
+	"discord":        validateDiscord,
+	"interests":      validateInterests,
+	"contact_number": validateContactNumber,
+	"fb_link":        validateFbLink,
+}
+
+var (
+	discordPattern  = regexp.MustCompile(`^[a-z0-9_.]{2,32}$`)
+	telegramPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{4,31}$`)
+	// PH mobile numbers: 09XXXXXXXXX, 639XXXXXXXXX or +639XXXXXXXXX
+	phMobilePattern = regexp.MustCompile(`^(?:\+?63|0)(9\d{9})$`)
+	// Separators people type in phone numbers
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+)
+
+var facebookHosts = map[string]bool{
+	"facebook.com":     true,
+	"www.facebook.com": true,
+	"m.facebook.com":   true,
+	"web.facebook.com": true,
+	"fb.com":           true,
+	"www.fb.com":       true,
+}
+
+func validateNickname(s string) (string, error) {
+	if utf8.RuneCountInString(s) > 100 {
+		return "", errors.New("nickname must be at most 100 characters")
+	}
+	if strings.IndexFunc(s, unicode.IsControl) >= 0 {
+		return "", errors.New("nickname must not contain control characters")
+	}
+	return s, nil
+}
+
+func validateInterests(s string) (string, error) {
+	if utf8.RuneCountInString(s) > 1000 {
+		return "", errors.New("interests must be at most 1000 characters")
+	}
+	return s, nil
+}
+
+// validateTelegram accepts a Telegram username, with or without the leading @.
+func validateTelegram(s string) (string, error) {
+	s = strings.TrimPrefix(s, "@")
+	if !telegramPattern.MatchString(s) {
+		return "", errors.New("telegram must be a Telegram username: 5 to 32 letters, digits or underscores, starting with a letter")
+	}
+	return s, nil
+}
+
+// validateDiscord accepts a Discord username. Discord usernames are lowercase, 2 to 32 characters
+// of letters, digits, underscores and periods, and cannot have two periods in a row.
+func validateDiscord(s string) (string, error) {
+	s = strings.ToLower(strings.TrimPrefix(s, "@"))
+	if !discordPattern.MatchString(s) || strings.Contains(s, "..") {
+		return "", errors.New("discord must be a Discord username: 2 to 32 letters, digits, underscores or periods")
+	}
+	return s, nil
+}
+
+// validateContactNumber accepts a Philippine mobile number and normalizes it to +639XXXXXXXXX.
+func validateContactNumber(s string) (string, error) {
+	m := phMobilePattern.FindStringSubmatch(phoneSeparators.Replace(s))
+	if m == nil {
+		return "", errors.New("contact_number must be a Philippine mobile number, such as 0917 123 4567")
+	}
+	return "+63" + m[1], nil
+}
+
+// validateFbLink accepts a link to a Facebook profile and normalizes it to https://www.facebook.com/...
+func validateFbLink(s string) (string, error) {
+	invalid := errors.New("fb_link must be a link to a Facebook profile, such as https://www.facebook.com/username")
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil || u.Port() != "" {
+		return "", invalid
+	}
+	if !facebookHosts[strings.ToLower(u.Hostname())] {
+		return "", invalid
+	}
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	if path == "" {
+		return "", invalid
+	}
+
+	link := "https://www.facebook.com" + path
+	// profile.php links identify the profile by the id query parameter; other parameters are tracking
+	if path == "/profile.php" {
+		id := u.Query().Get("id")
+		if id == "" {
+			return "", invalid
+		}
+		link += "?id=" + url.QueryEscape(id)
+	}
+	if len(link) > 255 {
+		return "", errors.New("fb_link must be at most 255 characters")
+	}
+	return link, nil
+}
+
+// ValidateProfileField validates and normalizes a new value of a field members can edit
+// themselves, so that admins editing a member are held to the same rules.
+func ValidateProfileField(field, value string) (string, error) {
+	validate, ok := profileValidators[field]
+	if !ok {
+		return "", fmt.Errorf("%s is not a profile field", field)
+	}
+	return validate(value)
+}
+
+// parseProfileUpdate reads the fields of a PATCH /me body. A field set to null or "" is cleared;
+// fields that are left out are not changed.
+func parseProfileUpdate(body []byte) (map[string]sql.NullString, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return nil, errors.New("Invalid request format")
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("no fields to update")
+	}
+
+	// Sorted so the first error reported does not depend on map order
+	fields := make([]string, 0, len(raw))
+	for field := range raw {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	update := make(map[string]sql.NullString, len(raw))
+	for _, field := range fields {
+		validate, ok := profileValidators[field]
+		if !ok {
+			return nil, &readOnlyFieldError{field: field}
+		}
+
+		var value *string
+		if err := json.Unmarshal(raw[field], &value); err != nil {
+			return nil, fmt.Errorf("%s must be a string or null", field)
+		}
+		if value == nil || strings.TrimSpace(*value) == "" {
+			update[field] = sql.NullString{}
+			continue
+		}
+
+		normalized, err := validate(strings.TrimSpace(*value))
+		if err != nil {
+			return nil, err
+		}
+		update[field] = sql.NullString{String: normalized, Valid: true}
+	}
+	return update, nil
+}
+
+// readOnlyFieldError is returned for a field members cannot change themselves.
+type readOnlyFieldError struct {
+	field string
+}
+
+func (e *readOnlyFieldError) Error() string {
+	return fmt.Sprintf("%s cannot be changed through this endpoint", e.field)
+}
+
+// GetProfileHandler returns the signed-in member's own record. Members see all of their own
+// contact details, whatever their privacy settings.
+func (h *Handler) GetProfileHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	email, ok := c.Get("user_email").(string)
+	if !ok || email == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Not signed in"})
+	}
+
+	memberInfo, err := q.GetMemberInfo(c.Request().Context(), email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Email is not an LSCS member"})
+		}
+		slog.Error("failed to get member info", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(int(memberInfo.ID)))
+
+	return c.JSON(http.StatusOK, toFullInfoMemberResponse(memberInfo))
+}
+
+// UpdateProfileHandler lets the signed-in member change their own nickname, telegram, discord,
+// interests, contact_number and fb_link. Committee, position and house can only be changed by admins.
+func (h *Handler) UpdateProfileHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	email, ok := c.Get("user_email").(string)
+	if !ok || email == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Not signed in"})
+	}
+
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+	}
+	update, err := parseProfileUpdate(body)
+	if err != nil {
+		var readOnly *readOnlyFieldError
+		if errors.As(err, &readOnly) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error(), "field": readOnly.field})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating profile"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	current, err := qtx.GetMemberProfileForUpdate(ctx, email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Email is not an LSCS member"})
+		}
+		slog.Error("failed to get member profile", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating profile"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(int(current.ID)))
+
+	params := repository.UpdateMemberProfileParams{
+		Nickname:      current.Nickname,
+		Telegram:      current.Telegram,
+		Discord:       current.Discord,
+		Interests:     current.Interests,
+		ContactNumber: current.ContactNumber,
+		FbLink:        current.FbLink,
+		ID:            current.ID,
+	}
+	for field, value := range update {
+		switch field {
+		case "nickname":
+			params.Nickname = value
+		case "telegram":
+			params.Telegram = value
+		case "discord":
+			params.Discord = value
+		case "interests":
+			params.Interests = value
+		case "contact_number":
+			params.ContactNumber = value
+		case "fb_link":
+			params.FbLink = value
+		}
+	}
+
+	if err := qtx.UpdateMemberProfile(ctx, params); err != nil {
+		slog.Error("failed to update member profile", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating profile"})
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit member profile update", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating profile"})
+	}
+
+	memberInfo, err := q.GetMemberInfo(ctx, email)
+	if err != nil {
+		slog.Error("failed to get member info", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error updating profile"})
+	}
+
+	return c.JSON(http.StatusOK, toFullInfoMemberResponse(memberInfo))
+}
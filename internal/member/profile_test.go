@@ -0,0 +1,228 @@
+package member
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileValidators(t *testing.T) {
+	valid := []struct {
+		field, input, want string
+	}{
+		{"contact_number", "09171234567", "+639171234567"},
+		{"contact_number", "0917 123 4567", "+639171234567"},
+		{"contact_number", "+63 917-123-4567", "+639171234567"},
+		{"contact_number", "639171234567", "+639171234567"},
+		{"discord", "Juan.DC", "juan.dc"},
+		{"discord", "@juan_dc", "juan_dc"},
+		{"telegram", "@juandc", "juandc"},
+		{"fb_link", "https://www.facebook.com/juan.dc", "https://www.facebook.com/juan.dc"},
+		{"fb_link", "m.facebook.com/juan.dc/", "https://www.facebook.com/juan.dc"},
+		{"fb_link", "https://fb.com/juan.dc?mibextid=abc", "https://www.facebook.com/juan.dc"},
+		{"fb_link", "https://web.facebook.com/profile.php?id=1000123&ref=x", "https://www.facebook.com/profile.php?id=1000123"},
+		{"nickname", "Juan", "Juan"},
+	}
+	for _, tc := range valid {
+		got, err := profileValidators[tc.field](tc.input)
+		if assert.NoError(t, err, "%s %q", tc.field, tc.input) {
+			assert.Equal(t, tc.want, got, "%s %q", tc.field, tc.input)
+		}
+	}
+
+	invalid := []struct {
+		field, input string
+	}{
+		{"contact_number", "0917123456"},
+		{"contact_number", "02 8123 4567"},
+		{"contact_number", "+1 415 555 0100"},
+		{"discord", "j"},
+		{"discord", "juan..dc"},
+		{"discord", "juan#1234"},
+		{"telegram", "juan"},
+		{"telegram", "1juandc"},
+		{"fb_link", "https://facebook.evil.com/juan"},
+		{"fb_link", "https://www.facebook.com/"},
+		{"fb_link", "https://www.facebook.com/profile.php"},
+		{"fb_link", "javascript:alert(1)"},
+		{"nickname", strings.Repeat("a", 101)},
+		{"nickname", "Juan\n"},
+		{"interests", strings.Repeat("a", 1001)},
+	}
+	for _, tc := range invalid {
+		_, err := profileValidators[tc.field](tc.input)
+		assert.Error(t, err, "%s %q", tc.field, tc.input)
+	}
+}
+
+var profileColumns = []string{"id", "nickname", "telegram", "discord", "interests", "contact_number", "fb_link"}
+
+func newProfileRequest(body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/me", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_email", "test@dlsu.edu.ph")
+	return c, rec
+}
+
+func TestGetProfileHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, rec := newProfileRequest("")
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("test@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+				AddRow(123, "test@dlsu.edu.ph", "Test User", "Juan", "RND", "Research and Development", "INT", "Internals", "CT", "Committee Trainee", "Gell-Mann", "+639171234567", nil, nil, "Go", "juan.dc", nil, "@juan"))
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		// The member's own contact details are never redacted
+		if assert.NoError(t, h.GetProfileHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"contact_number":"+639171234567"`)
+			assert.Contains(t, rec.Body.String(), `"telegram":"@juan"`)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		c, rec := newProfileRequest("")
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("test@dlsu.edu.ph").WillReturnError(sql.ErrNoRows)
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.GetProfileHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateProfileHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		c, rec := newProfileRequest(`{"discord": "Juan.DC", "contact_number": "0917 123 4567", "telegram": null}`)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE email = \\? AND status = .ACTIVE. FOR UPDATE").
+			WithArgs("test@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows(profileColumns).AddRow(123, "Juan", "juandc", nil, "Go", nil, nil))
+		// Fields left out of the body keep their current values
+		mock.ExpectExec("UPDATE members").
+			WithArgs("Juan", nil, "juan.dc", "Go", "+639171234567", nil, 123).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("test@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+				AddRow(123, "test@dlsu.edu.ph", "Test User", "Juan", "RND", "Research and Development", "INT", "Internals", "CT", "Committee Trainee", "Gell-Mann", "+639171234567", nil, nil, "Go", "juan.dc", nil, nil))
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdateProfileHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"discord":"juan.dc"`)
+			assert.Contains(t, rec.Body.String(), `"contact_number":"+639171234567"`)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("admin-only field", func(t *testing.T) {
+		c, rec := newProfileRequest(`{"nickname": "Juan", "committee_id": "RND"}`)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdateProfileHandler(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"field":"committee_id"`)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		c, rec := newProfileRequest(`{"contact_number": "12345"}`)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdateProfileHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "contact_number")
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("non-string value", func(t *testing.T) {
+		c, rec := newProfileRequest(`{"nickname": 5}`)
+
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdateProfileHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("empty body", func(t *testing.T) {
+		c, rec := newProfileRequest(`{}`)
+
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdateProfileHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("not a member", func(t *testing.T) {
+		c, rec := newProfileRequest(`{"nickname": "Juan"}`)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE email = \\? AND status = .ACTIVE. FOR UPDATE").
+			WithArgs("test@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows(profileColumns))
+		mock.ExpectRollback()
+
+		h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+		if assert.NoError(t, h.UpdateProfileHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
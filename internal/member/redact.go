@@ -0,0 +1,147 @@
+package member
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// Tier is how much of a member's contact details a caller may see. Higher tiers see more.
+type Tier int
+
+const (
+	// TierDev is a dev key. It sees synthetic contact details, so that apps can be built
+	// against realistic data without exposing real members.
+	TierDev Tier = iota
+	// TierMember is a member outside the member's committee, or any production key that is not
+	// an admin key.
+	TierMember
+	// TierCommittee is a signed-in member in the same committee.
+	TierCommittee
+	// TierOfficer is a signed-in officer (see policy.ActionViewOfficerContacts).
+	TierOfficer
+	// TierSelf is the signed-in member themself.
+	TierSelf
+	// TierAdmin is an admin key.
+	TierAdmin
+)
+
+// contactFields are the fields that are redacted, with the lowest tier that may see each one.
+// Fields that are not listed are returned to every tier.
+var contactFields = []struct {
+	name      string
+	minTier   Tier
+	synthetic func(id int32) string
+}{
+	{"telegram", TierCommittee, func(id int32) string { return fmt.Sprintf("@lscs_member_%d", id) }},
+	{"discord", TierCommittee, func(id int32) string { return fmt.Sprintf("lscs_member_%d", id) }},
+	{"fb_link", TierCommittee, func(id int32) string { return fmt.Sprintf("https://www.facebook.com/lscs.member.%d", id) }},
+	{"contact_number", TierOfficer, func(id int32) string { return fmt.Sprintf("+63900%07d", id%10000000) }},
+}
+
+// Viewer is the caller that member responses are redacted for. Restricted viewers are production
+// keys: the key belongs to an app rather than to the member who requested it, so it never sees
+// more than TierMember, whoever that member is and whatever scopes the key has.
+type Viewer struct {
+	Admin       bool
+	Dev         bool
+	Restricted  bool
+	Officer     bool
+	Email       string
+	CommitteeID string
+}
+
+// TierFor returns the tier of v for the member with email and committeeID.
+func (v Viewer) TierFor(email, committeeID string) Tier {
+	switch {
+	case v.Admin:
+		return TierAdmin
+	case v.Dev:
+		return TierDev
+	case v.Restricted:
+		return TierMember
+	case v.Email != "" && v.Email == email:
+		return TierSelf
+	case v.Officer:
+		return TierOfficer
+	case committeeID != "" && v.CommitteeID == committeeID:
+		return TierCommittee
+	default:
+		return TierMember
+	}
+}
+
+// redact clears the contact details the viewer may not see, by default or because of the
+// member's privacy settings, or replaces them with synthetic ones for dev keys. Empty fields
+// stay empty, so responses have the same shape for every tier.
+func (v Viewer) redact(privacy Privacy, id int32, email string, committeeID sql.NullString, contactNumber, fbLink, telegram, discord *sql.NullString) {
+	tier := v.TierFor(email, committeeID.String)
+	values := map[string]*sql.NullString{
+		"telegram":       telegram,
+		"discord":        discord,
+		"fb_link":        fbLink,
+		"contact_number": contactNumber,
+	}
+	for _, f := range contactFields {
+		value := values[f.name]
+		if !value.Valid || tier >= privacy.minTier(f.name, f.minTier) {
+			continue
+		}
+		if tier == TierDev {
+			value.String = f.synthetic(id)
+		} else {
+			*value = sql.NullString{}
+		}
+	}
+}
+
+// RedactFull redacts a full member response for v.
+func (v Viewer) RedactFull(r *FullInfoMemberResponse, privacy Privacy) {
+	v.redact(privacy, r.ID, r.Email, r.CommitteeID.NullString,
+		&r.ContactNumber.NullString, &r.FbLink.NullString, &r.Telegram.NullString, &r.Discord.NullString)
+}
+
+// RedactMember redacts a member list response for v.
+func (v Viewer) RedactMember(r *MemberResponse, privacy Privacy) {
+	v.redact(privacy, r.ID, r.Email, r.CommitteeID.NullString,
+		&r.ContactNumber.NullString, &r.FbLink.NullString, &r.Telegram.NullString, &r.Discord.NullString)
+}
+
+func (v Viewer) redactExport(m *repository.ExportMembersRow, privacy Privacy) {
+	v.redact(privacy, m.ID, m.Email, m.CommitteeID, &m.ContactNumber, &m.FbLink, &m.Telegram, &m.Discord)
+}
+
+// viewer returns the Viewer for the caller of the request. Only the signed-in member of a member
+// session is looked up, for their committee and for whether the policy lets them see contact
+// details as an officer; API keys get a fixed tier, and a request without either gets the lowest
+// member tier.
+func (h *Handler) viewer(c echo.Context) (Viewer, error) {
+	claims, ok := middlewares.MemberSessionFromContext(c)
+	if !ok {
+		if key, ok := middlewares.APIKeyFromContext(c); ok {
+			return Viewer{Admin: key.IsAdmin, Dev: key.IsDev, Restricted: !key.IsAdmin && !key.IsDev, Email: key.MemberEmail}, nil
+		}
+		return Viewer{}, nil
+	}
+	email := claims.Email
+
+	q := repository.New(h.dbService.GetConnection())
+	m, err := q.GetMemberInfo(c.Request().Context(), email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Viewer{Email: email}, nil
+	}
+	if err != nil {
+		return Viewer{}, err
+	}
+	return Viewer{
+		Officer:     h.policy.Allows(policy.ActionViewOfficerContacts, policy.SubjectFromMember(m)),
+		Email:       m.Email,
+		CommitteeID: helpers.NullStringToString(m.CommitteeID),
+	}, nil
+}
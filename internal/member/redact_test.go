@@ -0,0 +1,221 @@
+package member
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/session"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func nullable(s string) helpers.NullableString {
+	return helpers.NullableString{NullString: sql.NullString{String: s, Valid: true}}
+}
+
+func TestViewerRedactFull(t *testing.T) {
+	member := func() FullInfoMemberResponse {
+		return FullInfoMemberResponse{
+			ID:            12345678,
+			Email:         "juan@dlsu.edu.ph",
+			FullName:      "Juan Dela Cruz",
+			CommitteeID:   nullable("RND"),
+			ContactNumber: nullable("+639171234567"),
+			FbLink:        nullable("https://www.facebook.com/juan"),
+			Telegram:      nullable("@juan"),
+		}
+	}
+
+	tests := []struct {
+		name     string
+		viewer   Viewer
+		tier     Tier
+		contacts string
+	}{
+		{
+			name:     "admin key",
+			viewer:   Viewer{Admin: true, Email: "admin@dlsu.edu.ph"},
+			tier:     TierAdmin,
+			contacts: `{"contact_number":"+639171234567","fb_link":"https://www.facebook.com/juan","telegram":"@juan","discord":""}`,
+		},
+		{
+			name:     "self",
+			viewer:   Viewer{Email: "juan@dlsu.edu.ph", CommitteeID: "RND"},
+			tier:     TierSelf,
+			contacts: `{"contact_number":"+639171234567","fb_link":"https://www.facebook.com/juan","telegram":"@juan","discord":""}`,
+		},
+		{
+			name:     "officer",
+			viewer:   Viewer{Email: "vp@dlsu.edu.ph", CommitteeID: "PUB", Officer: true},
+			tier:     TierOfficer,
+			contacts: `{"contact_number":"+639171234567","fb_link":"https://www.facebook.com/juan","telegram":"@juan","discord":""}`,
+		},
+		{
+			name:     "same committee",
+			viewer:   Viewer{Email: "ana@dlsu.edu.ph", CommitteeID: "RND"},
+			tier:     TierCommittee,
+			contacts: `{"contact_number":"","fb_link":"https://www.facebook.com/juan","telegram":"@juan","discord":""}`,
+		},
+		{
+			name:     "other member",
+			viewer:   Viewer{Email: "ben@dlsu.edu.ph", CommitteeID: "PUB"},
+			tier:     TierMember,
+			contacts: `{"contact_number":"","fb_link":"","telegram":"","discord":""}`,
+		},
+		{
+			name:     "dev key",
+			viewer:   Viewer{Dev: true, Email: "juan@dlsu.edu.ph"},
+			tier:     TierDev,
+			contacts: `{"contact_number":"+639002345678","fb_link":"https://www.facebook.com/lscs.member.12345678","telegram":"@lscs_member_12345678","discord":""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.tier, tt.viewer.TierFor("juan@dlsu.edu.ph", "RND"))
+
+			r := member()
+			tt.viewer.RedactFull(&r, nil)
+
+			// Every tier gets the same fields; only the contact details differ
+			data, _ := json.Marshal(r)
+			var got map[string]any
+			assert.NoError(t, json.Unmarshal(data, &got))
+			assert.Len(t, got, 18)
+			assert.Equal(t, "Juan Dela Cruz", got["full_name"])
+			assert.Equal(t, "juan@dlsu.edu.ph", got["email"])
+
+			contacts, _ := json.Marshal(map[string]any{
+				"contact_number": got["contact_number"],
+				"fb_link":        got["fb_link"],
+				"telegram":       got["telegram"],
+				"discord":        got["discord"],
+			})
+			assert.JSONEq(t, tt.contacts, string(contacts))
+		})
+	}
+}
+
+func TestGetMemberInfoRedaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("juan@dlsu.edu.ph").
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(12345678, "juan@dlsu.edu.ph", "Juan Dela Cruz", nil, "RND", "Research and Development", "INT", "Internals",
+				"MEM", "Member", nil, "+639171234567", nil, nil, nil, "juan#1234", nil, "@juan"))
+	mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("ana@dlsu.edu.ph").
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(11111111, "ana@dlsu.edu.ph", "Ana Santos", nil, "RND", "Research and Development", "INT", "Internals",
+				"MEM", "Member", nil, nil, nil, nil, nil, nil, nil, nil))
+	// Juan only shows his Discord to officers
+	mock.ExpectQuery("SELECT field, visibility FROM member_privacy").WithArgs(12345678).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "visibility"}).AddRow("discord", "OFFICERS"))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/member", bytes.NewReader([]byte(`{"email":"juan@dlsu.edu.ph"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middlewares.MemberSessionContextKey, &session.Claims{Email: "ana@dlsu.edu.ph", SessionID: 3})
+
+	h := NewHandler(&mockDBService{db: db}, defaultPolicy)
+
+	if assert.NoError(t, h.GetMemberInfo(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got map[string]any
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, "", got["contact_number"])
+		assert.Equal(t, "@juan", got["telegram"])
+		assert.Equal(t, "", got["discord"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestViewerMemberSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("vp@dlsu.edu.ph").
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(22222222, "vp@dlsu.edu.ph", "Maria Santos", nil, "PUB", "Publicity", "EXT", "Externals",
+				"VP", "Vice President", nil, nil, nil, nil, nil, nil, nil, nil))
+
+	// The signed-in member is the caller, not the owner of an app's key
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/members", nil), httptest.NewRecorder())
+	c.Set(middlewares.MemberSessionContextKey, &session.Claims{Email: "vp@dlsu.edu.ph", SessionID: 7})
+
+	v, err := NewHandler(&mockDBService{db: db}, defaultPolicy).viewer(c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, Viewer{Officer: true, Email: "vp@dlsu.edu.ph", CommitteeID: "PUB"}, v)
+		assert.Equal(t, TierOfficer, v.TierFor("juan@dlsu.edu.ph", "RND"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
+
+func TestViewerAPIKeyTier(t *testing.T) {
+	// Under the default policy only RND officers hold production keys, so the owner of every key
+	// is an officer. The key belongs to an app, though, so it never gets its owner's tier
+	for _, scopes := range []string{"members:read", "members:read members:pii"} {
+		t.Run(scopes, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/members/export", nil), httptest.NewRecorder())
+			c.Set(middlewares.APIKeyContextKey, repository.ApiKey{ApiKeyID: 4, MemberEmail: "avp@dlsu.edu.ph", Scopes: scopes})
+
+			v, err := NewHandler(&mockDBService{db: db}, policy.New(policy.DefaultRules())).viewer(c)
+			if assert.NoError(t, err) {
+				assert.Equal(t, TierMember, v.TierFor("juan@dlsu.edu.ph", "PUB"))
+				assert.Equal(t, TierMember, v.TierFor("ben@dlsu.edu.ph", "RND"))
+				assert.Equal(t, TierMember, v.TierFor("avp@dlsu.edu.ph", "RND"))
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
+
+func TestGetMemberInfoAppKeyOwnedByOfficer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs("juan@dlsu.edu.ph").
+		WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+			AddRow(12345678, "juan@dlsu.edu.ph", "Juan Dela Cruz", nil, "RND", "Research and Development", "INT", "Internals",
+				"MEM", "Member", nil, "+639171234567", nil, nil, nil, "juan#1234", nil, "@juan"))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/member", bytes.NewReader([]byte(`{"email":"juan@dlsu.edu.ph"}`)))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(middlewares.APIKeyContextKey, repository.ApiKey{ApiKeyID: 4, MemberEmail: "avp@dlsu.edu.ph", Scopes: "members:read members:pii"})
+
+	h := NewHandler(&mockDBService{db: db}, policy.New(policy.DefaultRules()))
+
+	if assert.NoError(t, h.GetMemberInfo(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var got map[string]any
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, "juan@dlsu.edu.ph", got["email"])
+		assert.Equal(t, "", got["contact_number"])
+		assert.Equal(t, "", got["telegram"])
+		assert.Equal(t, "", got["discord"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
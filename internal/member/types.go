@@ -2,15 +2,18 @@ package member
 
 import (
 	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
 )
 
 type Handler struct {
 	dbService database.Service
+	policy    *policy.Engine
 }
 
-func NewHandler(dbService database.Service) *Handler {
+func NewHandler(dbService database.Service, policyEngine *policy.Engine) *Handler {
 	return &Handler{
 		dbService: dbService,
+		policy:    policyEngine,
 	}
 
 }
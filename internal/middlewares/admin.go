@@ -0,0 +1,44 @@
+package middlewares
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireAdminKey only lets requests made with an admin API key through, and only while the
+// policy still lets the key's owner act as an admin (policy.ActionAdmin), so a member who steps
+// down loses admin access without their keys being revoked. It must run after APIKeyMiddleware.
+func RequireAdminKey(dbService database.Service, engine *policy.Engine) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, ok := APIKeyFromContext(c)
+			if !ok {
+				slog.Error("admin check ran without a verified api key")
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			}
+
+			if !key.IsAdmin {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Admin API key required"})
+			}
+
+			q := repository.New(dbService.GetConnection())
+			m, err := q.GetMemberInfo(c.Request().Context(), key.MemberEmail)
+			if err != nil && err != sql.ErrNoRows {
+				slog.Error("failed to get admin key owner", "error", err, "api_key_id", key.ApiKeyID)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			}
+			if err == sql.ErrNoRows || !engine.Allows(policy.ActionAdmin, policy.SubjectFromMember(m)) {
+				slog.Warn("admin key used by a member the policy no longer allows", "api_key_id", key.ApiKeyID, "member_email", key.MemberEmail)
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Admin access is no longer allowed for this API key"})
+			}
+
+			return next(c)
+		}
+	}
+}
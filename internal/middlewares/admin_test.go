@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+var memberInfoColumns = []string{
+	"id", "email", "full_name", "nickname", "committee_id", "committee_name", "division_id", "division_name",
+	"position_id", "position_name", "house_name", "contact_number", "college", "program", "interests", "discord", "fb_link", "telegram",
+}
+
+func TestRequireAdminKey(t *testing.T) {
+	adminKey := repository.ApiKey{ApiKeyID: 1, MemberEmail: "admin@dlsu.edu.ph", IsAdmin: true}
+
+	tests := []struct {
+		name     string
+		key      repository.ApiKey
+		position string // position of the key owner; empty if they are no longer a member
+		want     int
+	}{
+		{name: "admin key of an RND officer", key: adminKey, position: "VP", want: http.StatusOK},
+		{name: "admin key of a former officer", key: adminKey, position: "MEM", want: http.StatusForbidden},
+		{name: "admin key of a former member", key: adminKey, want: http.StatusForbidden},
+		{name: "production key", key: repository.ApiKey{ApiKeyID: 2, MemberEmail: "admin@dlsu.edu.ph"}, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+
+			if tt.key.IsAdmin {
+				q := mock.ExpectQuery("SELECT (.+) FROM members m").WithArgs(tt.key.MemberEmail)
+				if tt.position == "" {
+					q.WillReturnError(sql.ErrNoRows)
+				} else {
+					q.WillReturnRows(sqlmock.NewRows(memberInfoColumns).
+						AddRow(1, tt.key.MemberEmail, "Admin", nil, "RND", "Research and Development", "INT", "Internals",
+							tt.position, nil, nil, nil, nil, nil, nil, nil, nil, nil))
+				}
+			}
+
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/keys", nil), rec)
+			c.Set(APIKeyContextKey, tt.key)
+
+			h := RequireAdminKey(&mockDBService{db: db}, policy.New(policy.DefaultRules()))(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			if assert.NoError(t, h(c)) {
+				assert.Equal(t, tt.want, rec.Code)
+				assert.NoError(t, mock.ExpectationsWereMet())
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/labstack/echo/v4"
+)
+
+// UsageAnalyticsMiddleware counts every request made with an API key, with its status and
+// latency, per key, project and route. It must run after APIKeyMiddleware.
+func UsageAnalyticsMiddleware(recorder *analytics.Recorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, ok := APIKeyFromContext(c)
+			if !ok {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+
+			route := c.Request().Method + " " + c.Path()
+			recorder.Record(key.ApiKeyID, key.Project.String, route, responseStatus(c, err), time.Since(start), start)
+			return err
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageAnalyticsMiddleware(t *testing.T) {
+	recorder := analytics.NewRecorder(time.Hour)
+	h := UsageAnalyticsMiddleware(recorder)(func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound)
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/member", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/member")
+	c.Set(APIKeyContextKey, repository.ApiKey{ApiKeyID: 7, Project: sql.NullString{String: "Links", Valid: true}})
+
+	assert.Error(t, h(c))
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO api_usage_buckets").
+		WithArgs(sqlmock.AnyArg(), 7, "Links", "POST /member", 1, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, recorder.Flush(context.Background(), repository.New(db)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
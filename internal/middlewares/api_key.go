@@ -0,0 +1,70 @@
+package middlewares
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyContextKey is the echo context key under which the verified api_keys row is stored.
+const APIKeyContextKey = "api_key"
+
+var errInvalidAuthHeader = errors.New("invalid Authorization header format")
+
+// APIKeyMiddleware checks that the bearer token is an API key that is still stored in api_keys
+// and has not expired. It runs after the JWT middleware, so a deleted key stops working even if
+// its signature is still valid. The resolved row is stored in the context under APIKeyContextKey,
+// and the request is counted in the key's usage by the recorder.
+func APIKeyMiddleware(dbService database.Service, recorder *analytics.Recorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokenString, err := bearerToken(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid Authorization header format"})
+			}
+
+			q := repository.New(dbService.GetConnection())
+			key, err := q.GetAPIKeyInfo(c.Request().Context(), auth.HashAPIKey(tokenString))
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+				}
+				slog.Error("failed to look up api key", "error", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			}
+
+			if key.ExpiresAt.Valid && !key.ExpiresAt.Time.After(time.Now()) {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "API key has expired"})
+			}
+
+			recorder.Touch(key.ApiKeyID, time.Now())
+
+			c.Set(APIKeyContextKey, key)
+			return next(c)
+		}
+	}
+}
+
+// APIKeyFromContext returns the api_keys row stored by APIKeyMiddleware.
+func APIKeyFromContext(c echo.Context) (repository.ApiKey, bool) {
+	key, ok := c.Get(APIKeyContextKey).(repository.ApiKey)
+	return key, ok
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header.
+func bearerToken(c echo.Context) (string, error) {
+	parts := strings.Split(c.Request().Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", errInvalidAuthHeader
+	}
+	return parts[1], nil
+}
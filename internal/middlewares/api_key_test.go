@@ -0,0 +1,139 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDBService is a mock implementation of the database.Service interface.
+type mockDBService struct {
+	db *sql.DB
+}
+
+func (m *mockDBService) Health() map[string]string {
+	return nil
+}
+
+func (m *mockDBService) Close() error {
+	return nil
+}
+
+func (m *mockDBService) GetConnection() *sql.DB {
+	return m.db
+}
+
+var apiKeyColumns = []string{"api_key_id", "member_email", "api_key_hash", "project", "allowed_origin", "is_dev", "is_admin", "scopes", "created_at", "expires_at", "last_used_at", "request_count"}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	const token = "test_jwt_token"
+
+	okHandler := func(c echo.Context) error {
+		key, ok := APIKeyFromContext(c)
+		if !ok {
+			return c.NoContent(http.StatusTeapot)
+		}
+		return c.String(http.StatusOK, key.MemberEmail)
+	}
+
+	t.Run("success - stored key", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM api_keys k JOIN members m (.+) WHERE k.api_key_hash = ?").
+			WithArgs(auth.HashAPIKey(token)).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+				AddRow(1, "test@dlsu.edu.ph", auth.HashAPIKey(token), "Test Project", "https://test.dlsu-lscs.org", false, false, "members:read", time.Now(), nil, nil, 0))
+
+		recorder := analytics.NewRecorder(time.Hour)
+		h := APIKeyMiddleware(&mockDBService{db: db}, recorder)(okHandler)
+
+		if assert.NoError(t, h(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "test@dlsu.edu.ph", rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+
+		// The use of the key is written on the next flush, not during the request
+		mock.ExpectExec("UPDATE api_keys SET last_used_at").
+			WithArgs(sqlmock.AnyArg(), 1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		assert.NoError(t, recorder.Flush(context.Background(), repository.New(db)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fail - deleted key", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM api_keys k JOIN members m (.+) WHERE k.api_key_hash = ?").
+			WithArgs(auth.HashAPIKey(token)).
+			WillReturnError(sql.ErrNoRows)
+
+		h := APIKeyMiddleware(&mockDBService{db: db}, analytics.NewRecorder(time.Hour))(okHandler)
+
+		if assert.NoError(t, h(c)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+
+	t.Run("fail - expired key", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM api_keys k JOIN members m (.+) WHERE k.api_key_hash = ?").
+			WithArgs(auth.HashAPIKey(token)).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).
+				AddRow(1, "test@dlsu.edu.ph", auth.HashAPIKey(token), nil, nil, true, false, "members:read", time.Now().Add(-48*time.Hour), time.Now().Add(-time.Hour), nil, 0))
+
+		h := APIKeyMiddleware(&mockDBService{db: db}, analytics.NewRecorder(time.Hour))(okHandler)
+
+		if assert.NoError(t, h(c)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+
+	t.Run("fail - missing bearer token", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		h := APIKeyMiddleware(&mockDBService{}, analytics.NewRecorder(time.Hour))(okHandler)
+
+		if assert.NoError(t, h(c)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
@@ -0,0 +1,141 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	t.Run("api key read", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/member-id", nil)
+		req.Header.Set(echo.HeaderXRealIP, "203.0.113.7")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/member-id")
+		c.Set(APIKeyContextKey, repository.ApiKey{ApiKeyID: 7, MemberEmail: "test@dlsu.edu.ph"})
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO audit_events").
+			WithArgs("test@dlsu.edu.ph", 7, "members.read", "POST /member-id", audit.TargetMember, "12345678", audit.OutcomeSuccess, http.StatusOK, "203.0.113.7", nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		h := Audit(&mockDBService{db: db}, "members.read")(func(c echo.Context) error {
+			audit.SetTarget(c, audit.TargetMember, "12345678")
+			return c.NoContent(http.StatusOK)
+		})
+
+		if assert.NoError(t, h(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("denied google request", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/request-key", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/request-key")
+		c.Set("user_email", "test@dlsu.edu.ph")
+
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO audit_events").
+			WithArgs("test@dlsu.edu.ph", nil, "api_keys.issue", "POST /request-key", nil, nil, audit.OutcomeDenied, http.StatusForbidden, sqlmock.AnyArg(), nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		h := Audit(&mockDBService{db: db}, "api_keys.issue")(func(c echo.Context) error {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "User is not allowed to request API keys"})
+		})
+
+		if assert.NoError(t, h(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
+
+func TestAuditRoutes(t *testing.T) {
+	actions := map[string]string{
+		"DELETE /admin/keys/:id": "api_keys.revoke",
+		"GET /admin/members/:id": "members.read",
+	}
+
+	newServer := func(t *testing.T) (*echo.Echo, sqlmock.Sqlmock) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+
+		e := echo.New()
+		g := e.Group("/admin")
+		// Stands in for an API key middleware that rejects a revoked key after parsing its token
+		g.Use(AuditRoutes(&mockDBService{db: db}, actions), func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Set("user", &jwt.Token{Claims: &auth.JwtCustomClaims{Email: "test@dlsu.edu.ph"}})
+				if c.Request().Header.Get(echo.HeaderAuthorization) == "" {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "API key has been revoked"})
+				}
+				return next(c)
+			}
+		})
+		g.GET("/keys", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		g.GET("/members/:id", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+		g.DELETE("/keys/:id", func(c echo.Context) error {
+			audit.SetRecorded(c)
+			return c.NoContent(http.StatusOK)
+		})
+		return e, mock
+	}
+
+	t.Run("rejected by authentication", func(t *testing.T) {
+		e, mock := newServer(t)
+		mock.ExpectExec("INSERT INTO audit_events").
+			WithArgs("test@dlsu.edu.ph", nil, "members.read", "GET /admin/members/:id", nil, nil, audit.OutcomeDenied, http.StatusUnauthorized, sqlmock.AnyArg(), nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/members/12345678", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("route without an action", func(t *testing.T) {
+		e, mock := newServer(t)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer key")
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("recorded by the handler", func(t *testing.T) {
+		e, mock := newServer(t)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/admin/keys/7", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer key")
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
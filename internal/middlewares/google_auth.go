@@ -1,48 +1,71 @@
 package middlewares
 
 import (
-	"context"
+	"errors"
 	"log/slog"
 	"net/http"
-	"os"
-	"strings"
 
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
 	"github.com/labstack/echo/v4"
-	"google.golang.org/api/idtoken"
 )
 
-// TODO: use this for verifying google emails
-func GoogleAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header is required"})
-		}
+// IdentityContextKey is the echo context key under which the verified auth.Identity is stored.
+const IdentityContextKey = "identity"
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid Authorization header format"})
-		}
+// GoogleAuthMiddleware checks the Google ID token in the Authorization header with verifier, then
+// lets the account in only if rules map it to a member. Denied accounts get a 403 with one of the
+// auth.SignIn* codes. The member's primary email is stored under "user_email", and the Google
+// identity under IdentityContextKey, also for denied accounts.
+func GoogleAuthMiddleware(dbService database.Service, verifier auth.IdentityVerifier, rules auth.SignInRules) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Authorization header is required"})
+			}
 
-		tokenString := parts[1]
-		audience := os.Getenv("GOOGLE_CLIENT_ID")
-		if audience == "" {
-			slog.Error("GOOGLE_CLIENT_ID environment variable not set")
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
-		}
+			tokenString, err := bearerToken(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid Authorization header format"})
+			}
 
-		payload, err := idtoken.Validate(context.Background(), tokenString, audience)
-		if err != nil {
-			slog.Error("failed to validate token", "error", err)
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid ID token"})
-		}
+			identity, err := verifier.Verify(c.Request().Context(), tokenString)
+			if err != nil {
+				slog.Error("failed to validate token", "error", err)
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid ID token"})
+			}
 
-		email, ok := payload.Claims["email"].(string)
-		if !ok || email == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Email not found in token"})
-		}
+			if identity.Email == "" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Email not found in token"})
+			}
 
-		c.Set("user_email", email)
-		return next(c)
+			// Stored before the member check, so a denied account is named in the audit log
+			c.Set(IdentityContextKey, identity)
+
+			q := repository.New(dbService.GetConnection())
+			email, err := rules.MemberEmail(c.Request().Context(), q, identity)
+			if err != nil {
+				var se *auth.SignInError
+				if errors.As(err, &se) {
+					return c.JSON(http.StatusForbidden, map[string]string{
+						"error": se.Message,
+						"code":  se.Code,
+						"email": identity.Email,
+					})
+				}
+				slog.Error("failed to check member email", "error", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			}
+
+			c.Set("user_email", email)
+			return next(c)
+		}
 	}
 }
+
+// IdentityFromContext returns the identity stored by GoogleAuthMiddleware.
+func IdentityFromContext(c echo.Context) (auth.Identity, bool) {
+	identity, ok := c.Get(IdentityContextKey).(auth.Identity)
+	return identity, ok
+}
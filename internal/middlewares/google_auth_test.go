@@ -0,0 +1,129 @@
+package middlewares
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVerifier accepts a single token.
+type fakeVerifier struct {
+	token    string
+	identity auth.Identity
+	err      error
+}
+
+func (f *fakeVerifier) Verify(ctx context.Context, idToken string) (auth.Identity, error) {
+	if f.err != nil {
+		return auth.Identity{}, f.err
+	}
+	if idToken != f.token {
+		return auth.Identity{}, auth.ErrInvalidIDToken
+	}
+	return f.identity, nil
+}
+
+func TestGoogleAuthMiddleware(t *testing.T) {
+	okHandler := func(c echo.Context) error {
+		identity, ok := IdentityFromContext(c)
+		if !ok {
+			return c.NoContent(http.StatusTeapot)
+		}
+		return c.String(http.StatusOK, c.Get("user_email").(string)+" "+identity.Subject)
+	}
+
+	run := func(verifier auth.IdentityVerifier, authorization string, expect ...func(sqlmock.Sqlmock)) *httptest.ResponseRecorder {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		for _, e := range expect {
+			e(mock)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/request-key", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		GoogleAuthMiddleware(&mockDBService{db: db}, verifier, auth.DefaultSignInRules())(okHandler)(echo.New().NewContext(req, rec))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		return rec
+	}
+
+	member := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WithArgs("test@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("test@dlsu.edu.ph"))
+	}
+
+	verifier := &fakeVerifier{
+		token:    "id-token",
+		identity: auth.Identity{Subject: "1234567890", Email: "test@dlsu.edu.ph", EmailVerified: true, HostedDomain: "dlsu.edu.ph"},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		rec := run(verifier, "Bearer id-token", member)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "test@dlsu.edu.ph 1234567890", rec.Body.String())
+	})
+
+	t.Run("fail - missing header", func(t *testing.T) {
+		rec := run(verifier, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("fail - invalid token", func(t *testing.T) {
+		rec := run(verifier, "Bearer forged")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"error": "Invalid ID token"}`, rec.Body.String())
+	})
+
+	t.Run("success - personal account of a member", func(t *testing.T) {
+		personal := &fakeVerifier{token: "id-token", identity: auth.Identity{Subject: "1", Email: "juan@gmail.com", EmailVerified: true}}
+		rec := run(personal, "Bearer id-token", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT m.email FROM member_emails e").
+				WithArgs("juan@gmail.com").
+				WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("juan_delacruz@dlsu.edu.ph"))
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "juan_delacruz@dlsu.edu.ph 1", rec.Body.String())
+	})
+
+	t.Run("fail - email not verified", func(t *testing.T) {
+		unverified := &fakeVerifier{token: "id-token", identity: auth.Identity{Email: "test@dlsu.edu.ph", HostedDomain: "dlsu.edu.ph"}}
+		rec := run(unverified, "Bearer id-token")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"email_not_verified"`)
+	})
+
+	t.Run("fail - outside hosted domain", func(t *testing.T) {
+		personal := &fakeVerifier{token: "id-token", identity: auth.Identity{Email: "someone@gmail.com", EmailVerified: true}}
+		rec := run(personal, "Bearer id-token", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT m.email FROM member_emails e").WillReturnError(sql.ErrNoRows)
+		})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"domain_not_allowed"`)
+	})
+
+	t.Run("fail - not a member", func(t *testing.T) {
+		rec := run(verifier, "Bearer id-token", func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT email FROM members WHERE email = ?").WillReturnError(sql.ErrNoRows)
+			mock.ExpectQuery("SELECT m.email FROM member_emails e").WillReturnError(sql.ErrNoRows)
+		})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"not_a_member"`)
+	})
+
+	t.Run("fail - no email", func(t *testing.T) {
+		rec := run(&fakeVerifier{token: "id-token", identity: auth.Identity{Subject: "1"}}, "Bearer id-token")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.JSONEq(t, `{"error": "Email not found in token"}`, rec.Body.String())
+	})
+}
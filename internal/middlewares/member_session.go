@@ -0,0 +1,79 @@
+package middlewares
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/session"
+	"github.com/labstack/echo/v4"
+)
+
+// MemberSessionContextKey is the echo context key under which the claims of a verified member
+// access token are stored.
+const MemberSessionContextKey = "member_session"
+
+// MemberSessionMiddleware checks that the bearer token is a member access token whose session has
+// not been revoked, so logging out takes effect before the token expires. Like
+// GoogleAuthMiddleware, it stores the member's email under "user_email".
+func MemberSessionMiddleware(dbService database.Service, tokens *session.Tokens) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			tokenString, err := bearerToken(c)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid Authorization header format"})
+			}
+
+			claims, err := tokens.Verify(tokenString)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid access token"})
+			}
+
+			q := repository.New(dbService.GetConnection())
+			s, err := q.GetActiveMemberSession(c.Request().Context(), claims.SessionID)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Session has ended"})
+				}
+				slog.Error("failed to look up session", "error", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			}
+			if s.MemberEmail != claims.Email {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid access token"})
+			}
+
+			c.Set(MemberSessionContextKey, claims)
+			c.Set("user_email", claims.Email)
+			return next(c)
+		}
+	}
+}
+
+// MemberSessionOr authenticates requests that carry a member access token with
+// MemberSessionMiddleware, and every other request with apiKeyAuth, the middlewares that verify
+// an API key. It lets routes be called both by apps with their own key and by frontends on behalf
+// of a signed-in member, who is then the caller.
+func MemberSessionOr(dbService database.Service, tokens *session.Tokens, apiKeyAuth ...echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withSession := MemberSessionMiddleware(dbService, tokens)(next)
+		withAPIKey := next
+		for i := len(apiKeyAuth) - 1; i >= 0; i-- {
+			withAPIKey = apiKeyAuth[i](withAPIKey)
+		}
+
+		return func(c echo.Context) error {
+			if tokenString, err := bearerToken(c); err == nil && session.IsAccessToken(tokenString) {
+				return withSession(c)
+			}
+			return withAPIKey(c)
+		}
+	}
+}
+
+// MemberSessionFromContext returns the access token claims stored by MemberSessionMiddleware.
+func MemberSessionFromContext(c echo.Context) (*session.Claims, bool) {
+	claims, ok := c.Get(MemberSessionContextKey).(*session.Claims)
+	return claims, ok
+}
@@ -0,0 +1,161 @@
+package middlewares
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/session"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemberSessionMiddleware(t *testing.T) {
+	tokens := session.NewTokens("test-secret", nil)
+
+	okHandler := func(c echo.Context) error {
+		claims, ok := MemberSessionFromContext(c)
+		if !ok {
+			return c.NoContent(http.StatusTeapot)
+		}
+		return c.String(http.StatusOK, c.Get("user_email").(string)+" "+claims.Email)
+	}
+
+	run := func(t *testing.T, token string, expect func(sqlmock.Sqlmock)) *httptest.ResponseRecorder {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		if expect != nil {
+			expect(mock)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/auth/logout-all", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h := MemberSessionMiddleware(&mockDBService{db: db}, tokens)(okHandler)
+		require.NoError(t, h(echo.New().NewContext(req, rec)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		return rec
+	}
+
+	token, _, err := tokens.Issue("test@dlsu.edu.ph", 7)
+	require.NoError(t, err)
+
+	t.Run("success - active session", func(t *testing.T) {
+		rec := run(t, token, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT id, member_email FROM member_sessions").
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "member_email"}).AddRow(7, "test@dlsu.edu.ph"))
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "test@dlsu.edu.ph test@dlsu.edu.ph", rec.Body.String())
+	})
+
+	t.Run("fail - revoked session", func(t *testing.T) {
+		rec := run(t, token, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT id, member_email FROM member_sessions").
+				WithArgs(7).
+				WillReturnError(sql.ErrNoRows)
+		})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("fail - api key", func(t *testing.T) {
+		// API keys have no audience, so they are not member access tokens
+		apiKey, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"email": "test@dlsu.edu.ph",
+			"sid":   7,
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		rec := run(t, apiKey, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("fail - signed with another secret", func(t *testing.T) {
+		other, _, err := session.NewTokens("other-secret", nil).Issue("test@dlsu.edu.ph", 7)
+		require.NoError(t, err)
+
+		rec := run(t, other, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
+
+func TestMemberSessionOr(t *testing.T) {
+	tokens := session.NewTokens("test-secret", nil)
+
+	// Stands in for the API key middlewares
+	apiKeyAuth := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(APIKeyContextKey, repository.ApiKey{ApiKeyID: 3, MemberEmail: "app@dlsu.edu.ph"})
+			return next(c)
+		}
+	}
+	caller := func(c echo.Context) error {
+		if claims, ok := MemberSessionFromContext(c); ok {
+			return c.String(http.StatusOK, "member "+claims.Email)
+		}
+		if key, ok := APIKeyFromContext(c); ok {
+			return c.String(http.StatusOK, "key "+key.MemberEmail)
+		}
+		return c.NoContent(http.StatusTeapot)
+	}
+
+	run := func(t *testing.T, token string, expect func(sqlmock.Sqlmock)) *httptest.ResponseRecorder {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+		if expect != nil {
+			expect(mock)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h := MemberSessionOr(&mockDBService{db: db}, tokens, apiKeyAuth)(caller)
+		require.NoError(t, h(echo.New().NewContext(req, rec)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		return rec
+	}
+
+	t.Run("member access token", func(t *testing.T) {
+		token, _, err := tokens.Issue("test@dlsu.edu.ph", 7)
+		require.NoError(t, err)
+
+		rec := run(t, token, func(mock sqlmock.Sqlmock) {
+			mock.ExpectQuery("SELECT id, member_email FROM member_sessions").
+				WithArgs(7).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "member_email"}).AddRow(7, "test@dlsu.edu.ph"))
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "member test@dlsu.edu.ph", rec.Body.String())
+	})
+
+	t.Run("forged member access token", func(t *testing.T) {
+		// Claiming the member audience does not skip verification
+		token, _, err := session.NewTokens("other-secret", nil).Issue("test@dlsu.edu.ph", 7)
+		require.NoError(t, err)
+
+		rec := run(t, token, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("api key", func(t *testing.T) {
+		apiKey, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"email": "app@dlsu.edu.ph",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}).SignedString([]byte("test-secret"))
+		require.NoError(t, err)
+
+		rec := run(t, apiKey, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "key app@dlsu.edu.ph", rec.Body.String())
+	})
+}
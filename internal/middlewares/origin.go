@@ -0,0 +1,124 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// OriginMiddleware enforces where an API key may be used from. Admin keys are accepted from
+// anywhere, dev keys only from http://localhost, and production keys only from their registered
+// allowed_origin. The caller's origin is taken from the Origin header, falling back to Referer;
+// a request with neither is rejected, so backend servers using a production key must send their
+// key's origin themselves. It must run after APIKeyMiddleware.
+func OriginMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key, ok := APIKeyFromContext(c)
+		if !ok {
+			slog.Error("origin check ran without a verified api key")
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+
+		if key.IsAdmin {
+			return next(c)
+		}
+
+		origin := requestOrigin(c.Request())
+		if origin == "" {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Origin or Referer header is required"})
+		}
+
+		if key.IsDev {
+			if !helpers.IsLocalhostOrigin(origin) {
+				slog.Warn("dev api key used outside localhost", "api_key_id", key.ApiKeyID, "member_email", key.MemberEmail, "origin", origin)
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Dev API keys can only be used from http://localhost"})
+			}
+			return next(c)
+		}
+
+		if origin != helpers.NormalizeOrigin(helpers.NullStringToString(key.AllowedOrigin)) {
+			slog.Warn("api key used from unregistered origin", "api_key_id", key.ApiKeyID, "member_email", key.MemberEmail, "origin", origin, "allowed_origin", key.AllowedOrigin.String)
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Origin is not allowed for this API key"})
+		}
+
+		return next(c)
+	}
+}
+
+// allowedOriginsTTL is how long CORSAllowOriginFunc uses the registered origins before loading
+// them again, so a newly issued production key works from its origin within this time.
+const allowedOriginsTTL = time.Minute
+
+// allowedOrigins caches the origins registered on production keys.
+type allowedOrigins struct {
+	mu       sync.Mutex
+	origins  map[string]bool
+	loadedAt time.Time
+}
+
+// contains reports whether origin is registered, loading the origins again once they are older
+// than allowedOriginsTTL. If loading fails, the previous set is kept.
+func (a *allowedOrigins) contains(q *repository.Queries, origin string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.origins == nil || time.Since(a.loadedAt) >= allowedOriginsTTL {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		rows, err := q.ListAllowedOrigins(ctx)
+		if err != nil {
+			slog.Error("failed to load cors origins", "error", err)
+		} else {
+			a.origins = make(map[string]bool, len(rows))
+			for _, o := range rows {
+				if n := helpers.NormalizeOrigin(o.String); n != "" {
+					a.origins[n] = true
+				}
+			}
+		}
+		// A failed load is retried after the TTL, not on every request
+		a.loadedAt = time.Now()
+	}
+	return a.origins[origin]
+}
+
+// CORSAllowOriginFunc returns an AllowOriginFunc for echo's CORS middleware that only allows
+// origins registered on a production key, localhost (for dev keys), and the given static origins
+// such as the key management dashboard. The registered origins are cached for allowedOriginsTTL.
+func CORSAllowOriginFunc(dbService database.Service, staticOrigins []string) func(origin string) (bool, error) {
+	static := make(map[string]bool, len(staticOrigins))
+	for _, o := range staticOrigins {
+		if n := helpers.NormalizeOrigin(o); n != "" {
+			static[n] = true
+		}
+	}
+	registered := &allowedOrigins{}
+
+	return func(origin string) (bool, error) {
+		origin = helpers.NormalizeOrigin(origin)
+		if origin == "" {
+			return false, nil
+		}
+		if static[origin] || helpers.IsLocalhostOrigin(origin) {
+			return true, nil
+		}
+		return registered.contains(repository.New(dbService.GetConnection()), origin), nil
+	}
+}
+
+// requestOrigin returns the normalized origin of the caller, or an empty string if the request
+// carries neither an Origin nor a Referer header.
+func requestOrigin(r *http.Request) string {
+	if origin := r.Header.Get(echo.HeaderOrigin); origin != "" {
+		return helpers.NormalizeOrigin(origin)
+	}
+	return helpers.NormalizeOrigin(r.Referer())
+}
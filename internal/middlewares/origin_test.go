@@ -0,0 +1,123 @@
+package middlewares
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOriginMiddleware(t *testing.T) {
+	prodKey := repository.ApiKey{ApiKeyID: 1, MemberEmail: "test@dlsu.edu.ph", AllowedOrigin: sql.NullString{String: "https://links.app.dlsu-lscs.org", Valid: true}}
+	devKey := repository.ApiKey{ApiKeyID: 2, MemberEmail: "test@dlsu.edu.ph", IsDev: true}
+	adminKey := repository.ApiKey{ApiKeyID: 3, MemberEmail: "test@dlsu.edu.ph", IsAdmin: true}
+
+	tests := []struct {
+		name    string
+		key     repository.ApiKey
+		origin  string
+		referer string
+		want    int
+	}{
+		{name: "production key from its origin", key: prodKey, origin: "https://links.app.dlsu-lscs.org", want: http.StatusOK},
+		{name: "production key from referer", key: prodKey, referer: "https://links.app.dlsu-lscs.org/dashboard", want: http.StatusOK},
+		{name: "production key from another origin", key: prodKey, origin: "https://oms.app.dlsu-lscs.org", want: http.StatusForbidden},
+		{name: "production key without origin", key: prodKey, want: http.StatusForbidden},
+		{name: "dev key from localhost", key: devKey, origin: "http://localhost:3000", want: http.StatusOK},
+		{name: "dev key from a deployed origin", key: devKey, origin: "https://links.app.dlsu-lscs.org", want: http.StatusForbidden},
+		{name: "dev key without origin", key: devKey, want: http.StatusForbidden},
+		{name: "admin key from anywhere", key: adminKey, origin: "https://anything.example.com", want: http.StatusOK},
+		{name: "admin key without origin", key: adminKey, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/members", nil)
+			if tt.origin != "" {
+				req.Header.Set(echo.HeaderOrigin, tt.origin)
+			}
+			if tt.referer != "" {
+				req.Header.Set("Referer", tt.referer)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set(APIKeyContextKey, tt.key)
+
+			h := OriginMiddleware(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			if assert.NoError(t, h(c)) {
+				assert.Equal(t, tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestCORSAllowOriginFunc(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	allow := CORSAllowOriginFunc(&mockDBService{db: db}, []string{"https://keys.dlsu-lscs.org"})
+
+	t.Run("static origin", func(t *testing.T) {
+		ok, err := allow("https://keys.dlsu-lscs.org")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("localhost", func(t *testing.T) {
+		ok, err := allow("http://localhost:3000")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("registered origins are loaded once", func(t *testing.T) {
+		mock.ExpectQuery("SELECT DISTINCT allowed_origin FROM api_keys").
+			WillReturnRows(sqlmock.NewRows([]string{"allowed_origin"}).AddRow("https://links.app.dlsu-lscs.org/"))
+
+		ok, err := allow("https://links.app.dlsu-lscs.org")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = allow("https://evil.example.com")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAllowedOriginsReload(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	q := repository.New(db)
+	a := &allowedOrigins{}
+
+	mock.ExpectQuery("SELECT DISTINCT allowed_origin FROM api_keys").
+		WillReturnRows(sqlmock.NewRows([]string{"allowed_origin"}))
+	assert.False(t, a.contains(q, "https://oms.app.dlsu-lscs.org"))
+
+	// Once the TTL has passed the origins are loaded again, picking up new keys
+	a.loadedAt = time.Now().Add(-allowedOriginsTTL)
+	mock.ExpectQuery("SELECT DISTINCT allowed_origin FROM api_keys").
+		WillReturnRows(sqlmock.NewRows([]string{"allowed_origin"}).AddRow("https://oms.app.dlsu-lscs.org"))
+	assert.True(t, a.contains(q, "https://oms.app.dlsu-lscs.org"))
+
+	// A failed load keeps the previous origins
+	a.loadedAt = time.Now().Add(-allowedOriginsTTL)
+	mock.ExpectQuery("SELECT DISTINCT allowed_origin FROM api_keys").WillReturnError(sql.ErrConnDone)
+	assert.True(t, a.contains(q, "https://oms.app.dlsu-lscs.org"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/ratelimit"
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimitMiddleware limits how often each API key can call the API, using a token bucket per
+// api_key_id sized by the class of the key. It must run after APIKeyMiddleware. Every request,
+// allowed or not, is added to the key's daily usage counters, which the recorder writes out.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, recorder *analytics.Recorder) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, ok := APIKeyFromContext(c)
+			if !ok {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+			}
+
+			d := limiter.Allow(key.ApiKeyID, auth.ClassOf(key.IsDev, key.IsAdmin))
+			if d.Limit > 0 {
+				h := c.Response().Header()
+				h.Set("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+				h.Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+				h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+			}
+
+			recorder.CountDay(key.ApiKeyID, !d.Allowed, time.Now())
+
+			if !d.Allowed {
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(ceilSeconds(d.RetryAfter)))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Rate limit exceeded"})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds, as used by Retry-After and X-RateLimit-Reset.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
+	"github.com/dlsu-lscs/lscs-core-api/internal/ratelimit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limits{Production: ratelimit.Limit{Requests: 1, Per: time.Minute}})
+	key := repository.ApiKey{ApiKeyID: 7, MemberEmail: "test@dlsu.edu.ph"}
+
+	recorder := analytics.NewRecorder(time.Hour)
+
+	h := RateLimitMiddleware(limiter, recorder)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	call := func() *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/members", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(APIKeyContextKey, key)
+		assert.NoError(t, h(c))
+		return rec
+	}
+
+	t.Run("allowed", func(t *testing.T) {
+		rec := call()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", rec.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "60", rec.Header().Get("X-RateLimit-Reset"))
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		rec := call()
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("unlimited admin key", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(APIKeyContextKey, repository.ApiKey{ApiKeyID: 1, IsAdmin: true})
+
+		assert.NoError(t, h(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("daily usage is written on flush", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO api_key_daily_usage").
+			WithArgs(7, sqlmock.AnyArg(), 1, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO api_key_daily_usage").
+			WithArgs(1, sqlmock.AnyArg(), 1, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		mock.MatchExpectationsInOrder(false)
+		assert.NoError(t, recorder.Flush(context.Background(), repository.New(db)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireScope only lets a request through if its API key was granted scope.
+// Admin keys have every scope, and so do members calling in a member session, since handlers
+// show them what the member may see. It must run after APIKeyMiddleware or MemberSessionOr.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if _, ok := MemberSessionFromContext(c); ok {
+				return next(c)
+			}
+
+			key, ok := APIKeyFromContext(c)
+			if !ok {
+				slog.Error("scope check ran without a verified api key")
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+			}
+
+			if !KeyHasScope(key, scope) {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "API key is missing the required scope",
+					"scope": scope,
+				})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// CallerHasScope reports whether the caller of the request has scope: a member in a member
+// session, or an API key that was granted scope.
+func CallerHasScope(c echo.Context, scope string) bool {
+	if _, ok := MemberSessionFromContext(c); ok {
+		return true
+	}
+	key, ok := APIKeyFromContext(c)
+	return ok && KeyHasScope(key, scope)
+}
+
+// KeyHasScope reports whether key was granted scope. Admin keys have every scope.
+func KeyHasScope(key repository.ApiKey, scope string) bool {
+	return key.IsAdmin || auth.HasScope(auth.ParseScopes(key.Scopes), scope)
+}
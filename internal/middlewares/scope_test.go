@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/session"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScope(t *testing.T) {
+	tests := []struct {
+		name string
+		key  repository.ApiKey
+		want int
+	}{
+		{name: "key with scope", key: repository.ApiKey{Scopes: "members:read members:pii"}, want: http.StatusOK},
+		{name: "key without scope", key: repository.ApiKey{Scopes: "members:read committees:read"}, want: http.StatusForbidden},
+		{name: "key without any scopes", key: repository.ApiKey{}, want: http.StatusForbidden},
+		{name: "admin key", key: repository.ApiKey{IsAdmin: true}, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/members", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set(APIKeyContextKey, tt.key)
+
+			h := RequireScope(auth.ScopeMembersPII)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			if assert.NoError(t, h(c)) {
+				assert.Equal(t, tt.want, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRequireScopeMemberSession(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/members", nil), rec)
+	c.Set(MemberSessionContextKey, &session.Claims{Email: "test@dlsu.edu.ph", SessionID: 7})
+
+	h := RequireScope(auth.ScopeMembersPII)(func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	if assert.NoError(t, h(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
@@ -0,0 +1,172 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type CreateClientRequest struct {
+	Name         string   `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	// Public clients (SPAs, mobile apps) cannot keep a secret and rely on PKCE alone.
+	Public bool `json:"public"`
+}
+
+// ClientResponse describes a registered client, without its secret.
+type ClientResponse struct {
+	ClientID     string               `json:"client_id"`
+	Name         string               `json:"name"`
+	RedirectURIs []string             `json:"redirect_uris"`
+	Public       bool                 `json:"public"`
+	OwnerEmail   string               `json:"owner_email"`
+	CreatedAt    helpers.NullableTime `json:"created_at"`
+}
+
+func toClientResponse(c repository.ListOAuthClientsRow) ClientResponse {
+	return ClientResponse{
+		ClientID:     c.ClientID,
+		Name:         c.Name,
+		RedirectURIs: strings.Fields(c.RedirectUris),
+		Public:       !c.Confidential,
+		OwnerEmail:   c.OwnerEmail,
+		CreatedAt:    helpers.NullableTime{NullTime: c.CreatedAt},
+	}
+}
+
+// validateRedirectURI accepts absolute https URLs, and http URLs on localhost for development.
+func validateRedirectURI(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid redirect URI %q", raw)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("redirect URI %q must not have a fragment", raw)
+	}
+	if u.Scheme != "https" && !(u.Scheme == "http" && helpers.IsLocalhostOrigin(raw)) {
+		return fmt.Errorf("redirect URI %q must use https", raw)
+	}
+	return nil
+}
+
+// CreateClientHandler registers an app that can sign members in. The client secret is only ever
+// returned here.
+func (h *Handler) CreateClientHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	var req CreateClientRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot read body"})
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "name is required"})
+	}
+	if len(req.RedirectURIs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "at least one redirect URI is required"})
+	}
+	for _, uri := range req.RedirectURIs {
+		if err := validateRedirectURI(uri); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	key, ok := middlewares.APIKeyFromContext(c)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		slog.Error("failed to generate client id", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	clientID := hex.EncodeToString(id)
+	audit.SetTarget(c, audit.TargetOAuthClient, clientID)
+
+	var secret string
+	var secretHash sql.NullString
+	if !req.Public {
+		var err error
+		secret, err = randomToken()
+		if err != nil {
+			slog.Error("failed to generate client secret", "error", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+		}
+		secretHash = sql.NullString{String: hashToken(secret), Valid: true}
+	}
+
+	if err := q.CreateOAuthClient(ctx, repository.CreateOAuthClientParams{
+		ClientID:         clientID,
+		ClientSecretHash: secretHash,
+		Name:             req.Name,
+		RedirectUris:     strings.Join(req.RedirectURIs, " "),
+		OwnerEmail:       key.MemberEmail,
+	}); err != nil {
+		slog.Error("failed to create oauth client", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error creating client"})
+	}
+
+	response := map[string]interface{}{
+		"client_id":     clientID,
+		"name":          req.Name,
+		"redirect_uris": req.RedirectURIs,
+		"public":        req.Public,
+	}
+	if secret != "" {
+		response["client_secret"] = secret
+	}
+	return c.JSON(http.StatusCreated, response)
+}
+
+// ListClientsHandler returns every registered client.
+func (h *Handler) ListClientsHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	clients, err := q.ListOAuthClients(c.Request().Context())
+	if err != nil {
+		slog.Error("failed to list oauth clients", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error listing clients"})
+	}
+
+	response := make([]ClientResponse, 0, len(clients))
+	for _, cl := range clients {
+		response = append(response, toClientResponse(cl))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"clients": response,
+	})
+}
+
+// DeleteClientHandler removes a client. Its pending authorization codes go with it; tokens it was
+// already given stay valid until they expire.
+func (h *Handler) DeleteClientHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+	audit.SetTarget(c, audit.TargetOAuthClient, c.Param("id"))
+
+	deleted, err := q.DeleteOAuthClient(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		slog.Error("failed to delete oauth client", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting client"})
+	}
+	if deleted == 0 {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Client not found"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"success":   "Client deleted",
+		"client_id": c.Param("id"),
+	})
+}
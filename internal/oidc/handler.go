@@ -0,0 +1,382 @@
+package oidc
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const csrfCookieName = "lscs_oidc_login"
+
+// oauthError is an OAuth 2.0 error response (RFC 6749, section 5.2).
+func oauthError(c echo.Context, status int, code, description string) error {
+	return c.JSON(status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// redirectError sends the member back to the client with an error (RFC 6749, section 4.1.2.1).
+// Only use it once redirectURI is known to be registered for the client.
+func redirectError(c echo.Context, redirectURI, state, code, description string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Invalid redirect_uri")
+	}
+	q := u.Query()
+	q.Set("error", code)
+	q.Set("error_description", description)
+	if state != "" {
+		q.Set("state", state)
+	}
+	u.RawQuery = q.Encode()
+	return c.Redirect(http.StatusFound, u.String())
+}
+
+// registeredRedirect reports whether redirectURI is one of the client's redirect URIs. Matching is exact.
+func registeredRedirect(client repository.OauthClient, redirectURI string) bool {
+	return slices.Contains(strings.Fields(client.RedirectUris), redirectURI)
+}
+
+// AuthorizeHandler starts the authorization code flow. The member is sent to Google to sign in,
+// and the request travels along in a signed state token. PKCE with S256 is required.
+func (h *Handler) AuthorizeHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	clientID := c.QueryParam("client_id")
+	redirectURI := c.QueryParam("redirect_uri")
+	state := c.QueryParam("state")
+
+	// Errors about the client or redirect_uri must not redirect, or this becomes an open redirector
+	client, err := q.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return oauthError(c, http.StatusBadRequest, "invalid_client", "Unknown client_id")
+		}
+		slog.Error("failed to get oauth client", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	audit.SetTarget(c, audit.TargetOAuthClient, client.ClientID)
+	if !registeredRedirect(client, redirectURI) {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "redirect_uri is not registered for this client")
+	}
+
+	if c.QueryParam("response_type") != "code" {
+		return redirectError(c, redirectURI, state, "unsupported_response_type", "Only the code response type is supported")
+	}
+	scopes := strings.Fields(c.QueryParam("scope"))
+	if !slices.Contains(scopes, ScopeOpenID) {
+		return redirectError(c, redirectURI, state, "invalid_scope", "The openid scope is required")
+	}
+	for _, s := range scopes {
+		if !slices.Contains(SupportedScopes, s) {
+			return redirectError(c, redirectURI, state, "invalid_scope", "Unsupported scope: "+s)
+		}
+	}
+	challenge := c.QueryParam("code_challenge")
+	if c.QueryParam("code_challenge_method") != "S256" || !validChallenge(challenge) {
+		return redirectError(c, redirectURI, state, "invalid_request", "PKCE with code_challenge_method S256 is required")
+	}
+
+	csrf, err := randomToken()
+	if err != nil {
+		slog.Error("failed to generate login cookie", "error", err)
+		return redirectError(c, redirectURI, state, "server_error", "Internal server error")
+	}
+
+	login := loginState{
+		ClientID:         client.ClientID,
+		RedirectURI:      redirectURI,
+		Scope:            strings.Join(scopes, " "),
+		State:            state,
+		Nonce:            c.QueryParam("nonce"),
+		CodeChallenge:    challenge,
+		CSRF:             hashToken(csrf),
+		RegisteredClaims: h.newRegisteredClaims("", h.config.CallbackURL(), loginLifetime),
+	}
+	loginToken, err := h.keys.Sign(&login)
+	if err != nil {
+		slog.Error("failed to sign login state", "error", err)
+		return redirectError(c, redirectURI, state, "server_error", "Internal server error")
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     csrfCookieName,
+		Value:    csrf,
+		Path:     "/oauth",
+		MaxAge:   int(loginLifetime.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.config.Issuer, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.Redirect(http.StatusFound, h.upstream.AuthCodeURL(loginToken))
+}
+
+// CallbackHandler finishes the Google sign-in. Members get an authorization code for the client;
+// anyone else is sent back with access_denied.
+func (h *Handler) CallbackHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	var login loginState
+	if err := h.parse(c.QueryParam("state"), h.config.CallbackURL(), &login); err != nil {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Invalid or expired login state")
+	}
+
+	cookie, err := c.Cookie(csrfCookieName)
+	if err != nil || subtle.ConstantTimeCompare([]byte(hashToken(cookie.Value)), []byte(login.CSRF)) != 1 {
+		return oauthError(c, http.StatusBadRequest, "invalid_request", "Login was started in a different browser")
+	}
+	c.SetCookie(&http.Cookie{Name: csrfCookieName, Path: "/oauth", MaxAge: -1})
+
+	if e := c.QueryParam("error"); e != "" {
+		return redirectError(c, login.RedirectURI, login.State, "access_denied", "Sign in with Google was cancelled")
+	}
+
+	identity, err := h.upstream.Exchange(ctx, c.QueryParam("code"))
+	if err != nil {
+		slog.Error("failed to finish google sign in", "error", err)
+		return redirectError(c, login.RedirectURI, login.State, "server_error", "Sign in with Google failed")
+	}
+
+	email, err := h.rules.MemberEmail(ctx, q, identity)
+	if err != nil {
+		var se *auth.SignInError
+		if errors.As(err, &se) {
+			return redirectError(c, login.RedirectURI, login.State, "access_denied", se.Message)
+		}
+		slog.Error("failed to check member email", "error", err)
+		return redirectError(c, login.RedirectURI, login.State, "server_error", "Internal server error")
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		slog.Error("failed to generate authorization code", "error", err)
+		return redirectError(c, login.RedirectURI, login.State, "server_error", "Internal server error")
+	}
+
+	if err := q.StoreOAuthCode(ctx, repository.StoreOAuthCodeParams{
+		CodeHash:      hashToken(code),
+		ClientID:      login.ClientID,
+		MemberEmail:   email,
+		RedirectUri:   login.RedirectURI,
+		Scope:         login.Scope,
+		Nonce:         sql.NullString{String: login.Nonce, Valid: login.Nonce != ""},
+		CodeChallenge: login.CodeChallenge,
+		ExpiresAt:     time.Now().Add(codeLifetime),
+	}); err != nil {
+		slog.Error("failed to store authorization code", "error", err)
+		return redirectError(c, login.RedirectURI, login.State, "server_error", "Internal server error")
+	}
+	// Expired codes are never exchanged, so clean them up while we are here
+	if _, err := q.DeleteExpiredOAuthCodes(ctx); err != nil {
+		slog.Error("failed to delete expired authorization codes", "error", err)
+	}
+
+	u, _ := url.Parse(login.RedirectURI)
+	params := u.Query()
+	params.Set("code", code)
+	if login.State != "" {
+		params.Set("state", login.State)
+	}
+	u.RawQuery = params.Encode()
+	return c.Redirect(http.StatusFound, u.String())
+}
+
+// authenticateClient checks the client credentials of a token request, sent with HTTP Basic
+// authentication or in the form. Public clients send only their client_id.
+func authenticateClient(client repository.OauthClient, secret string) bool {
+	if !client.ClientSecretHash.Valid {
+		return secret == ""
+	}
+	return subtle.ConstantTimeCompare([]byte(hashToken(secret)), []byte(client.ClientSecretHash.String)) == 1
+}
+
+// clientCredentials reads the client_id and client_secret of a token request.
+func clientCredentials(c echo.Context) (string, string) {
+	if id, secret, ok := c.Request().BasicAuth(); ok {
+		// RFC 6749, section 2.3.1: both are form-urlencoded before being put in the header
+		uid, err1 := url.QueryUnescape(id)
+		usecret, err2 := url.QueryUnescape(secret)
+		if err1 == nil && err2 == nil {
+			return uid, usecret
+		}
+	}
+	return c.FormValue("client_id"), c.FormValue("client_secret")
+}
+
+// TokenHandler exchanges an authorization code for an ID token and an access token.
+func (h *Handler) TokenHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	if c.FormValue("grant_type") != "authorization_code" {
+		return oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "Only the authorization_code grant is supported")
+	}
+
+	clientID, secret := clientCredentials(c)
+	client, err := q.GetOAuthClient(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		}
+		slog.Error("failed to get oauth client", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	if !authenticateClient(client, secret) {
+		return oauthError(c, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	// Codes are single use: the row is locked, checked and deleted in one transaction
+	code, err := qtx.GetOAuthCodeForUpdate(ctx, hashToken(c.FormValue("code")))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return oauthError(c, http.StatusBadRequest, "invalid_grant", "Invalid authorization code")
+		}
+		slog.Error("failed to get authorization code", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	if err := qtx.DeleteOAuthCode(ctx, code.CodeHash); err != nil {
+		slog.Error("failed to delete authorization code", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit authorization code", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+
+	switch {
+	case !code.ExpiresAt.After(time.Now()):
+		return oauthError(c, http.StatusBadRequest, "invalid_grant", "Authorization code has expired")
+	case code.ClientID != client.ClientID:
+		return oauthError(c, http.StatusBadRequest, "invalid_grant", "Authorization code was issued to another client")
+	case code.RedirectUri != c.FormValue("redirect_uri"):
+		return oauthError(c, http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request")
+	case !verifyPKCE(c.FormValue("code_verifier"), code.CodeChallenge):
+		return oauthError(c, http.StatusBadRequest, "invalid_grant", "Invalid code_verifier")
+	}
+
+	member, err := q.GetMemberInfo(ctx, code.MemberEmail)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return oauthError(c, http.StatusBadRequest, "invalid_grant", "Member no longer exists")
+		}
+		slog.Error("failed to get member info", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(int(member.ID)))
+
+	sub := subject(member)
+	idToken, err := h.keys.Sign(&IDTokenClaims{
+		Nonce:            code.Nonce.String,
+		MemberClaims:     memberClaims(member, code.Scope),
+		RegisteredClaims: h.newRegisteredClaims(sub, client.ClientID, tokenLifetime),
+	})
+	if err != nil {
+		slog.Error("failed to sign id token", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+	accessToken, err := h.keys.Sign(&AccessTokenClaims{
+		Scope:            code.Scope,
+		ClientID:         client.ClientID,
+		RegisteredClaims: h.newRegisteredClaims(sub, h.userinfoAudience(), tokenLifetime),
+	})
+	if err != nil {
+		slog.Error("failed to sign access token", "error", err)
+		return oauthError(c, http.StatusInternalServerError, "server_error", "Internal server error")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(tokenLifetime.Seconds()),
+		"id_token":     idToken,
+		"scope":        code.Scope,
+	})
+}
+
+// UserInfoHandler returns the current claims of the member an access token was issued for.
+func (h *Handler) UserInfoHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	tokenString, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+	if !ok || tokenString == "" {
+		c.Response().Header().Set("WWW-Authenticate", `Bearer`)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+	}
+
+	var claims AccessTokenClaims
+	if err := h.parse(tokenString, h.userinfoAudience(), &claims); err != nil {
+		c.Response().Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+	}
+	member, err := q.GetMemberInfoById(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		}
+		slog.Error("failed to get member info", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	return c.JSON(http.StatusOK, struct {
+		Subject string `json:"sub"`
+		MemberClaims
+	}{
+		Subject:      claims.Subject,
+		MemberClaims: memberClaims(repository.GetMemberInfoRow(member), claims.Scope),
+	})
+}
+
+// DiscoveryHandler serves the OpenID Provider metadata.
+func (h *Handler) DiscoveryHandler(c echo.Context) error {
+	iss := h.config.Issuer
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"issuer":                                iss,
+		"authorization_endpoint":                iss + "/oauth/authorize",
+		"token_endpoint":                        iss + "/oauth/token",
+		"userinfo_endpoint":                     iss + "/oauth/userinfo",
+		"jwks_uri":                              iss + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{h.keys.SigningAlg()},
+		"scopes_supported":                      SupportedScopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"iss", "sub", "aud", "exp", "iat", "nonce", "email", "email_verified", "name", "nickname",
+			"committee_id", "committee", "division_id", "division", "position_id", "position", "house",
+		},
+	})
+}
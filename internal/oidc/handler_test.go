@@ -0,0 +1,429 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDBService is a mock implementation of the database.Service interface.
+type mockDBService struct {
+	db *sql.DB
+}
+
+func (m *mockDBService) Health() map[string]string {
+	return nil
+}
+
+func (m *mockDBService) Close() error {
+	return nil
+}
+
+func (m *mockDBService) GetConnection() *sql.DB {
+	return m.db
+}
+
+// fakeUpstream stands in for Google.
+type fakeUpstream struct {
+	identity auth.Identity
+}
+
+func (f *fakeUpstream) AuthCodeURL(state string) string {
+	return "https://accounts.google.example/auth?state=" + url.QueryEscape(state)
+}
+
+func (f *fakeUpstream) Exchange(ctx context.Context, code string) (auth.Identity, error) {
+	return f.identity, nil
+}
+
+var (
+	testConfig = Config{Issuer: "https://core.api.test"}
+
+	clientColumns     = []string{"client_id", "client_secret_hash", "name", "redirect_uris", "owner_email", "created_at"}
+	codeColumns       = []string{"code_hash", "client_id", "member_email", "redirect_uri", "scope", "nonce", "code_challenge", "expires_at", "created_at"}
+	memberInfoColumns = []string{"id", "email", "full_name", "nickname", "committee_id", "committee_name", "division_id", "division_name", "position_id", "position_name", "house_name", "contact_number", "college", "program", "interests", "discord", "fb_link", "telegram"}
+)
+
+const (
+	testRedirect = "https://app.test/callback"
+	testVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXkabcdefg"
+)
+
+func testKeys(t *testing.T) *auth.KeySet {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	k, err := auth.ParseSigningKey("test", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	ks, err := auth.NewKeySet([]*auth.SigningKey{k}, "test")
+	require.NoError(t, err)
+	return ks
+}
+
+func testChallenge() string {
+	sum := sha256.Sum256([]byte(testVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func clientRow(secretHash any) *sqlmock.Rows {
+	return sqlmock.NewRows(clientColumns).AddRow("app", secretHash, "App", testRedirect+" https://app.test/other", "rnd@dlsu.edu.ph", time.Now())
+}
+
+func memberRow() *sqlmock.Rows {
+	return sqlmock.NewRows(memberInfoColumns).
+		AddRow(12345678, "test@dlsu.edu.ph", "Test Member", "Tess", "RND", "Research and Development", "TECH", "Technology", "AVP", "Assistant Vice President", "Gryffindor", nil, nil, nil, nil, nil, nil, nil)
+}
+
+func authorizeQuery(overrides map[string]string) string {
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"app"},
+		"redirect_uri":          {testRedirect},
+		"scope":                 {"openid email profile"},
+		"state":                 {"xyz"},
+		"nonce":                 {"n-0S6"},
+		"code_challenge":        {testChallenge()},
+		"code_challenge_method": {"S256"},
+	}
+	for k, val := range overrides {
+		if val == "" {
+			v.Del(k)
+		} else {
+			v.Set(k, val)
+		}
+	}
+	return "/oauth/authorize?" + v.Encode()
+}
+
+func TestAuthorizeHandler(t *testing.T) {
+	keys := testKeys(t)
+
+	run := func(t *testing.T, target string, rows *sqlmock.Rows) *httptest.ResponseRecorder {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		q := mock.ExpectQuery("SELECT (.+) FROM oauth_clients WHERE client_id = ?")
+		if rows != nil {
+			q.WillReturnRows(rows)
+		} else {
+			q.WillReturnError(sql.ErrNoRows)
+		}
+
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec)
+		h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{}, auth.DefaultSignInRules())
+		require.NoError(t, h.AuthorizeHandler(c))
+		return rec
+	}
+
+	t.Run("redirects to google with a login cookie", func(t *testing.T) {
+		rec := run(t, authorizeQuery(nil), clientRow(nil))
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Header().Get("Location"), "https://accounts.google.example/auth"))
+		assert.Contains(t, rec.Header().Get("Set-Cookie"), csrfCookieName+"=")
+	})
+
+	t.Run("unknown client is not redirected", func(t *testing.T) {
+		rec := run(t, authorizeQuery(nil), nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_client")
+	})
+
+	t.Run("unregistered redirect uri is not redirected", func(t *testing.T) {
+		rec := run(t, authorizeQuery(map[string]string{"redirect_uri": "https://evil.test/callback"}), clientRow(nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Empty(t, rec.Header().Get("Location"))
+	})
+
+	t.Run("pkce is required", func(t *testing.T) {
+		rec := run(t, authorizeQuery(map[string]string{"code_challenge_method": "plain"}), clientRow(nil))
+		assert.Equal(t, http.StatusFound, rec.Code)
+		loc, _ := url.Parse(rec.Header().Get("Location"))
+		assert.Equal(t, "invalid_request", loc.Query().Get("error"))
+		assert.Equal(t, "xyz", loc.Query().Get("state"))
+	})
+
+	t.Run("openid scope is required", func(t *testing.T) {
+		rec := run(t, authorizeQuery(map[string]string{"scope": "email"}), clientRow(nil))
+		loc, _ := url.Parse(rec.Header().Get("Location"))
+		assert.Equal(t, "invalid_scope", loc.Query().Get("error"))
+	})
+}
+
+// login runs /oauth/authorize and returns the callback request Google would send the member back with.
+func login(t *testing.T, h *Handler, db *sql.DB, mock sqlmock.Sqlmock) *http.Request {
+	mock.ExpectQuery("SELECT (.+) FROM oauth_clients WHERE client_id = ?").WillReturnRows(clientRow(nil))
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, authorizeQuery(nil), nil), rec)
+	require.NoError(t, h.AuthorizeHandler(c))
+
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/oauth/callback?code=google-code&state="+url.QueryEscape(loc.Query().Get("state")), nil)
+	for _, cookie := range rec.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	return req
+}
+
+func TestSignInFlow(t *testing.T) {
+	keys := testKeys(t)
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{identity: auth.Identity{Email: "test@dlsu.edu.ph", EmailVerified: true, HostedDomain: "dlsu.edu.ph"}}, auth.DefaultSignInRules())
+	callback := login(t, h, db, mock)
+
+	// Callback: the member is an LSCS member and gets a code
+	mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+		WithArgs("test@dlsu.edu.ph").
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("test@dlsu.edu.ph"))
+	mock.ExpectExec("INSERT INTO oauth_auth_codes").
+		WithArgs(sqlmock.AnyArg(), "app", "test@dlsu.edu.ph", testRedirect, "openid email profile", "n-0S6", testChallenge(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM oauth_auth_codes WHERE expires_at").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.CallbackHandler(e.NewContext(callback, rec)))
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "xyz", loc.Query().Get("state"))
+	code := loc.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	// Token: the code is redeemed once, with the PKCE verifier
+	mock.ExpectQuery("SELECT (.+) FROM oauth_clients WHERE client_id = ?").WillReturnRows(clientRow(nil))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM oauth_auth_codes WHERE code_hash = ?").
+		WithArgs(hashToken(code)).
+		WillReturnRows(sqlmock.NewRows(codeColumns).AddRow(hashToken(code), "app", "test@dlsu.edu.ph", testRedirect, "openid email profile", "n-0S6", testChallenge(), time.Now().Add(time.Minute), time.Now()))
+	mock.ExpectExec("DELETE FROM oauth_auth_codes WHERE code_hash = ?").
+		WithArgs(hashToken(code)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT (.+) FROM members").
+		WithArgs("test@dlsu.edu.ph").
+		WillReturnRows(memberRow())
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {testRedirect},
+		"client_id":     {"app"},
+		"code_verifier": {testVerifier},
+	}
+	req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.TokenHandler(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+		IDToken     string `json:"id_token"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+
+	var idClaims IDTokenClaims
+	_, err = jwt.ParseWithClaims(tokens.IDToken, &idClaims, keys.Key, jwt.WithAudience("app"), jwt.WithIssuer(testConfig.Issuer))
+	require.NoError(t, err)
+	assert.Equal(t, "12345678", idClaims.Subject)
+	assert.Equal(t, "n-0S6", idClaims.Nonce)
+	assert.Equal(t, "test@dlsu.edu.ph", idClaims.Email)
+	assert.Equal(t, "RND", idClaims.CommitteeID)
+	assert.Equal(t, "Assistant Vice President", idClaims.Position)
+
+	// Userinfo: the access token reads the member's claims
+	mock.ExpectQuery("SELECT (.+) FROM members").
+		WithArgs(12345678).
+		WillReturnRows(memberRow())
+
+	req = httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.AccessToken)
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.UserInfoHandler(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"committee":"Research and Development"`)
+
+	// The ID token is not an access token
+	req = httptest.NewRequest(http.MethodGet, "/oauth/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+tokens.IDToken)
+	rec = httptest.NewRecorder()
+	require.NoError(t, h.UserInfoHandler(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCallbackHandler(t *testing.T) {
+	keys := testKeys(t)
+
+	t.Run("non-members are denied", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{identity: auth.Identity{Email: "outsider@dlsu.edu.ph", EmailVerified: true, HostedDomain: "dlsu.edu.ph"}}, auth.DefaultSignInRules())
+		callback := login(t, h, db, mock)
+
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WithArgs("outsider@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery("SELECT m.email FROM member_emails e").
+			WithArgs("outsider@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.CallbackHandler(echo.New().NewContext(callback, rec)))
+		loc, _ := url.Parse(rec.Header().Get("Location"))
+		assert.Equal(t, "access_denied", loc.Query().Get("error"))
+		assert.Empty(t, loc.Query().Get("code"))
+	})
+
+	t.Run("login cookie is required", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{identity: auth.Identity{Email: "test@dlsu.edu.ph", EmailVerified: true, HostedDomain: "dlsu.edu.ph"}}, auth.DefaultSignInRules())
+		callback := login(t, h, db, mock)
+		callback.Header.Del("Cookie")
+
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.CallbackHandler(echo.New().NewContext(callback, rec)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestTokenHandler(t *testing.T) {
+	keys := testKeys(t)
+
+	post := func(h *Handler, form url.Values, basicAuth ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(form.Encode()))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		if len(basicAuth) == 2 {
+			req.SetBasicAuth(basicAuth[0], basicAuth[1])
+		}
+		rec := httptest.NewRecorder()
+		h.TokenHandler(echo.New().NewContext(req, rec))
+		return rec
+	}
+
+	t.Run("wrong client secret", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM oauth_clients WHERE client_id = ?").WillReturnRows(clientRow(hashToken("s3cret")))
+
+		h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{}, auth.DefaultSignInRules())
+		rec := post(h, url.Values{"grant_type": {"authorization_code"}, "code": {"abc"}}, "app", "wrong")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_client")
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM oauth_clients WHERE client_id = ?").WillReturnRows(clientRow(hashToken("s3cret")))
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM oauth_auth_codes WHERE code_hash = ?").
+			WillReturnRows(sqlmock.NewRows(codeColumns).AddRow(hashToken("abc"), "app", "test@dlsu.edu.ph", testRedirect, "openid", nil, testChallenge(), time.Now().Add(time.Minute), time.Now()))
+		mock.ExpectExec("DELETE FROM oauth_auth_codes WHERE code_hash = ?").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{}, auth.DefaultSignInRules())
+		rec := post(h, url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {"abc"},
+			"redirect_uri":  {testRedirect},
+			"code_verifier": {strings.Repeat("x", 43)},
+		}, "app", "s3cret")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_grant")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("unsupported grant", func(t *testing.T) {
+		h := NewHandler(&mockDBService{}, keys, testConfig, &fakeUpstream{}, auth.DefaultSignInRules())
+		rec := post(h, url.Values{"grant_type": {"password"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "unsupported_grant_type")
+	})
+}
+
+func TestCreateClientHandler(t *testing.T) {
+	keys := testKeys(t)
+
+	newContext := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/oauth/clients", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.Set(middlewares.APIKeyContextKey, repository.ApiKey{ApiKeyID: 1, MemberEmail: "admin@dlsu.edu.ph", IsAdmin: true})
+		return c, rec
+	}
+
+	t.Run("confidential client gets a secret", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec("INSERT INTO oauth_clients").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "Links", "https://links.app.dlsu-lscs.org/callback", "admin@dlsu.edu.ph").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		h := NewHandler(&mockDBService{db: db}, keys, testConfig, &fakeUpstream{}, auth.DefaultSignInRules())
+		c, rec := newContext(`{"name": "Links", "redirect_uris": ["https://links.app.dlsu-lscs.org/callback"]}`)
+
+		if assert.NoError(t, h.CreateClientHandler(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), "client_secret")
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("http redirect outside localhost", func(t *testing.T) {
+		h := NewHandler(&mockDBService{}, keys, testConfig, &fakeUpstream{}, auth.DefaultSignInRules())
+		c, rec := newContext(`{"name": "Links", "redirect_uris": ["http://links.app.dlsu-lscs.org/callback"]}`)
+
+		if assert.NoError(t, h.CreateClientHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
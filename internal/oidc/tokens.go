@@ -0,0 +1,149 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// loginState carries an authorization request through the Google sign-in as a signed token, so
+// nothing has to be stored until the member comes back.
+type loginState struct {
+	ClientID      string `json:"client_id"`
+	RedirectURI   string `json:"redirect_uri"`
+	Scope         string `json:"scope"`
+	State         string `json:"state,omitempty"`
+	Nonce         string `json:"nonce,omitempty"`
+	CodeChallenge string `json:"code_challenge"`
+	// CSRF is the hash of a cookie set on the browser that started the login, so a login cannot
+	// be finished in someone else's browser.
+	CSRF string `json:"csrf"`
+	jwt.RegisteredClaims
+}
+
+// MemberClaims describe a member. Which of them are filled in depends on the granted scopes.
+type MemberClaims struct {
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Nickname      string `json:"nickname,omitempty"`
+	CommitteeID   string `json:"committee_id,omitempty"`
+	Committee     string `json:"committee,omitempty"`
+	DivisionID    string `json:"division_id,omitempty"`
+	Division      string `json:"division,omitempty"`
+	PositionID    string `json:"position_id,omitempty"`
+	Position      string `json:"position,omitempty"`
+	House         string `json:"house,omitempty"`
+}
+
+// IDTokenClaims are the claims of an ID token.
+type IDTokenClaims struct {
+	Nonce string `json:"nonce,omitempty"`
+	MemberClaims
+	jwt.RegisteredClaims
+}
+
+// AccessTokenClaims are the claims of an access token for the userinfo endpoint.
+type AccessTokenClaims struct {
+	Scope    string `json:"scope"`
+	ClientID string `json:"client_id"`
+	jwt.RegisteredClaims
+}
+
+// memberClaims returns the claims of member that scope allows. The org claims (committee,
+// division, position and house) are always included, since they are why apps sign in with LSCS.
+func memberClaims(member repository.GetMemberInfoRow, scope string) MemberClaims {
+	scopes := strings.Fields(scope)
+	c := MemberClaims{
+		CommitteeID: member.CommitteeID.String,
+		Committee:   member.CommitteeName.String,
+		DivisionID:  member.DivisionID.String,
+		Division:    member.DivisionName.String,
+		PositionID:  member.PositionID.String,
+		Position:    member.PositionName.String,
+		House:       member.HouseName.String,
+	}
+	if slices.Contains(scopes, ScopeEmail) {
+		c.Email = member.Email
+		// Members only get in with a verified Google account
+		c.EmailVerified = true
+	}
+	if slices.Contains(scopes, ScopeProfile) {
+		c.Name = member.FullName
+		c.Nickname = member.Nickname.String
+	}
+	return c
+}
+
+// subject is the stable identifier of a member in tokens.
+func subject(member repository.GetMemberInfoRow) string {
+	return strconv.Itoa(int(member.ID))
+}
+
+// newRegisteredClaims returns the registered claims of a token issued now.
+func (h *Handler) newRegisteredClaims(sub, audience string, lifetime time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Issuer:    h.config.Issuer,
+		Subject:   sub,
+		Audience:  jwt.ClaimStrings{audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(lifetime)),
+	}
+}
+
+// parse verifies a token signed by this provider for the given audience.
+func (h *Handler) parse(tokenString, audience string, claims jwt.Claims) error {
+	_, err := jwt.ParseWithClaims(tokenString, claims, h.keys.Key,
+		jwt.WithIssuer(h.config.Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	return err
+}
+
+// userinfoAudience is the audience of access tokens, which are only good for the userinfo endpoint.
+func (h *Handler) userinfoAudience() string {
+	return h.config.Issuer + "/oauth/userinfo"
+}
+
+// randomToken returns a random URL-safe string with 256 bits of entropy.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a code, secret or cookie value.
+func hashToken(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyPKCE checks a code_verifier against the S256 code_challenge it was created with.
+func verifyPKCE(verifier, challenge string) bool {
+	// RFC 7636: 43 to 128 characters
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+// validChallenge reports whether s looks like a base64url-encoded SHA-256 hash.
+func validChallenge(s string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	return err == nil && len(b) == sha256.Size
+}
@@ -0,0 +1,82 @@
+package oidc
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+)
+
+const (
+	// codeLifetime is how long an authorization code can be exchanged for tokens.
+	codeLifetime = 5 * time.Minute
+	// tokenLifetime is how long ID and access tokens are valid.
+	tokenLifetime = time.Hour
+	// loginLifetime is how long a member has to finish signing in with Google.
+	loginLifetime = 10 * time.Minute
+)
+
+// Scopes a client can request. openid is required.
+const (
+	ScopeOpenID  = "openid"
+	ScopeEmail   = "email"
+	ScopeProfile = "profile"
+)
+
+// SupportedScopes are the scopes listed in the discovery document.
+var SupportedScopes = []string{ScopeOpenID, ScopeEmail, ScopeProfile}
+
+// Config configures the OIDC provider.
+type Config struct {
+	// Issuer is the public base URL of the API, such as https://core.api.dlsu-lscs.org.
+	Issuer string
+	// GoogleClientID and GoogleClientSecret are the OAuth client members sign in to Google with.
+	GoogleClientID     string
+	GoogleClientSecret string
+}
+
+// ConfigFromEnv reads OIDC_ISSUER, GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET. It returns false if
+// OIDC_ISSUER is not set, which leaves the provider disabled.
+func ConfigFromEnv() (Config, bool, error) {
+	cfg := Config{
+		Issuer:             strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/"),
+		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+		GoogleClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+	}
+	if cfg.Issuer == "" {
+		return cfg, false, nil
+	}
+	if cfg.GoogleClientID == "" || cfg.GoogleClientSecret == "" {
+		return cfg, false, errors.New("OIDC_ISSUER is set but GOOGLE_CLIENT_ID or GOOGLE_CLIENT_SECRET is not")
+	}
+	return cfg, true, nil
+}
+
+// CallbackURL is where Google sends members back to after they sign in.
+func (c Config) CallbackURL() string {
+	return c.Issuer + "/oauth/callback"
+}
+
+type Handler struct {
+	dbService database.Service
+	keys      *auth.KeySet
+	config    Config
+	upstream  Upstream
+	rules     auth.SignInRules
+}
+
+// NewHandler creates the OIDC provider. Tokens are signed with the signing key of keys, so
+// clients can verify them against /.well-known/jwks.json. Who can sign in is decided by rules,
+// the same as for the API's own Google sign-in.
+func NewHandler(dbService database.Service, keys *auth.KeySet, config Config, upstream Upstream, rules auth.SignInRules) *Handler {
+	return &Handler{
+		dbService: dbService,
+		keys:      keys,
+		config:    config,
+		upstream:  upstream,
+		rules:     rules,
+	}
+}
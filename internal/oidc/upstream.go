@@ -0,0 +1,64 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+// Upstream is the identity provider members actually sign in with.
+type Upstream interface {
+	// AuthCodeURL returns the URL to send the member to, which will come back to the callback with state.
+	AuthCodeURL(state string) string
+	// Exchange redeems the code the member came back with.
+	Exchange(ctx context.Context, code string) (auth.Identity, error)
+}
+
+type googleUpstream struct {
+	oauth    *oauth2.Config
+	verifier auth.IdentityVerifier
+}
+
+// NewGoogleUpstream signs members in with Google. The ID tokens Google returns are checked with
+// verifier, which must accept config.GoogleClientID as an audience.
+func NewGoogleUpstream(config Config, verifier auth.IdentityVerifier) Upstream {
+	return &googleUpstream{
+		verifier: verifier,
+		oauth: &oauth2.Config{
+			ClientID:     config.GoogleClientID,
+			ClientSecret: config.GoogleClientSecret,
+			Endpoint:     endpoints.Google,
+			RedirectURL:  config.CallbackURL(),
+			Scopes:       []string{"openid", "email"},
+		},
+	}
+}
+
+func (g *googleUpstream) AuthCodeURL(state string) string {
+	return g.oauth.AuthCodeURL(state, oauth2.SetAuthURLParam("hd", auth.DefaultHostedDomain), oauth2.SetAuthURLParam("prompt", "select_account"))
+}
+
+func (g *googleUpstream) Exchange(ctx context.Context, code string) (auth.Identity, error) {
+	token, err := g.oauth.Exchange(ctx, code)
+	if err != nil {
+		return auth.Identity{}, fmt.Errorf("failed to exchange google code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return auth.Identity{}, errors.New("google did not return an id_token")
+	}
+
+	identity, err := g.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return auth.Identity{}, fmt.Errorf("failed to validate google id_token: %w", err)
+	}
+	if identity.Email == "" {
+		return auth.Identity{}, errors.New("google id_token has no email")
+	}
+	return identity, nil
+}
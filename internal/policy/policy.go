@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// Actions that handlers ask the policy engine about.
+const (
+	// ActionRequestAPIKey is requesting a dev or production API key.
+	ActionRequestAPIKey = "api_keys:request"
+	// ActionRequestAdminKey is requesting an admin API key.
+	ActionRequestAdminKey = "api_keys:request_admin"
+	// ActionAdmin is using an admin API key. It is checked on every admin request, so an admin
+	// key stops working once its owner no longer matches the rules.
+	ActionAdmin = "admin:access"
+	// ActionViewOfficerContacts is seeing members' contact details as an officer.
+	ActionViewOfficerContacts = "members:view_officer_contacts"
+)
+
+// Subject holds the member attributes that rules are evaluated against.
+type Subject struct {
+	Email       string
+	CommitteeID string
+	DivisionID  string
+	PositionID  string
+	HouseName   string
+}
+
+// SubjectFromMember builds a Subject from a GetMemberInfo row.
+func SubjectFromMember(m repository.GetMemberInfoRow) Subject {
+	return Subject{
+		Email:       m.Email,
+		CommitteeID: helpers.NullStringToString(m.CommitteeID),
+		DivisionID:  helpers.NullStringToString(m.DivisionID),
+		PositionID:  helpers.NullStringToString(m.PositionID),
+		HouseName:   helpers.NullStringToString(m.HouseName),
+	}
+}
+
+// Rule allows an action for members matching all of its non-empty conditions.
+// Each condition matches if the member's attribute is one of the listed values.
+type Rule struct {
+	Action     string   `json:"action"`
+	Committees []string `json:"committees,omitempty"`
+	Divisions  []string `json:"divisions,omitempty"`
+	Positions  []string `json:"positions,omitempty"`
+	Houses     []string `json:"houses,omitempty"`
+}
+
+func (r Rule) matches(s Subject) bool {
+	return matchesAny(r.Committees, s.CommitteeID) &&
+		matchesAny(r.Divisions, s.DivisionID) &&
+		matchesAny(r.Positions, s.PositionID) &&
+		matchesAny(r.Houses, s.HouseName)
+}
+
+func matchesAny(values []string, v string) bool {
+	return len(values) == 0 || slices.Contains(values, v)
+}
+
+// Engine decides whether a member may perform an action. An action is allowed if any rule
+// for it matches; actions without rules are denied.
+type Engine struct {
+	rules []Rule
+}
+
+// New creates an engine from rules.
+func New(rules []Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// OfficerPositions are the positions of LSCS officers: AVP and higher.
+var OfficerPositions = []string{"PRES", "EVP", "VP", "AVP"}
+
+// DefaultRules only lets RND members who are AVP or higher request and use API keys, including
+// admin keys, and gives officers of every committee the officer view of contact details.
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Action:     ActionRequestAPIKey,
+			Committees: []string{"RND"},
+			Positions:  slices.Clone(OfficerPositions),
+		},
+		{
+			Action:     ActionRequestAdminKey,
+			Committees: []string{"RND"},
+			Positions:  slices.Clone(OfficerPositions),
+		},
+		{
+			Action:     ActionAdmin,
+			Committees: []string{"RND"},
+			Positions:  slices.Clone(OfficerPositions),
+		},
+		{
+			Action:    ActionViewOfficerContacts,
+			Positions: slices.Clone(OfficerPositions),
+		},
+	}
+}
+
+// Allows reports whether s may perform action.
+func (e *Engine) Allows(action string, s Subject) bool {
+	for _, r := range e.rules {
+		if r.Action == action && r.matches(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads rules from a JSON file of the form {"rules": [...]}.
+func Load(path string) ([]Rule, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file struct {
+		Rules []Rule `json:"rules"`
+	}
+	if err := json.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+
+	for i, r := range file.Rules {
+		if r.Action == "" {
+			return nil, fmt.Errorf("policy rule %d has no action", i)
+		}
+	}
+	return file.Rules, nil
+}
+
+// NewFromEnv creates an engine from the file in POLICY_FILE, or from DefaultRules if it is not set.
+func NewFromEnv() (*Engine, error) {
+	path := os.Getenv("POLICY_FILE")
+	if path == "" {
+		return New(DefaultRules()), nil
+	}
+
+	rules, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(rules), nil
+}
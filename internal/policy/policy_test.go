@@ -0,0 +1,69 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRules(t *testing.T) {
+	e := New(DefaultRules())
+
+	t.Run("RND AVP", func(t *testing.T) {
+		assert.True(t, e.Allows(ActionRequestAPIKey, Subject{CommitteeID: "RND", PositionID: "AVP"}))
+	})
+
+	t.Run("RND trainee", func(t *testing.T) {
+		assert.False(t, e.Allows(ActionRequestAPIKey, Subject{CommitteeID: "RND", PositionID: "CT"}))
+	})
+
+	t.Run("AVP of another committee", func(t *testing.T) {
+		assert.False(t, e.Allows(ActionRequestAPIKey, Subject{CommitteeID: "PUB", PositionID: "AVP"}))
+	})
+
+	t.Run("admin keys", func(t *testing.T) {
+		assert.True(t, e.Allows(ActionRequestAdminKey, Subject{CommitteeID: "RND", PositionID: "VP"}))
+		assert.True(t, e.Allows(ActionAdmin, Subject{CommitteeID: "RND", PositionID: "VP"}))
+		assert.False(t, e.Allows(ActionRequestAdminKey, Subject{CommitteeID: "RND", PositionID: "MEM"}))
+		assert.False(t, e.Allows(ActionAdmin, Subject{CommitteeID: "PUB", PositionID: "VP"}))
+	})
+
+	t.Run("officer contacts", func(t *testing.T) {
+		assert.True(t, e.Allows(ActionViewOfficerContacts, Subject{CommitteeID: "PUB", PositionID: "VP"}))
+		assert.False(t, e.Allows(ActionViewOfficerContacts, Subject{CommitteeID: "RND", PositionID: "MEM"}))
+	})
+
+	t.Run("action without rules", func(t *testing.T) {
+		assert.False(t, e.Allows("events:approve", Subject{CommitteeID: "RND", PositionID: "AVP"}))
+	})
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("valid file", func(t *testing.T) {
+		path := filepath.Join(dir, "policy.json")
+		os.WriteFile(path, []byte(`{"rules": [
+			{"action": "events:approve", "divisions": ["INT"], "positions": ["VP"]},
+			{"action": "events:approve", "houses": ["Gell-Mann"]}
+		]}`), 0o600)
+
+		rules, err := Load(path)
+		assert.NoError(t, err)
+
+		e := New(rules)
+		assert.True(t, e.Allows("events:approve", Subject{DivisionID: "INT", PositionID: "VP"}))
+		assert.False(t, e.Allows("events:approve", Subject{DivisionID: "EXT", PositionID: "VP"}))
+		assert.True(t, e.Allows("events:approve", Subject{HouseName: "Gell-Mann"}))
+	})
+
+	t.Run("rule without action", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.json")
+		os.WriteFile(path, []byte(`{"rules": [{"committees": ["RND"]}]}`), 0o600)
+
+		_, err := Load(path)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,193 @@
+// Package ratelimit keeps a token bucket per API key, sized by the class of the key.
+package ratelimit
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"golang.org/x/time/rate"
+)
+
+// Limit allows Requests requests per Per, with bursts of up to Requests.
+// A zero Limit means no rate limit.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// IsZero reports whether l means no rate limit.
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+func (l Limit) String() string {
+	if l.IsZero() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// ParseLimit parses a limit written as "<requests>/<duration>", such as "60/1m", or "0" for no limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "0" {
+		return Limit{}, nil
+	}
+	n, per, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<duration>", s)
+	}
+	requests, err := strconv.Atoi(n)
+	if err != nil || requests < 1 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", s)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: duration must be positive", s)
+	}
+	return Limit{Requests: requests, Per: d}, nil
+}
+
+// Limits holds the rate limit of each class of API key.
+type Limits struct {
+	Dev        Limit
+	Production Limit
+	Admin      Limit
+}
+
+// DefaultLimits returns the limits used when none are configured: 60 requests a minute for dev
+// keys, 600 a minute for production keys, and no limit for admin keys.
+func DefaultLimits() Limits {
+	return Limits{
+		Dev:        Limit{Requests: 60, Per: time.Minute},
+		Production: Limit{Requests: 600, Per: time.Minute},
+		Admin:      Limit{},
+	}
+}
+
+// LimitsFromEnv reads RATE_LIMIT_DEV, RATE_LIMIT_PRODUCTION and RATE_LIMIT_ADMIN
+// (such as "60/1m", or "0" for no limit), falling back to DefaultLimits.
+func LimitsFromEnv() Limits {
+	l := DefaultLimits()
+	l.Dev = limitFromEnv("RATE_LIMIT_DEV", l.Dev)
+	l.Production = limitFromEnv("RATE_LIMIT_PRODUCTION", l.Production)
+	l.Admin = limitFromEnv("RATE_LIMIT_ADMIN", l.Admin)
+	return l
+}
+
+// For returns the limit of the given key class.
+func (l Limits) For(class auth.KeyClass) Limit {
+	switch class {
+	case auth.KeyClassAdmin:
+		return l.Admin
+	case auth.KeyClassDev:
+		return l.Dev
+	default:
+		return l.Production
+	}
+}
+
+func limitFromEnv(name string, fallback Limit) Limit {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	l, err := ParseLimit(v)
+	if err != nil {
+		slog.Warn("invalid rate limit, using default", "env", name, "value", v, "default", fallback.String())
+		return fallback
+	}
+	return l
+}
+
+// Decision is the result of checking a request against its key's bucket.
+type Decision struct {
+	Allowed    bool
+	Limit      int           // size of the bucket, 0 when the key is not rate limited
+	Remaining  int           // requests left in the bucket
+	Reset      time.Duration // time until the bucket is full again
+	RetryAfter time.Duration // time until the next request is allowed, set when Allowed is false
+}
+
+// sweepInterval is how often buckets that have refilled completely are dropped.
+const sweepInterval = 10 * time.Minute
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter rate limits requests per API key. It is safe for concurrent use.
+type Limiter struct {
+	limits Limits
+
+	mu        sync.Mutex
+	buckets   map[int32]*bucket
+	lastSweep time.Time
+}
+
+// New creates a Limiter with the given per-class limits.
+func New(limits Limits) *Limiter {
+	return &Limiter{
+		limits:  limits,
+		buckets: make(map[int32]*bucket),
+	}
+}
+
+// Allow takes a token from the bucket of the given key, if there is one.
+func (l *Limiter) Allow(apiKeyID int32, class auth.KeyClass) Decision {
+	return l.AllowAt(apiKeyID, class, time.Now())
+}
+
+// AllowAt is Allow at the given time.
+func (l *Limiter) AllowAt(apiKeyID int32, class auth.KeyClass, now time.Time) Decision {
+	limit := l.limits.For(class)
+	if limit.IsZero() {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[apiKeyID]
+	if !ok {
+		every := limit.Per / time.Duration(limit.Requests)
+		b = &bucket{limiter: rate.NewLimiter(rate.Every(every), limit.Requests)}
+		l.buckets[apiKeyID] = b
+	}
+	b.lastSeen = now
+
+	d := Decision{Allowed: true, Limit: limit.Requests}
+	r := b.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		d.Allowed = false
+		d.RetryAfter = delay
+	}
+
+	tokens := b.limiter.TokensAt(now)
+	d.Remaining = max(int(tokens), 0)
+	d.Reset = time.Duration((float64(limit.Requests) - tokens) / float64(b.limiter.Limit()) * float64(time.Second))
+	return d
+}
+
+// sweep drops buckets that have been idle long enough to refill, since a new bucket is the same as
+// a full one. Callers must hold l.mu.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for id, b := range l.buckets {
+		if b.limiter.TokensAt(now) >= float64(b.limiter.Burst()) {
+			delete(l.buckets, id)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	l, err := ParseLimit("60/1m")
+	assert.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 60, Per: time.Minute}, l)
+
+	l, err = ParseLimit("0")
+	assert.NoError(t, err)
+	assert.True(t, l.IsZero())
+
+	for _, s := range []string{"60", "abc/1m", "0/1m", "60/soon", "60/-1m"} {
+		_, err := ParseLimit(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_DEV", "10/1s")
+	t.Setenv("RATE_LIMIT_PRODUCTION", "nonsense")
+	t.Setenv("RATE_LIMIT_ADMIN", "")
+
+	l := LimitsFromEnv()
+	assert.Equal(t, Limit{Requests: 10, Per: time.Second}, l.For(auth.KeyClassDev))
+	assert.Equal(t, DefaultLimits().Production, l.For(auth.KeyClassProduction))
+	assert.True(t, l.For(auth.KeyClassAdmin).IsZero())
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC)
+	l := New(Limits{Dev: Limit{Requests: 2, Per: time.Minute}})
+
+	t.Run("burst then reject", func(t *testing.T) {
+		d := l.AllowAt(1, auth.KeyClassDev, now)
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 2, d.Limit)
+		assert.Equal(t, 1, d.Remaining)
+
+		d = l.AllowAt(1, auth.KeyClassDev, now)
+		assert.True(t, d.Allowed)
+		assert.Equal(t, 0, d.Remaining)
+		assert.Equal(t, time.Minute, d.Reset)
+
+		d = l.AllowAt(1, auth.KeyClassDev, now)
+		assert.False(t, d.Allowed)
+		assert.Equal(t, 30*time.Second, d.RetryAfter)
+	})
+
+	t.Run("buckets are per key", func(t *testing.T) {
+		d := l.AllowAt(2, auth.KeyClassDev, now)
+		assert.True(t, d.Allowed)
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		d := l.AllowAt(1, auth.KeyClassDev, now.Add(30*time.Second))
+		assert.True(t, d.Allowed)
+	})
+
+	t.Run("unlimited class", func(t *testing.T) {
+		for range 100 {
+			d := l.AllowAt(3, auth.KeyClassAdmin, now)
+			assert.True(t, d.Allowed)
+			assert.Equal(t, 0, d.Limit)
+		}
+	})
+}
@@ -55,6 +55,91 @@ func (ns NullFileStatusesStatus) Value() (driver.Value, error) {
 	return string(ns.FileStatusesStatus), nil
 }
 
+type MemberPrivacyVisibility string
+
+const (
+	MemberPrivacyVisibilityCOMMITTEE MemberPrivacyVisibility = "COMMITTEE"
+	MemberPrivacyVisibilityOFFICERS  MemberPrivacyVisibility = "OFFICERS"
+	MemberPrivacyVisibilityNOBODY    MemberPrivacyVisibility = "NOBODY"
+)
+
+func (e *MemberPrivacyVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MemberPrivacyVisibility(s)
+	case string:
+		*e = MemberPrivacyVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MemberPrivacyVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullMemberPrivacyVisibility struct {
+	MemberPrivacyVisibility MemberPrivacyVisibility
+	Valid                   bool // Valid is true if MemberPrivacyVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMemberPrivacyVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.MemberPrivacyVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MemberPrivacyVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMemberPrivacyVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MemberPrivacyVisibility), nil
+}
+
+type MembersStatus string
+
+const (
+	MembersStatusACTIVE   MembersStatus = "ACTIVE"
+	MembersStatusINACTIVE MembersStatus = "INACTIVE"
+)
+
+func (e *MembersStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = MembersStatus(s)
+	case string:
+		*e = MembersStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for MembersStatus: %T", src)
+	}
+	return nil
+}
+
+type NullMembersStatus struct {
+	MembersStatus MembersStatus
+	Valid         bool // Valid is true if MembersStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullMembersStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.MembersStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.MembersStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullMembersStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.MembersStatus), nil
+}
+
 type ApiKey struct {
 	ApiKeyID      int32
 	MemberEmail   string
@@ -63,8 +148,44 @@ type ApiKey struct {
 	AllowedOrigin sql.NullString
 	IsDev         bool
 	IsAdmin       bool
+	Scopes        string
 	CreatedAt     sql.NullTime
 	ExpiresAt     sql.NullTime
+	LastUsedAt    sql.NullTime
+	RequestCount  int64
+}
+
+type ApiKeyDailyUsage struct {
+	ApiKeyID         int32
+	UsageDate        time.Time
+	RequestCount     int64
+	RateLimitedCount int64
+}
+
+type ApiUsageBucket struct {
+	BucketStart    time.Time
+	ApiKeyID       int32
+	Project        string
+	Route          string
+	RequestCount   int64
+	ErrorCount     int64
+	TotalLatencyMs int64
+	MaxLatencyMs   int64
+}
+
+type AuditEvent struct {
+	ID            int64
+	ActorEmail    string
+	ActorApiKeyID sql.NullInt32
+	Action        string
+	Route         string
+	TargetType    sql.NullString
+	TargetID      sql.NullString
+	Outcome       string
+	StatusCode    int32
+	ClientIp      sql.NullString
+	Details       sql.NullString
+	CreatedAt     time.Time
 }
 
 type Committee struct {
@@ -216,6 +337,66 @@ type Member struct {
 	ContactNumber sql.NullString
 	FbLink        sql.NullString
 	HouseID       sql.NullInt32
+	Status        MembersStatus
+	DeactivatedAt sql.NullTime
+}
+
+type MemberEmail struct {
+	Email     string
+	MemberID  int32
+	CreatedAt sql.NullTime
+}
+
+type MemberPrivacy struct {
+	MemberID   int32
+	Field      string
+	Visibility MemberPrivacyVisibility
+	UpdatedAt  sql.NullTime
+}
+
+type MemberSession struct {
+	ID                int64
+	MemberEmail       string
+	RefreshTokenHash  string
+	PreviousTokenHash sql.NullString
+	UserAgent         string
+	ClientIp          string
+	CreatedAt         sql.NullTime
+	RefreshedAt       sql.NullTime
+	ExpiresAt         time.Time
+	RevokedAt         sql.NullTime
+}
+
+type MemberTerm struct {
+	MemberID    int32
+	TermID      int32
+	CommitteeID sql.NullString
+	PositionID  sql.NullString
+	HouseID     sql.NullInt32
+	StartedOn   sql.NullTime
+	EndedOn     sql.NullTime
+	RecordedAt  sql.NullTime
+}
+
+type OauthAuthCode struct {
+	CodeHash      string
+	ClientID      string
+	MemberEmail   string
+	RedirectUri   string
+	Scope         string
+	Nonce         sql.NullString
+	CodeChallenge string
+	ExpiresAt     time.Time
+	CreatedAt     sql.NullTime
+}
+
+type OauthClient struct {
+	ClientID         string
+	ClientSecretHash sql.NullString
+	Name             string
+	RedirectUris     string
+	OwnerEmail       string
+	CreatedAt        sql.NullTime
 }
 
 type Position struct {
@@ -256,4 +437,6 @@ type Term struct {
 	Term      int32
 	StartYear int32
 	EndYear   int32
+	StartsOn  sql.NullTime
+	EndsOn    sql.NullTime
 }
@@ -8,8 +8,142 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const addMemberEmail = `-- name: AddMemberEmail :exec
+INSERT INTO member_emails (email, member_id) VALUES (?, ?)
+`
+
+type AddMemberEmailParams struct {
+	Email    string
+	MemberID int32
+}
+
+func (q *Queries) AddMemberEmail(ctx context.Context, arg AddMemberEmailParams) error {
+	_, err := q.db.ExecContext(ctx, addMemberEmail, arg.Email, arg.MemberID)
+	return err
+}
+
+const adminGetMember = `-- name: AdminGetMember :one
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id, status, deactivated_at
+FROM members
+WHERE id = ?
+`
+
+func (q *Queries) AdminGetMember(ctx context.Context, id int32) (Member, error) {
+	row := q.db.QueryRowContext(ctx, adminGetMember, id)
+	var i Member
+	err := row.Scan(
+		&i.ID,
+		&i.FullName,
+		&i.Nickname,
+		&i.Email,
+		&i.Telegram,
+		&i.PositionID,
+		&i.CommitteeID,
+		&i.College,
+		&i.Program,
+		&i.Discord,
+		&i.Interests,
+		&i.ContactNumber,
+		&i.FbLink,
+		&i.HouseID,
+		&i.Status,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const adminGetMemberForUpdate = `-- name: AdminGetMemberForUpdate :one
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id, status, deactivated_at
+FROM members
+WHERE id = ?
+FOR UPDATE
+`
+
+func (q *Queries) AdminGetMemberForUpdate(ctx context.Context, id int32) (Member, error) {
+	row := q.db.QueryRowContext(ctx, adminGetMemberForUpdate, id)
+	var i Member
+	err := row.Scan(
+		&i.ID,
+		&i.FullName,
+		&i.Nickname,
+		&i.Email,
+		&i.Telegram,
+		&i.PositionID,
+		&i.CommitteeID,
+		&i.College,
+		&i.Program,
+		&i.Discord,
+		&i.Interests,
+		&i.ContactNumber,
+		&i.FbLink,
+		&i.HouseID,
+		&i.Status,
+		&i.DeactivatedAt,
+	)
+	return i, err
+}
+
+const adminListMembers = `-- name: AdminListMembers :many
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id, status, deactivated_at
+FROM members
+ORDER BY id
+`
+
+func (q *Queries) AdminListMembers(ctx context.Context) ([]Member, error) {
+	rows, err := q.db.QueryContext(ctx, adminListMembers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Member
+	for rows.Next() {
+		var i Member
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Nickname,
+			&i.Email,
+			&i.Telegram,
+			&i.PositionID,
+			&i.CommitteeID,
+			&i.College,
+			&i.Program,
+			&i.Discord,
+			&i.Interests,
+			&i.ContactNumber,
+			&i.FbLink,
+			&i.HouseID,
+			&i.Status,
+			&i.DeactivatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const adminRevokeAPIKey = `-- name: AdminRevokeAPIKey :execrows
+DELETE FROM api_keys WHERE api_key_id = ?
+`
+
+func (q *Queries) AdminRevokeAPIKey(ctx context.Context, apiKeyID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, adminRevokeAPIKey, apiKeyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const checkAllowedOriginExists = `-- name: CheckAllowedOriginExists :one
 SELECT EXISTS(SELECT 1 FROM api_keys WHERE allowed_origin = ? AND is_dev = false)
 `
@@ -22,7 +156,7 @@ func (q *Queries) CheckAllowedOriginExists(ctx context.Context, allowedOrigin sq
 }
 
 const checkEmailIfMember = `-- name: CheckEmailIfMember :one
-SELECT email FROM members WHERE email = ?
+SELECT email FROM members WHERE email = ? AND status = 'ACTIVE'
 `
 
 func (q *Queries) CheckEmailIfMember(ctx context.Context, email string) (string, error) {
@@ -32,7 +166,7 @@ func (q *Queries) CheckEmailIfMember(ctx context.Context, email string) (string,
 }
 
 const checkIdIfMember = `-- name: CheckIdIfMember :one
-SELECT id FROM members WHERE id = ?
+SELECT id FROM members WHERE id = ? AND status = 'ACTIVE'
 `
 
 func (q *Queries) CheckIdIfMember(ctx context.Context, id int32) (int32, error) {
@@ -41,74 +175,94 @@ func (q *Queries) CheckIdIfMember(ctx context.Context, id int32) (int32, error)
 	return id, err
 }
 
-const deleteAPIKey = `-- name: DeleteAPIKey :exec
-DELETE FROM api_keys WHERE member_email = ? LIMIT 1
+const committeeExists = `-- name: CommitteeExists :one
+SELECT EXISTS(SELECT 1 FROM committees WHERE committee_id = ?)
 `
 
-func (q *Queries) DeleteAPIKey(ctx context.Context, memberEmail string) error {
-	_, err := q.db.ExecContext(ctx, deleteAPIKey, memberEmail)
-	return err
+func (q *Queries) CommitteeExists(ctx context.Context, committeeID string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, committeeExists, committeeID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
 }
 
-const getAPIKeyInfo = `-- name: GetAPIKeyInfo :one
-SELECT api_key_id, member_email, api_key_hash, project, allowed_origin, is_dev, is_admin, created_at, expires_at FROM api_keys WHERE api_key_hash = ?
+const countMembers = `-- name: CountMembers :one
+SELECT COUNT(*) AS total
+FROM members m
+LEFT JOIN committees c ON m.committee_id = c.committee_id
+LEFT JOIN houses h ON m.house_id = h.id
+WHERE m.status = 'ACTIVE'
+  AND (? IS NULL OR m.committee_id = ?)
+  AND (? IS NULL OR c.division_id = ?)
+  AND (? IS NULL OR m.position_id = ?)
+  AND (? IS NULL OR h.name = ?)
+  AND (? IS NULL OR m.college = ?)
+  AND (? IS NULL OR m.program = ?)
+  AND (? IS NULL
+    OR m.full_name LIKE ?
+    OR m.nickname LIKE ?
+    OR m.email LIKE ?)
 `
 
-func (q *Queries) GetAPIKeyInfo(ctx context.Context, apiKeyHash string) (ApiKey, error) {
-	row := q.db.QueryRowContext(ctx, getAPIKeyInfo, apiKeyHash)
-	var i ApiKey
-	err := row.Scan(
-		&i.ApiKeyID,
-		&i.MemberEmail,
-		&i.ApiKeyHash,
-		&i.Project,
-		&i.AllowedOrigin,
-		&i.IsDev,
-		&i.IsAdmin,
-		&i.CreatedAt,
-		&i.ExpiresAt,
-	)
-	return i, err
+type CountMembersParams struct {
+	CommitteeID sql.NullString
+	DivisionID  sql.NullString
+	PositionID  sql.NullString
+	House       sql.NullString
+	College     sql.NullString
+	Program     sql.NullString
+	Search      sql.NullString
 }
 
-const getAPIKeyInfoWithEmail = `-- name: GetAPIKeyInfoWithEmail :one
-SELECT api_key_id, member_email, api_key_hash, project, allowed_origin, is_dev, is_admin, created_at, expires_at FROM api_keys WHERE member_email = ?
-`
-
-func (q *Queries) GetAPIKeyInfoWithEmail(ctx context.Context, memberEmail string) (ApiKey, error) {
-	row := q.db.QueryRowContext(ctx, getAPIKeyInfoWithEmail, memberEmail)
-	var i ApiKey
-	err := row.Scan(
-		&i.ApiKeyID,
-		&i.MemberEmail,
-		&i.ApiKeyHash,
-		&i.Project,
-		&i.AllowedOrigin,
-		&i.IsDev,
-		&i.IsAdmin,
-		&i.CreatedAt,
-		&i.ExpiresAt,
+func (q *Queries) CountMembers(ctx context.Context, arg CountMembersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countMembers,
+		arg.CommitteeID,
+		arg.CommitteeID,
+		arg.DivisionID,
+		arg.DivisionID,
+		arg.PositionID,
+		arg.PositionID,
+		arg.House,
+		arg.House,
+		arg.College,
+		arg.College,
+		arg.Program,
+		arg.Program,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+		arg.Search,
 	)
-	return i, err
+	var total int64
+	err := row.Scan(&total)
+	return total, err
 }
 
-const getAllAPIKeyHashes = `-- name: GetAllAPIKeyHashes :many
-SELECT api_key_hash FROM api_keys
+const countMembersByCommittee = `-- name: CountMembersByCommittee :many
+SELECT committee_id, COUNT(*) AS member_count
+FROM members
+WHERE status = 'ACTIVE'
+GROUP BY committee_id
 `
 
-func (q *Queries) GetAllAPIKeyHashes(ctx context.Context) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getAllAPIKeyHashes)
+type CountMembersByCommitteeRow struct {
+	CommitteeID sql.NullString
+	MemberCount int64
+}
+
+func (q *Queries) CountMembersByCommittee(ctx context.Context) ([]CountMembersByCommitteeRow, error) {
+	rows, err := q.db.QueryContext(ctx, countMembersByCommittee)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []string
+	var items []CountMembersByCommitteeRow
 	for rows.Next() {
-		var api_key_hash string
-		if err := rows.Scan(&api_key_hash); err != nil {
+		var i CountMembersByCommitteeRow
+		if err := rows.Scan(&i.CommitteeID, &i.MemberCount); err != nil {
 			return nil, err
 		}
-		items = append(items, api_key_hash)
+		items = append(items, i)
 	}
 	if err := rows.Close(); err != nil {
 		return nil, err
@@ -119,25 +273,106 @@ func (q *Queries) GetAllAPIKeyHashes(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
-const getAllCommittees = `-- name: GetAllCommittees :many
-SELECT c.committee_id, c.committee_name, c.committee_head, c.division_id FROM committees c
+const countOverlappingTerms = `-- name: CountOverlappingTerms :one
+SELECT COUNT(*) FROM terms
+WHERE id <> ?
+  AND starts_on IS NOT NULL
+  AND starts_on <= ?
+  AND COALESCE(ends_on, starts_on) >= ?
 `
 
-func (q *Queries) GetAllCommittees(ctx context.Context) ([]Committee, error) {
-	rows, err := q.db.QueryContext(ctx, getAllCommittees)
+type CountOverlappingTermsParams struct {
+	ID       int32
+	EndsOn   sql.NullTime
+	StartsOn sql.NullTime
+}
+
+// Terms other than id whose dates overlap starts_on to ends_on. A term without an end date is
+// taken to end on the day it starts.
+func (q *Queries) CountOverlappingTerms(ctx context.Context, arg CountOverlappingTermsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countOverlappingTerms, arg.ID, arg.EndsOn, arg.StartsOn)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTermMembers = `-- name: CountTermMembers :one
+SELECT COUNT(*) AS total
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.term_id = ?
+  AND (? IS NULL OR mt.committee_id = ?)
+  AND (? IS NULL OR c.division_id = ?)
+  AND (? IS NULL OR mt.position_id = ?)
+  AND (? IS NULL OR h.name = ?)
+  AND (? IS NULL OR m.college = ?)
+  AND (? IS NULL OR m.program = ?)
+  AND (? IS NULL
+    OR m.full_name LIKE ?
+    OR m.nickname LIKE ?
+    OR m.email LIKE ?)
+`
+
+type CountTermMembersParams struct {
+	TermID      int32
+	CommitteeID sql.NullString
+	DivisionID  sql.NullString
+	PositionID  sql.NullString
+	House       sql.NullString
+	College     sql.NullString
+	Program     sql.NullString
+	Search      sql.NullString
+}
+
+func (q *Queries) CountTermMembers(ctx context.Context, arg CountTermMembersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTermMembers,
+		arg.TermID,
+		arg.CommitteeID,
+		arg.CommitteeID,
+		arg.DivisionID,
+		arg.DivisionID,
+		arg.PositionID,
+		arg.PositionID,
+		arg.House,
+		arg.House,
+		arg.College,
+		arg.College,
+		arg.Program,
+		arg.Program,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+	)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const countTermMembersByCommittee = `-- name: CountTermMembersByCommittee :many
+SELECT committee_id, COUNT(*) AS member_count
+FROM member_terms
+WHERE term_id = ?
+GROUP BY committee_id
+`
+
+type CountTermMembersByCommitteeRow struct {
+	CommitteeID sql.NullString
+	MemberCount int64
+}
+
+func (q *Queries) CountTermMembersByCommittee(ctx context.Context, termID int32) ([]CountTermMembersByCommitteeRow, error) {
+	rows, err := q.db.QueryContext(ctx, countTermMembersByCommittee, termID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Committee
+	var items []CountTermMembersByCommitteeRow
 	for rows.Next() {
-		var i Committee
-		if err := rows.Scan(
-			&i.CommitteeID,
-			&i.CommitteeName,
-			&i.CommitteeHead,
-			&i.DivisionID,
-		); err != nil {
+		var i CountTermMembersByCommitteeRow
+		if err := rows.Scan(&i.CommitteeID, &i.MemberCount); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -151,210 +386,2370 @@ func (q *Queries) GetAllCommittees(ctx context.Context) ([]Committee, error) {
 	return items, nil
 }
 
-const getAllDivisions = `-- name: GetAllDivisions :many
-SELECT d.division_id, d.division_name, d.division_head FROM divisions d
+const countTermSnapshot = `-- name: CountTermSnapshot :one
+SELECT COUNT(*) FROM member_terms WHERE term_id = ?
 `
 
-func (q *Queries) GetAllDivisions(ctx context.Context) ([]Division, error) {
-	rows, err := q.db.QueryContext(ctx, getAllDivisions)
+func (q *Queries) CountTermSnapshot(ctx context.Context, termID int32) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTermSnapshot, termID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createMember = `-- name: CreateMember :exec
+INSERT INTO members (
+    id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type CreateMemberParams struct {
+	ID            int32
+	FullName      string
+	Nickname      sql.NullString
+	Email         string
+	Telegram      sql.NullString
+	PositionID    sql.NullString
+	CommitteeID   sql.NullString
+	College       sql.NullString
+	Program       sql.NullString
+	Discord       sql.NullString
+	Interests     sql.NullString
+	ContactNumber sql.NullString
+	FbLink        sql.NullString
+	HouseID       sql.NullInt32
+}
+
+func (q *Queries) CreateMember(ctx context.Context, arg CreateMemberParams) error {
+	_, err := q.db.ExecContext(ctx, createMember,
+		arg.ID,
+		arg.FullName,
+		arg.Nickname,
+		arg.Email,
+		arg.Telegram,
+		arg.PositionID,
+		arg.CommitteeID,
+		arg.College,
+		arg.Program,
+		arg.Discord,
+		arg.Interests,
+		arg.ContactNumber,
+		arg.FbLink,
+		arg.HouseID,
+	)
+	return err
+}
+
+const createMemberSession = `-- name: CreateMemberSession :execlastid
+INSERT INTO member_sessions (member_email, refresh_token_hash, user_agent, client_ip, expires_at)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateMemberSessionParams struct {
+	MemberEmail      string
+	RefreshTokenHash string
+	UserAgent        string
+	ClientIp         string
+	ExpiresAt        time.Time
+}
+
+func (q *Queries) CreateMemberSession(ctx context.Context, arg CreateMemberSessionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createMemberSession,
+		arg.MemberEmail,
+		arg.RefreshTokenHash,
+		arg.UserAgent,
+		arg.ClientIp,
+		arg.ExpiresAt,
+	)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	defer rows.Close()
-	var items []Division
-	for rows.Next() {
-		var i Division
-		if err := rows.Scan(&i.DivisionID, &i.DivisionName, &i.DivisionHead); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+	return result.LastInsertId()
+}
+
+const createOAuthClient = `-- name: CreateOAuthClient :exec
+INSERT INTO oauth_clients (client_id, client_secret_hash, name, redirect_uris, owner_email)
+VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateOAuthClientParams struct {
+	ClientID         string
+	ClientSecretHash sql.NullString
+	Name             string
+	RedirectUris     string
+	OwnerEmail       string
+}
+
+func (q *Queries) CreateOAuthClient(ctx context.Context, arg CreateOAuthClientParams) error {
+	_, err := q.db.ExecContext(ctx, createOAuthClient,
+		arg.ClientID,
+		arg.ClientSecretHash,
+		arg.Name,
+		arg.RedirectUris,
+		arg.OwnerEmail,
+	)
+	return err
+}
+
+const createTerm = `-- name: CreateTerm :execlastid
+INSERT INTO terms (term, start_year, end_year, starts_on, ends_on) VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateTermParams struct {
+	Term      int32
+	StartYear int32
+	EndYear   int32
+	StartsOn  sql.NullTime
+	EndsOn    sql.NullTime
+}
+
+func (q *Queries) CreateTerm(ctx context.Context, arg CreateTermParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createTerm,
+		arg.Term,
+		arg.StartYear,
+		arg.EndYear,
+		arg.StartsOn,
+		arg.EndsOn,
+	)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
+	return result.LastInsertId()
+}
+
+const deactivateMember = `-- name: DeactivateMember :execrows
+UPDATE members SET status = 'INACTIVE', deactivated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = 'ACTIVE'
+`
+
+func (q *Queries) DeactivateMember(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deactivateMember, id)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected()
+}
+
+const deleteExpiredOAuthCodes = `-- name: DeleteExpiredOAuthCodes :execrows
+DELETE FROM oauth_auth_codes WHERE expires_at < CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredOAuthCodes(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredOAuthCodes)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMemberEmail = `-- name: DeleteMemberEmail :execrows
+DELETE FROM member_emails WHERE member_id = ? AND email = ?
+`
+
+type DeleteMemberEmailParams struct {
+	MemberID int32
+	Email    string
+}
+
+func (q *Queries) DeleteMemberEmail(ctx context.Context, arg DeleteMemberEmailParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteMemberEmail, arg.MemberID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMemberPrivacy = `-- name: DeleteMemberPrivacy :exec
+DELETE FROM member_privacy WHERE member_id = ? AND field = ?
+`
+
+type DeleteMemberPrivacyParams struct {
+	MemberID int32
+	Field    string
+}
+
+func (q *Queries) DeleteMemberPrivacy(ctx context.Context, arg DeleteMemberPrivacyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteMemberPrivacy, arg.MemberID, arg.Field)
+	return err
+}
+
+const deleteOAuthClient = `-- name: DeleteOAuthClient :execrows
+DELETE FROM oauth_clients WHERE client_id = ?
+`
+
+func (q *Queries) DeleteOAuthClient(ctx context.Context, clientID string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOAuthClient, clientID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOAuthCode = `-- name: DeleteOAuthCode :exec
+DELETE FROM oauth_auth_codes WHERE code_hash = ?
+`
+
+func (q *Queries) DeleteOAuthCode(ctx context.Context, codeHash string) error {
+	_, err := q.db.ExecContext(ctx, deleteOAuthCode, codeHash)
+	return err
+}
+
+const deleteTerm = `-- name: DeleteTerm :execrows
+DELETE FROM terms WHERE id = ?
+`
+
+func (q *Queries) DeleteTerm(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTerm, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTermSnapshot = `-- name: DeleteTermSnapshot :execrows
+DELETE FROM member_terms WHERE term_id = ?
+`
+
+func (q *Queries) DeleteTermSnapshot(ctx context.Context, termID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteTermSnapshot, termID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const exportMembers = `-- name: ExportMembers :many
+SELECT
+  m.id, m.email, m.full_name, m.nickname,
+  c.committee_id, c.committee_name,
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  m.contact_number, m.college, m.program,
+  m.interests, m.discord, m.fb_link, m.telegram
+FROM members m
+LEFT JOIN committees c ON m.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON m.position_id = p.position_id
+LEFT JOIN houses h ON m.house_id = h.id
+WHERE m.status = 'ACTIVE'
+  AND (? IS NULL OR m.committee_id = ?)
+  AND (? IS NULL OR c.division_id = ?)
+  AND (? IS NULL OR h.name = ?)
+ORDER BY m.full_name, m.id
+`
+
+type ExportMembersParams struct {
+	CommitteeID sql.NullString
+	DivisionID  sql.NullString
+	House       sql.NullString
+}
+
+type ExportMembersRow struct {
+	ID            int32
+	Email         string
+	FullName      string
+	Nickname      sql.NullString
+	CommitteeID   sql.NullString
+	CommitteeName sql.NullString
+	DivisionID    sql.NullString
+	DivisionName  sql.NullString
+	PositionID    sql.NullString
+	PositionName  sql.NullString
+	HouseName     sql.NullString
+	ContactNumber sql.NullString
+	College       sql.NullString
+	Program       sql.NullString
+	Interests     sql.NullString
+	Discord       sql.NullString
+	FbLink        sql.NullString
+	Telegram      sql.NullString
+}
+
+func (q *Queries) ExportMembers(ctx context.Context, arg ExportMembersParams) ([]ExportMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportMembers,
+		arg.CommitteeID,
+		arg.CommitteeID,
+		arg.DivisionID,
+		arg.DivisionID,
+		arg.House,
+		arg.House,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportMembersRow
+	for rows.Next() {
+		var i ExportMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.FullName,
+			&i.Nickname,
+			&i.CommitteeID,
+			&i.CommitteeName,
+			&i.DivisionID,
+			&i.DivisionName,
+			&i.PositionID,
+			&i.PositionName,
+			&i.HouseName,
+			&i.ContactNumber,
+			&i.College,
+			&i.Program,
+			&i.Interests,
+			&i.Discord,
+			&i.FbLink,
+			&i.Telegram,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const exportTermMembers = `-- name: ExportTermMembers :many
+SELECT
+  m.id, m.email, m.full_name, m.nickname,
+  c.committee_id, c.committee_name,
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  m.contact_number, m.college, m.program,
+  m.interests, m.discord, m.fb_link, m.telegram
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.term_id = ?
+  AND (? IS NULL OR mt.committee_id = ?)
+  AND (? IS NULL OR c.division_id = ?)
+  AND (? IS NULL OR h.name = ?)
+ORDER BY m.full_name, m.id
+`
+
+type ExportTermMembersParams struct {
+	TermID      int32
+	CommitteeID sql.NullString
+	DivisionID  sql.NullString
+	House       sql.NullString
+}
+
+type ExportTermMembersRow struct {
+	ID            int32
+	Email         string
+	FullName      string
+	Nickname      sql.NullString
+	CommitteeID   sql.NullString
+	CommitteeName sql.NullString
+	DivisionID    sql.NullString
+	DivisionName  sql.NullString
+	PositionID    sql.NullString
+	PositionName  sql.NullString
+	HouseName     sql.NullString
+	ContactNumber sql.NullString
+	College       sql.NullString
+	Program       sql.NullString
+	Interests     sql.NullString
+	Discord       sql.NullString
+	FbLink        sql.NullString
+	Telegram      sql.NullString
+}
+
+func (q *Queries) ExportTermMembers(ctx context.Context, arg ExportTermMembersParams) ([]ExportTermMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, exportTermMembers,
+		arg.TermID,
+		arg.CommitteeID,
+		arg.CommitteeID,
+		arg.DivisionID,
+		arg.DivisionID,
+		arg.House,
+		arg.House,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ExportTermMembersRow
+	for rows.Next() {
+		var i ExportTermMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.FullName,
+			&i.Nickname,
+			&i.CommitteeID,
+			&i.CommitteeName,
+			&i.DivisionID,
+			&i.DivisionName,
+			&i.PositionID,
+			&i.PositionName,
+			&i.HouseName,
+			&i.ContactNumber,
+			&i.College,
+			&i.Program,
+			&i.Interests,
+			&i.Discord,
+			&i.FbLink,
+			&i.Telegram,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAPIKeyByIDAndEmail = `-- name: GetAPIKeyByIDAndEmail :one
+SELECT api_key_id, member_email, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at
+FROM api_keys
+WHERE api_key_id = ? AND member_email = ?
+`
+
+type GetAPIKeyByIDAndEmailParams struct {
+	ApiKeyID    int32
+	MemberEmail string
+}
+
+type GetAPIKeyByIDAndEmailRow struct {
+	ApiKeyID      int32
+	MemberEmail   string
+	Project       sql.NullString
+	AllowedOrigin sql.NullString
+	IsDev         bool
+	IsAdmin       bool
+	Scopes        string
+	CreatedAt     sql.NullTime
+	ExpiresAt     sql.NullTime
+}
+
+func (q *Queries) GetAPIKeyByIDAndEmail(ctx context.Context, arg GetAPIKeyByIDAndEmailParams) (GetAPIKeyByIDAndEmailRow, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyByIDAndEmail, arg.ApiKeyID, arg.MemberEmail)
+	var i GetAPIKeyByIDAndEmailRow
+	err := row.Scan(
+		&i.ApiKeyID,
+		&i.MemberEmail,
+		&i.Project,
+		&i.AllowedOrigin,
+		&i.IsDev,
+		&i.IsAdmin,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getAPIKeyInfo = `-- name: GetAPIKeyInfo :one
+SELECT k.api_key_id, k.member_email, k.api_key_hash, k.project, k.allowed_origin, k.is_dev, k.is_admin, k.scopes, k.created_at, k.expires_at, k.last_used_at, k.request_count
+FROM api_keys k
+JOIN members m ON m.email = k.member_email
+WHERE k.api_key_hash = ? AND m.status = 'ACTIVE'
+`
+
+func (q *Queries) GetAPIKeyInfo(ctx context.Context, apiKeyHash string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyInfo, apiKeyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ApiKeyID,
+		&i.MemberEmail,
+		&i.ApiKeyHash,
+		&i.Project,
+		&i.AllowedOrigin,
+		&i.IsDev,
+		&i.IsAdmin,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RequestCount,
+	)
+	return i, err
+}
+
+const getAPIKeyInfoWithEmail = `-- name: GetAPIKeyInfoWithEmail :one
+SELECT api_key_id, member_email, api_key_hash, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at, last_used_at, request_count FROM api_keys WHERE member_email = ?
+`
+
+func (q *Queries) GetAPIKeyInfoWithEmail(ctx context.Context, memberEmail string) (ApiKey, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyInfoWithEmail, memberEmail)
+	var i ApiKey
+	err := row.Scan(
+		&i.ApiKeyID,
+		&i.MemberEmail,
+		&i.ApiKeyHash,
+		&i.Project,
+		&i.AllowedOrigin,
+		&i.IsDev,
+		&i.IsAdmin,
+		&i.Scopes,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RequestCount,
+	)
+	return i, err
+}
+
+const getAPIKeyUsage = `-- name: GetAPIKeyUsage :one
+SELECT api_key_id, member_email, project, created_at, expires_at, last_used_at, request_count
+FROM api_keys
+WHERE api_key_id = ?
+`
+
+type GetAPIKeyUsageRow struct {
+	ApiKeyID     int32
+	MemberEmail  string
+	Project      sql.NullString
+	CreatedAt    sql.NullTime
+	ExpiresAt    sql.NullTime
+	LastUsedAt   sql.NullTime
+	RequestCount int64
+}
+
+func (q *Queries) GetAPIKeyUsage(ctx context.Context, apiKeyID int32) (GetAPIKeyUsageRow, error) {
+	row := q.db.QueryRowContext(ctx, getAPIKeyUsage, apiKeyID)
+	var i GetAPIKeyUsageRow
+	err := row.Scan(
+		&i.ApiKeyID,
+		&i.MemberEmail,
+		&i.Project,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.RequestCount,
+	)
+	return i, err
+}
+
+const getActiveMemberSession = `-- name: GetActiveMemberSession :one
+SELECT id, member_email
+FROM member_sessions
+WHERE id = ? AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+`
+
+type GetActiveMemberSessionRow struct {
+	ID          int64
+	MemberEmail string
+}
+
+func (q *Queries) GetActiveMemberSession(ctx context.Context, id int64) (GetActiveMemberSessionRow, error) {
+	row := q.db.QueryRowContext(ctx, getActiveMemberSession, id)
+	var i GetActiveMemberSessionRow
+	err := row.Scan(&i.ID, &i.MemberEmail)
+	return i, err
+}
+
+const getAllAPIKeyHashes = `-- name: GetAllAPIKeyHashes :many
+SELECT api_key_hash FROM api_keys
+`
+
+func (q *Queries) GetAllAPIKeyHashes(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getAllAPIKeyHashes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var api_key_hash string
+		if err := rows.Scan(&api_key_hash); err != nil {
+			return nil, err
+		}
+		items = append(items, api_key_hash)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllCommittees = `-- name: GetAllCommittees :many
+SELECT c.committee_id, c.committee_name, c.committee_head, c.division_id FROM committees c
+`
+
+func (q *Queries) GetAllCommittees(ctx context.Context) ([]Committee, error) {
+	rows, err := q.db.QueryContext(ctx, getAllCommittees)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Committee
+	for rows.Next() {
+		var i Committee
+		if err := rows.Scan(
+			&i.CommitteeID,
+			&i.CommitteeName,
+			&i.CommitteeHead,
+			&i.DivisionID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAllDivisions = `-- name: GetAllDivisions :many
+SELECT d.division_id, d.division_name, d.division_head FROM divisions d
+`
+
+func (q *Queries) GetAllDivisions(ctx context.Context) ([]Division, error) {
+	rows, err := q.db.QueryContext(ctx, getAllDivisions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Division
+	for rows.Next() {
+		var i Division
+		if err := rows.Scan(&i.DivisionID, &i.DivisionName, &i.DivisionHead); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCurrentTerm = `-- name: GetCurrentTerm :one
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms
+WHERE starts_on <= CURDATE()
+ORDER BY starts_on DESC
+LIMIT 1
+`
+
+// The term that started most recently, so a term stays current through the break until the next one starts.
+func (q *Queries) GetCurrentTerm(ctx context.Context) (Term, error) {
+	row := q.db.QueryRowContext(ctx, getCurrentTerm)
+	var i Term
+	err := row.Scan(
+		&i.ID,
+		&i.Term,
+		&i.StartYear,
+		&i.EndYear,
+		&i.StartsOn,
+		&i.EndsOn,
+	)
+	return i, err
+}
+
+const getEmailsInAPIKey = `-- name: GetEmailsInAPIKey :many
+SELECT member_email FROM api_keys
+`
+
+func (q *Queries) GetEmailsInAPIKey(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getEmailsInAPIKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var member_email string
+		if err := rows.Scan(&member_email); err != nil {
+			return nil, err
+		}
+		items = append(items, member_email)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMemberEmailBySecondaryEmail = `-- name: GetMemberEmailBySecondaryEmail :one
+SELECT m.email
+FROM member_emails e
+JOIN members m ON m.id = e.member_id
+WHERE e.email = ? AND m.status = 'ACTIVE'
+`
+
+func (q *Queries) GetMemberEmailBySecondaryEmail(ctx context.Context, email string) (string, error) {
+	row := q.db.QueryRowContext(ctx, getMemberEmailBySecondaryEmail, email)
+	err := row.Scan(&email)
+	return email, err
+}
+
+const getMemberIDByEmail = `-- name: GetMemberIDByEmail :one
+SELECT id FROM members WHERE email = ?
+`
+
+func (q *Queries) GetMemberIDByEmail(ctx context.Context, email string) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getMemberIDByEmail, email)
+	var id int32
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getMemberInfo = `-- name: GetMemberInfo :one
+SELECT 
+  m.id, m.email, m.full_name, m.nickname, 
+  c.committee_id, c.committee_name, 
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  m.contact_number, m.college, m.program,
+  m.interests, m.discord, m.fb_link, m.telegram
+FROM members m
+LEFT JOIN committees c ON m.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON m.position_id = p.position_id
+LEFT JOIN houses h ON m.house_id = h.id
+WHERE m.email = ? AND m.status = 'ACTIVE'
+`
+
+type GetMemberInfoRow struct {
+	ID            int32
+	Email         string
+	FullName      string
+	Nickname      sql.NullString
+	CommitteeID   sql.NullString
+	CommitteeName sql.NullString
+	DivisionID    sql.NullString
+	DivisionName  sql.NullString
+	PositionID    sql.NullString
+	PositionName  sql.NullString
+	HouseName     sql.NullString
+	ContactNumber sql.NullString
+	College       sql.NullString
+	Program       sql.NullString
+	Interests     sql.NullString
+	Discord       sql.NullString
+	FbLink        sql.NullString
+	Telegram      sql.NullString
+}
+
+func (q *Queries) GetMemberInfo(ctx context.Context, email string) (GetMemberInfoRow, error) {
+	row := q.db.QueryRowContext(ctx, getMemberInfo, email)
+	var i GetMemberInfoRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.Nickname,
+		&i.CommitteeID,
+		&i.CommitteeName,
+		&i.DivisionID,
+		&i.DivisionName,
+		&i.PositionID,
+		&i.PositionName,
+		&i.HouseName,
+		&i.ContactNumber,
+		&i.College,
+		&i.Program,
+		&i.Interests,
+		&i.Discord,
+		&i.FbLink,
+		&i.Telegram,
+	)
+	return i, err
+}
+
+const getMemberInfoById = `-- name: GetMemberInfoById :one
+SELECT 
+  m.id, m.email, m.full_name, m.nickname, 
+  c.committee_id, c.committee_name, 
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  m.contact_number, m.college, m.program,
+  m.interests, m.discord, m.fb_link, m.telegram
+FROM members m
+LEFT JOIN committees c ON m.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON m.position_id = p.position_id
+LEFT JOIN houses h ON m.house_id = h.id
+WHERE m.id = ? AND m.status = 'ACTIVE'
+`
+
+type GetMemberInfoByIdRow struct {
+	ID            int32
+	Email         string
+	FullName      string
+	Nickname      sql.NullString
+	CommitteeID   sql.NullString
+	CommitteeName sql.NullString
+	DivisionID    sql.NullString
+	DivisionName  sql.NullString
+	PositionID    sql.NullString
+	PositionName  sql.NullString
+	HouseName     sql.NullString
+	ContactNumber sql.NullString
+	College       sql.NullString
+	Program       sql.NullString
+	Interests     sql.NullString
+	Discord       sql.NullString
+	FbLink        sql.NullString
+	Telegram      sql.NullString
+}
+
+func (q *Queries) GetMemberInfoById(ctx context.Context, id int32) (GetMemberInfoByIdRow, error) {
+	row := q.db.QueryRowContext(ctx, getMemberInfoById, id)
+	var i GetMemberInfoByIdRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.FullName,
+		&i.Nickname,
+		&i.CommitteeID,
+		&i.CommitteeName,
+		&i.DivisionID,
+		&i.DivisionName,
+		&i.PositionID,
+		&i.PositionName,
+		&i.HouseName,
+		&i.ContactNumber,
+		&i.College,
+		&i.Program,
+		&i.Interests,
+		&i.Discord,
+		&i.FbLink,
+		&i.Telegram,
+	)
+	return i, err
+}
+
+const getMemberPrivacy = `-- name: GetMemberPrivacy :many
+SELECT field, visibility FROM member_privacy WHERE member_id = ?
+`
+
+type GetMemberPrivacyRow struct {
+	Field      string
+	Visibility MemberPrivacyVisibility
+}
+
+func (q *Queries) GetMemberPrivacy(ctx context.Context, memberID int32) ([]GetMemberPrivacyRow, error) {
+	rows, err := q.db.QueryContext(ctx, getMemberPrivacy, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetMemberPrivacyRow
+	for rows.Next() {
+		var i GetMemberPrivacyRow
+		if err := rows.Scan(&i.Field, &i.Visibility); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMemberProfileForUpdate = `-- name: GetMemberProfileForUpdate :one
+SELECT id, nickname, telegram, discord, interests, contact_number, fb_link
+FROM members
+WHERE email = ? AND status = 'ACTIVE'
+FOR UPDATE
+`
+
+type GetMemberProfileForUpdateRow struct {
+	ID            int32
+	Nickname      sql.NullString
+	Telegram      sql.NullString
+	Discord       sql.NullString
+	Interests     sql.NullString
+	ContactNumber sql.NullString
+	FbLink        sql.NullString
+}
+
+func (q *Queries) GetMemberProfileForUpdate(ctx context.Context, email string) (GetMemberProfileForUpdateRow, error) {
+	row := q.db.QueryRowContext(ctx, getMemberProfileForUpdate, email)
+	var i GetMemberProfileForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.Nickname,
+		&i.Telegram,
+		&i.Discord,
+		&i.Interests,
+		&i.ContactNumber,
+		&i.FbLink,
+	)
+	return i, err
+}
+
+const getMemberSessionByPreviousToken = `-- name: GetMemberSessionByPreviousToken :one
+SELECT id, member_email, refresh_token_hash, previous_token_hash, user_agent, client_ip, created_at, refreshed_at, expires_at, revoked_at
+FROM member_sessions
+WHERE previous_token_hash = ?
+`
+
+func (q *Queries) GetMemberSessionByPreviousToken(ctx context.Context, previousTokenHash sql.NullString) (MemberSession, error) {
+	row := q.db.QueryRowContext(ctx, getMemberSessionByPreviousToken, previousTokenHash)
+	var i MemberSession
+	err := row.Scan(
+		&i.ID,
+		&i.MemberEmail,
+		&i.RefreshTokenHash,
+		&i.PreviousTokenHash,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.RefreshedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getMemberSessionByRefreshTokenForUpdate = `-- name: GetMemberSessionByRefreshTokenForUpdate :one
+SELECT id, member_email, refresh_token_hash, previous_token_hash, user_agent, client_ip, created_at, refreshed_at, expires_at, revoked_at
+FROM member_sessions
+WHERE refresh_token_hash = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetMemberSessionByRefreshTokenForUpdate(ctx context.Context, refreshTokenHash string) (MemberSession, error) {
+	row := q.db.QueryRowContext(ctx, getMemberSessionByRefreshTokenForUpdate, refreshTokenHash)
+	var i MemberSession
+	err := row.Scan(
+		&i.ID,
+		&i.MemberEmail,
+		&i.RefreshTokenHash,
+		&i.PreviousTokenHash,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.CreatedAt,
+		&i.RefreshedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getOAuthClient = `-- name: GetOAuthClient :one
+SELECT client_id, client_secret_hash, name, redirect_uris, owner_email, created_at
+FROM oauth_clients
+WHERE client_id = ?
+`
+
+func (q *Queries) GetOAuthClient(ctx context.Context, clientID string) (OauthClient, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthClient, clientID)
+	var i OauthClient
+	err := row.Scan(
+		&i.ClientID,
+		&i.ClientSecretHash,
+		&i.Name,
+		&i.RedirectUris,
+		&i.OwnerEmail,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getOAuthCodeForUpdate = `-- name: GetOAuthCodeForUpdate :one
+SELECT code_hash, client_id, member_email, redirect_uri, scope, nonce, code_challenge, expires_at, created_at
+FROM oauth_auth_codes
+WHERE code_hash = ?
+FOR UPDATE
+`
+
+func (q *Queries) GetOAuthCodeForUpdate(ctx context.Context, codeHash string) (OauthAuthCode, error) {
+	row := q.db.QueryRowContext(ctx, getOAuthCodeForUpdate, codeHash)
+	var i OauthAuthCode
+	err := row.Scan(
+		&i.CodeHash,
+		&i.ClientID,
+		&i.MemberEmail,
+		&i.RedirectUri,
+		&i.Scope,
+		&i.Nonce,
+		&i.CodeChallenge,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTerm = `-- name: GetTerm :one
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms WHERE id = ?
+`
+
+func (q *Queries) GetTerm(ctx context.Context, id int32) (Term, error) {
+	row := q.db.QueryRowContext(ctx, getTerm, id)
+	var i Term
+	err := row.Scan(
+		&i.ID,
+		&i.Term,
+		&i.StartYear,
+		&i.EndYear,
+		&i.StartsOn,
+		&i.EndsOn,
+	)
+	return i, err
+}
+
+const getTermForUpdate = `-- name: GetTermForUpdate :one
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms WHERE id = ? FOR UPDATE
+`
+
+func (q *Queries) GetTermForUpdate(ctx context.Context, id int32) (Term, error) {
+	row := q.db.QueryRowContext(ctx, getTermForUpdate, id)
+	var i Term
+	err := row.Scan(
+		&i.ID,
+		&i.Term,
+		&i.StartYear,
+		&i.EndYear,
+		&i.StartsOn,
+		&i.EndsOn,
+	)
+	return i, err
+}
+
+const houseExists = `-- name: HouseExists :one
+SELECT EXISTS(SELECT 1 FROM houses WHERE id = ?)
+`
+
+func (q *Queries) HouseExists(ctx context.Context, id int32) (bool, error) {
+	row := q.db.QueryRowContext(ctx, houseExists, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listAPIKeyDailyUsage = `-- name: ListAPIKeyDailyUsage :many
+SELECT usage_date, request_count, rate_limited_count
+FROM api_key_daily_usage
+WHERE api_key_id = ? AND usage_date >= ?
+ORDER BY usage_date DESC
+`
+
+type ListAPIKeyDailyUsageParams struct {
+	ApiKeyID  int32
+	UsageDate time.Time
+}
+
+type ListAPIKeyDailyUsageRow struct {
+	UsageDate        time.Time
+	RequestCount     int64
+	RateLimitedCount int64
+}
+
+func (q *Queries) ListAPIKeyDailyUsage(ctx context.Context, arg ListAPIKeyDailyUsageParams) ([]ListAPIKeyDailyUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeyDailyUsage, arg.ApiKeyID, arg.UsageDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAPIKeyDailyUsageRow
+	for rows.Next() {
+		var i ListAPIKeyDailyUsageRow
+		if err := rows.Scan(
+			&i.UsageDate,
+			&i.RequestCount,
+			&i.RateLimitedCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAPIKeysByEmail = `-- name: ListAPIKeysByEmail :many
+SELECT api_key_id, member_email, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at
+FROM api_keys
+WHERE member_email = ?
+ORDER BY created_at DESC
+`
+
+type ListAPIKeysByEmailRow struct {
+	ApiKeyID      int32
+	MemberEmail   string
+	Project       sql.NullString
+	AllowedOrigin sql.NullString
+	IsDev         bool
+	IsAdmin       bool
+	Scopes        string
+	CreatedAt     sql.NullTime
+	ExpiresAt     sql.NullTime
+}
+
+func (q *Queries) ListAPIKeysByEmail(ctx context.Context, memberEmail string) ([]ListAPIKeysByEmailRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeysByEmail, memberEmail)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAPIKeysByEmailRow
+	for rows.Next() {
+		var i ListAPIKeysByEmailRow
+		if err := rows.Scan(
+			&i.ApiKeyID,
+			&i.MemberEmail,
+			&i.Project,
+			&i.AllowedOrigin,
+			&i.IsDev,
+			&i.IsAdmin,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllAPIKeys = `-- name: ListAllAPIKeys :many
+SELECT api_key_id, member_email, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at, last_used_at, request_count
+FROM api_keys
+ORDER BY created_at DESC
+`
+
+type ListAllAPIKeysRow struct {
+	ApiKeyID      int32
+	MemberEmail   string
+	Project       sql.NullString
+	AllowedOrigin sql.NullString
+	IsDev         bool
+	IsAdmin       bool
+	Scopes        string
+	CreatedAt     sql.NullTime
+	ExpiresAt     sql.NullTime
+	LastUsedAt    sql.NullTime
+	RequestCount  int64
+}
+
+func (q *Queries) ListAllAPIKeys(ctx context.Context) ([]ListAllAPIKeysRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAllAPIKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllAPIKeysRow
+	for rows.Next() {
+		var i ListAllAPIKeysRow
+		if err := rows.Scan(
+			&i.ApiKeyID,
+			&i.MemberEmail,
+			&i.Project,
+			&i.AllowedOrigin,
+			&i.IsDev,
+			&i.IsAdmin,
+			&i.Scopes,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.RequestCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllMemberEmails = `-- name: ListAllMemberEmails :many
+SELECT email, member_id FROM member_emails
+`
+
+type ListAllMemberEmailsRow struct {
+	Email    string
+	MemberID int32
+}
+
+func (q *Queries) ListAllMemberEmails(ctx context.Context) ([]ListAllMemberEmailsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAllMemberEmails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllMemberEmailsRow
+	for rows.Next() {
+		var i ListAllMemberEmailsRow
+		if err := rows.Scan(
+			&i.Email,
+			&i.MemberID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllMemberPrivacy = `-- name: ListAllMemberPrivacy :many
+SELECT member_id, field, visibility FROM member_privacy
+`
+
+type ListAllMemberPrivacyRow struct {
+	MemberID   int32
+	Field      string
+	Visibility MemberPrivacyVisibility
+}
+
+func (q *Queries) ListAllMemberPrivacy(ctx context.Context) ([]ListAllMemberPrivacyRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAllMemberPrivacy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListAllMemberPrivacyRow
+	for rows.Next() {
+		var i ListAllMemberPrivacyRow
+		if err := rows.Scan(&i.MemberID, &i.Field, &i.Visibility); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllowedOrigins = `-- name: ListAllowedOrigins :many
+SELECT DISTINCT allowed_origin FROM api_keys WHERE allowed_origin IS NOT NULL AND is_dev = false
+`
+
+func (q *Queries) ListAllowedOrigins(ctx context.Context) ([]sql.NullString, error) {
+	rows, err := q.db.QueryContext(ctx, listAllowedOrigins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []sql.NullString
+	for rows.Next() {
+		var allowed_origin sql.NullString
+		if err := rows.Scan(&allowed_origin); err != nil {
+			return nil, err
+		}
+		items = append(items, allowed_origin)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAuditEvents = `-- name: ListAuditEvents :many
+SELECT id, actor_email, actor_api_key_id, action, route, target_type, target_id, outcome, status_code, client_ip, details, created_at
+FROM audit_events
+WHERE (? IS NULL OR actor_email = ?)
+  AND (? IS NULL OR target_type = ?)
+  AND (? IS NULL OR target_id = ?)
+  AND (? IS NULL OR created_at >= ?)
+  AND (? IS NULL OR created_at < ?)
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?
+`
+
+type ListAuditEventsParams struct {
+	ActorEmail sql.NullString
+	TargetType sql.NullString
+	TargetID   sql.NullString
+	Since      sql.NullTime
+	Until      sql.NullTime
+	Limit      int32
+	Offset     int32
+}
+
+func (q *Queries) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEvents,
+		arg.ActorEmail,
+		arg.ActorEmail,
+		arg.TargetType,
+		arg.TargetType,
+		arg.TargetID,
+		arg.TargetID,
+		arg.Since,
+		arg.Since,
+		arg.Until,
+		arg.Until,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditEvent
+	for rows.Next() {
+		var i AuditEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActorEmail,
+			&i.ActorApiKeyID,
+			&i.Action,
+			&i.Route,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Outcome,
+			&i.StatusCode,
+			&i.ClientIp,
+			&i.Details,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDailyUsage = `-- name: ListDailyUsage :many
+SELECT u.usage_date, u.api_key_id, k.member_email, k.project, u.request_count, u.rate_limited_count
+FROM api_key_daily_usage u
+LEFT JOIN api_keys k ON k.api_key_id = u.api_key_id
+WHERE u.usage_date >= ?
+ORDER BY u.usage_date DESC, u.request_count DESC
+`
+
+type ListDailyUsageRow struct {
+	UsageDate        time.Time
+	ApiKeyID         int32
+	MemberEmail      sql.NullString
+	Project          sql.NullString
+	RequestCount     int64
+	RateLimitedCount int64
+}
+
+func (q *Queries) ListDailyUsage(ctx context.Context, usageDate time.Time) ([]ListDailyUsageRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDailyUsage, usageDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDailyUsageRow
+	for rows.Next() {
+		var i ListDailyUsageRow
+		if err := rows.Scan(
+			&i.UsageDate,
+			&i.ApiKeyID,
+			&i.MemberEmail,
+			&i.Project,
+			&i.RequestCount,
+			&i.RateLimitedCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHouses = `-- name: ListHouses :many
+SELECT id, name, description FROM houses
+`
+
+func (q *Queries) ListHouses(ctx context.Context) ([]House, error) {
+	rows, err := q.db.QueryContext(ctx, listHouses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []House
+	for rows.Next() {
+		var i House
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMemberEmails = `-- name: ListMemberEmails :many
+SELECT email, created_at FROM member_emails WHERE member_id = ? ORDER BY created_at
+`
+
+type ListMemberEmailsRow struct {
+	Email     string
+	CreatedAt sql.NullTime
+}
+
+func (q *Queries) ListMemberEmails(ctx context.Context, memberID int32) ([]ListMemberEmailsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMemberEmails, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMemberEmailsRow
+	for rows.Next() {
+		var i ListMemberEmailsRow
+		if err := rows.Scan(&i.Email, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMemberTerms = `-- name: ListMemberTerms :many
+SELECT
+  t.id AS term_id, t.term, t.start_year, t.end_year, t.starts_on, t.ends_on,
+  c.committee_id, c.committee_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  mt.started_on, mt.ended_on
+FROM member_terms mt
+JOIN terms t ON mt.term_id = t.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.member_id = ?
+ORDER BY t.start_year DESC, t.term DESC
+`
+
+type ListMemberTermsRow struct {
+	TermID        int32
+	Term          int32
+	StartYear     int32
+	EndYear       int32
+	StartsOn      sql.NullTime
+	EndsOn        sql.NullTime
+	CommitteeID   sql.NullString
+	CommitteeName sql.NullString
+	PositionID    sql.NullString
+	PositionName  sql.NullString
+	HouseName     sql.NullString
+	StartedOn     sql.NullTime
+	EndedOn       sql.NullTime
+}
+
+func (q *Queries) ListMemberTerms(ctx context.Context, memberID int32) ([]ListMemberTermsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMemberTerms, memberID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMemberTermsRow
+	for rows.Next() {
+		var i ListMemberTermsRow
+		if err := rows.Scan(
+			&i.TermID,
+			&i.Term,
+			&i.StartYear,
+			&i.EndYear,
+			&i.StartsOn,
+			&i.EndsOn,
+			&i.CommitteeID,
+			&i.CommitteeName,
+			&i.PositionID,
+			&i.PositionName,
+			&i.HouseName,
+			&i.StartedOn,
+			&i.EndedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOAuthClients = `-- name: ListOAuthClients :many
+SELECT client_id, client_secret_hash IS NOT NULL AS confidential, name, redirect_uris, owner_email, created_at
+FROM oauth_clients
+ORDER BY created_at DESC
+`
+
+type ListOAuthClientsRow struct {
+	ClientID     string
+	Confidential bool
+	Name         string
+	RedirectUris string
+	OwnerEmail   string
+	CreatedAt    sql.NullTime
+}
+
+func (q *Queries) ListOAuthClients(ctx context.Context) ([]ListOAuthClientsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOAuthClients)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOAuthClientsRow
+	for rows.Next() {
+		var i ListOAuthClientsRow
+		if err := rows.Scan(
+			&i.ClientID,
+			&i.Confidential,
+			&i.Name,
+			&i.RedirectUris,
+			&i.OwnerEmail,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrgHeads = `-- name: ListOrgHeads :many
+SELECT m.id, m.full_name, m.nickname, m.position_id, p.position_name
+FROM members m
+LEFT JOIN positions p ON m.position_id = p.position_id
+WHERE m.status = 'ACTIVE'
+  AND (m.id IN (SELECT division_head FROM divisions WHERE division_head IS NOT NULL)
+    OR m.id IN (SELECT committee_head FROM committees WHERE committee_head IS NOT NULL))
+`
+
+type ListOrgHeadsRow struct {
+	ID           int32
+	FullName     string
+	Nickname     sql.NullString
+	PositionID   sql.NullString
+	PositionName sql.NullString
+}
+
+// Active members who head a division or a committee. A post whose head was deactivated shows as vacant.
+func (q *Queries) ListOrgHeads(ctx context.Context) ([]ListOrgHeadsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrgHeads)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrgHeadsRow
+	for rows.Next() {
+		var i ListOrgHeadsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Nickname,
+			&i.PositionID,
+			&i.PositionName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOrgMembers = `-- name: ListOrgMembers :many
+SELECT m.id, m.full_name, m.nickname, m.committee_id, m.position_id, p.position_name
+FROM members m
+LEFT JOIN positions p ON m.position_id = p.position_id
+WHERE m.status = 'ACTIVE'
+ORDER BY m.full_name, m.id
+`
+
+type ListOrgMembersRow struct {
+	ID           int32
+	FullName     string
+	Nickname     sql.NullString
+	CommitteeID  sql.NullString
+	PositionID   sql.NullString
+	PositionName sql.NullString
+}
+
+func (q *Queries) ListOrgMembers(ctx context.Context) ([]ListOrgMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOrgMembers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOrgMembersRow
+	for rows.Next() {
+		var i ListOrgMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Nickname,
+			&i.CommitteeID,
+			&i.PositionID,
+			&i.PositionName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPositions = `-- name: ListPositions :many
+SELECT position_id, position_name FROM positions
+`
+
+func (q *Queries) ListPositions(ctx context.Context) ([]Position, error) {
+	rows, err := q.db.QueryContext(ctx, listPositions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Position
+	for rows.Next() {
+		var i Position
+		if err := rows.Scan(
+			&i.PositionID,
+			&i.PositionName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTermOrgMembers = `-- name: ListTermOrgMembers :many
+SELECT m.id, m.full_name, m.nickname, mt.committee_id, mt.position_id, p.position_name
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+WHERE mt.term_id = ?
+ORDER BY m.full_name, m.id
+`
+
+type ListTermOrgMembersRow struct {
+	ID           int32
+	FullName     string
+	Nickname     sql.NullString
+	CommitteeID  sql.NullString
+	PositionID   sql.NullString
+	PositionName sql.NullString
+}
+
+func (q *Queries) ListTermOrgMembers(ctx context.Context, termID int32) ([]ListTermOrgMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTermOrgMembers, termID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTermOrgMembersRow
+	for rows.Next() {
+		var i ListTermOrgMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Nickname,
+			&i.CommitteeID,
+			&i.PositionID,
+			&i.PositionName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTermRoster = `-- name: ListTermRoster :many
+SELECT
+  m.id, m.full_name, m.nickname,
+  c.committee_id, c.committee_name,
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  mt.started_on, mt.ended_on
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.term_id = ?
+ORDER BY m.full_name, m.id
+`
+
+type ListTermRosterRow struct {
+	ID            int32
+	FullName      string
+	Nickname      sql.NullString
+	CommitteeID   sql.NullString
+	CommitteeName sql.NullString
+	DivisionID    sql.NullString
+	DivisionName  sql.NullString
+	PositionID    sql.NullString
+	PositionName  sql.NullString
+	HouseName     sql.NullString
+	StartedOn     sql.NullTime
+	EndedOn       sql.NullTime
+}
+
+func (q *Queries) ListTermRoster(ctx context.Context, termID int32) ([]ListTermRosterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTermRoster, termID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTermRosterRow
+	for rows.Next() {
+		var i ListTermRosterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Nickname,
+			&i.CommitteeID,
+			&i.CommitteeName,
+			&i.DivisionID,
+			&i.DivisionName,
+			&i.PositionID,
+			&i.PositionName,
+			&i.HouseName,
+			&i.StartedOn,
+			&i.EndedOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTerms = `-- name: ListTerms :many
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms
+ORDER BY start_year DESC, term DESC
+`
+
+func (q *Queries) ListTerms(ctx context.Context) ([]Term, error) {
+	rows, err := q.db.QueryContext(ctx, listTerms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Term
+	for rows.Next() {
+		var i Term
+		if err := rows.Scan(
+			&i.ID,
+			&i.Term,
+			&i.StartYear,
+			&i.EndYear,
+			&i.StartsOn,
+			&i.EndsOn,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsageByRoute = `-- name: ListUsageByRoute :many
+SELECT
+    project,
+    route,
+    CAST(COUNT(DISTINCT api_key_id) AS SIGNED) AS key_count,
+    CAST(SUM(request_count) AS SIGNED) AS request_count,
+    CAST(SUM(error_count) AS SIGNED) AS error_count,
+    CAST(SUM(total_latency_ms) AS SIGNED) AS total_latency_ms,
+    CAST(MAX(max_latency_ms) AS SIGNED) AS max_latency_ms,
+    CAST(MAX(bucket_start) AS DATETIME) AS last_seen
+FROM api_usage_buckets
+WHERE bucket_start >= ?
+  AND bucket_start < ?
+  AND (? IS NULL OR project = ?)
+  AND (? IS NULL OR route = ?)
+GROUP BY project, route
+ORDER BY request_count DESC, project, route
+`
+
+type ListUsageByRouteParams struct {
+	Since   time.Time
+	Until   time.Time
+	Project sql.NullString
+	Route   sql.NullString
+}
+
+type ListUsageByRouteRow struct {
+	Project        string
+	Route          string
+	KeyCount       int64
+	RequestCount   int64
+	ErrorCount     int64
+	TotalLatencyMs int64
+	MaxLatencyMs   int64
+	LastSeen       time.Time
+}
+
+func (q *Queries) ListUsageByRoute(ctx context.Context, arg ListUsageByRouteParams) ([]ListUsageByRouteRow, error) {
+	rows, err := q.db.QueryContext(ctx, listUsageByRoute,
+		arg.Since,
+		arg.Until,
+		arg.Project,
+		arg.Project,
+		arg.Route,
+		arg.Route,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUsageByRouteRow
+	for rows.Next() {
+		var i ListUsageByRouteRow
+		if err := rows.Scan(
+			&i.Project,
+			&i.Route,
+			&i.KeyCount,
+			&i.RequestCount,
+			&i.ErrorCount,
+			&i.TotalLatencyMs,
+			&i.MaxLatencyMs,
+			&i.LastSeen,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const positionExists = `-- name: PositionExists :one
+SELECT EXISTS(SELECT 1 FROM positions WHERE position_id = ?)
+`
+
+func (q *Queries) PositionExists(ctx context.Context, positionID string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, positionExists, positionID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const reactivateMember = `-- name: ReactivateMember :execrows
+UPDATE members SET status = 'ACTIVE', deactivated_at = NULL WHERE id = ? AND status = 'INACTIVE'
+`
+
+func (q *Queries) ReactivateMember(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reactivateMember, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordAPIKeyDailyUsage = `-- name: RecordAPIKeyDailyUsage :exec
+INSERT INTO api_key_daily_usage (api_key_id, usage_date, request_count, rate_limited_count)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    request_count = request_count + VALUES(request_count),
+    rate_limited_count = rate_limited_count + VALUES(rate_limited_count)
+`
+
+type RecordAPIKeyDailyUsageParams struct {
+	ApiKeyID         int32
+	UsageDate        time.Time
+	RequestCount     int64
+	RateLimitedCount int64
+}
+
+func (q *Queries) RecordAPIKeyDailyUsage(ctx context.Context, arg RecordAPIKeyDailyUsageParams) error {
+	_, err := q.db.ExecContext(ctx, recordAPIKeyDailyUsage,
+		arg.ApiKeyID,
+		arg.UsageDate,
+		arg.RequestCount,
+		arg.RateLimitedCount,
+	)
+	return err
+}
+
+const recordAuditEvent = `-- name: RecordAuditEvent :exec
+INSERT INTO audit_events (
+    actor_email,
+    actor_api_key_id,
+    action,
+    route,
+    target_type,
+    target_id,
+    outcome,
+    status_code,
+    client_ip,
+    details
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+)
+`
+
+type RecordAuditEventParams struct {
+	ActorEmail    string
+	ActorApiKeyID sql.NullInt32
+	Action        string
+	Route         string
+	TargetType    sql.NullString
+	TargetID      sql.NullString
+	Outcome       string
+	StatusCode    int32
+	ClientIp      sql.NullString
+	Details       sql.NullString
+}
+
+func (q *Queries) RecordAuditEvent(ctx context.Context, arg RecordAuditEventParams) error {
+	_, err := q.db.ExecContext(ctx, recordAuditEvent,
+		arg.ActorEmail,
+		arg.ActorApiKeyID,
+		arg.Action,
+		arg.Route,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Outcome,
+		arg.StatusCode,
+		arg.ClientIp,
+		arg.Details,
+	)
+	return err
+}
+
+const recordUsageBucket = `-- name: RecordUsageBucket :exec
+INSERT INTO api_usage_buckets (bucket_start, api_key_id, project, route, request_count, error_count, total_latency_ms, max_latency_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    request_count = request_count + VALUES(request_count),
+    error_count = error_count + VALUES(error_count),
+    total_latency_ms = total_latency_ms + VALUES(total_latency_ms),
+    max_latency_ms = GREATEST(max_latency_ms, VALUES(max_latency_ms))
+`
+
+type RecordUsageBucketParams struct {
+	BucketStart    time.Time
+	ApiKeyID       int32
+	Project        string
+	Route          string
+	RequestCount   int64
+	ErrorCount     int64
+	TotalLatencyMs int64
+	MaxLatencyMs   int64
+}
+
+func (q *Queries) RecordUsageBucket(ctx context.Context, arg RecordUsageBucketParams) error {
+	_, err := q.db.ExecContext(ctx, recordUsageBucket,
+		arg.BucketStart,
+		arg.ApiKeyID,
+		arg.Project,
+		arg.Route,
+		arg.RequestCount,
+		arg.ErrorCount,
+		arg.TotalLatencyMs,
+		arg.MaxLatencyMs,
+	)
+	return err
+}
+
+const revokeAPIKey = `-- name: RevokeAPIKey :execrows
+DELETE FROM api_keys WHERE api_key_id = ? AND member_email = ?
+`
+
+type RevokeAPIKeyParams struct {
+	ApiKeyID    int32
+	MemberEmail string
+}
+
+func (q *Queries) RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeAPIKey, arg.ApiKeyID, arg.MemberEmail)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getEmailsInAPIKey = `-- name: GetEmailsInAPIKey :many
-SELECT member_email FROM api_keys
+const revokeMemberSession = `-- name: RevokeMemberSession :execrows
+UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL
 `
 
-func (q *Queries) GetEmailsInAPIKey(ctx context.Context) ([]string, error) {
-	rows, err := q.db.QueryContext(ctx, getEmailsInAPIKey)
+func (q *Queries) RevokeMemberSession(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeMemberSession, id)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []string
-	for rows.Next() {
-		var member_email string
-		if err := rows.Scan(&member_email); err != nil {
-			return nil, err
-		}
-		items = append(items, member_email)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected()
+}
+
+const revokeMemberSessions = `-- name: RevokeMemberSessions :execrows
+UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE member_email = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeMemberSessions(ctx context.Context, memberEmail string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeMemberSessions, memberEmail)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
-const getMemberInfo = `-- name: GetMemberInfo :one
-SELECT 
-  m.id, m.email, m.full_name, m.nickname, 
-  c.committee_id, c.committee_name, 
-  d.division_id, d.division_name,
-  p.position_id, p.position_name,
-  h.name as house_name,
-  m.contact_number, m.college, m.program,
-  m.interests, m.discord, m.fb_link, m.telegram
-FROM members m
-LEFT JOIN committees c ON m.committee_id = c.committee_id
-LEFT JOIN divisions d ON c.division_id = d.division_id
-LEFT JOIN positions p ON m.position_id = p.position_id
-LEFT JOIN houses h ON m.house_id = h.id
-WHERE m.email = ?
+const rotateMemberSessionToken = `-- name: RotateMemberSessionToken :exec
+UPDATE member_sessions
+SET previous_token_hash = refresh_token_hash,
+    refresh_token_hash = ?,
+    refreshed_at = CURRENT_TIMESTAMP,
+    expires_at = ?
+WHERE id = ?
 `
 
-type GetMemberInfoRow struct {
-	ID            int32
-	Email         string
-	FullName      string
-	Nickname      sql.NullString
-	CommitteeID   sql.NullString
-	CommitteeName sql.NullString
-	DivisionID    sql.NullString
-	DivisionName  sql.NullString
-	PositionID    sql.NullString
-	PositionName  sql.NullString
-	HouseName     sql.NullString
-	ContactNumber sql.NullString
-	College       sql.NullString
-	Program       sql.NullString
-	Interests     sql.NullString
-	Discord       sql.NullString
-	FbLink        sql.NullString
-	Telegram      sql.NullString
+type RotateMemberSessionTokenParams struct {
+	RefreshTokenHash string
+	ExpiresAt        time.Time
+	ID               int64
 }
 
-func (q *Queries) GetMemberInfo(ctx context.Context, email string) (GetMemberInfoRow, error) {
-	row := q.db.QueryRowContext(ctx, getMemberInfo, email)
-	var i GetMemberInfoRow
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.FullName,
-		&i.Nickname,
-		&i.CommitteeID,
-		&i.CommitteeName,
-		&i.DivisionID,
-		&i.DivisionName,
-		&i.PositionID,
-		&i.PositionName,
-		&i.HouseName,
-		&i.ContactNumber,
-		&i.College,
-		&i.Program,
-		&i.Interests,
-		&i.Discord,
-		&i.FbLink,
-		&i.Telegram,
-	)
-	return i, err
+func (q *Queries) RotateMemberSessionToken(ctx context.Context, arg RotateMemberSessionTokenParams) error {
+	_, err := q.db.ExecContext(ctx, rotateMemberSessionToken, arg.RefreshTokenHash, arg.ExpiresAt, arg.ID)
+	return err
 }
 
-const getMemberInfoById = `-- name: GetMemberInfoById :one
-SELECT 
-  m.id, m.email, m.full_name, m.nickname, 
-  c.committee_id, c.committee_name, 
-  d.division_id, d.division_name,
-  p.position_id, p.position_name,
-  h.name as house_name,
-  m.contact_number, m.college, m.program,
-  m.interests, m.discord, m.fb_link, m.telegram
-FROM members m
-LEFT JOIN committees c ON m.committee_id = c.committee_id
-LEFT JOIN divisions d ON c.division_id = d.division_id
-LEFT JOIN positions p ON m.position_id = p.position_id
-LEFT JOIN houses h ON m.house_id = h.id
-WHERE m.id = ?
+const searchMembers = `-- name: SearchMembers :many
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program,
+    discord, interests, contact_number, fb_link, house_name
+FROM (
+    SELECT
+        m.id,
+        m.full_name,
+        m.nickname,
+        m.email,
+        m.telegram,
+        m.position_id,
+        m.committee_id,
+        m.college,
+        m.program,
+        m.discord,
+        m.interests,
+        m.contact_number,
+        m.fb_link,
+        h.name as house_name,
+        CASE ?
+            WHEN 'email' THEN m.email
+            WHEN 'id' THEN LPAD(m.id, 10, '0')
+            ELSE m.full_name
+        END AS sort_key
+    FROM members m
+    LEFT JOIN committees c ON m.committee_id = c.committee_id
+    LEFT JOIN houses h ON m.house_id = h.id
+    WHERE m.status = 'ACTIVE'
+      AND (? IS NULL OR m.committee_id = ?)
+      AND (? IS NULL OR c.division_id = ?)
+      AND (? IS NULL OR m.position_id = ?)
+      AND (? IS NULL OR h.name = ?)
+      AND (? IS NULL OR m.college = ?)
+      AND (? IS NULL OR m.program = ?)
+      AND (? IS NULL
+        OR m.full_name LIKE ?
+        OR m.nickname LIKE ?
+        OR m.email LIKE ?)
+) AS matched
+WHERE ? IS NULL
+    OR IF(?,
+        (sort_key, id) < (?, ?),
+        (sort_key, id) > (?, ?))
+ORDER BY
+    CASE WHEN ? THEN sort_key END DESC,
+    CASE WHEN ? THEN id END DESC,
+    sort_key,
+    id
+LIMIT ?
 `
 
-type GetMemberInfoByIdRow struct {
+type SearchMembersParams struct {
+	SortBy      string
+	CommitteeID sql.NullString
+	DivisionID  sql.NullString
+	PositionID  sql.NullString
+	House       sql.NullString
+	College     sql.NullString
+	Program     sql.NullString
+	Search      sql.NullString
+	AfterKey    sql.NullString
+	Descending  bool
+	AfterID     sql.NullInt32
+	Limit       int32
+}
+
+type SearchMembersRow struct {
 	ID            int32
-	Email         string
 	FullName      string
 	Nickname      sql.NullString
-	CommitteeID   sql.NullString
-	CommitteeName sql.NullString
-	DivisionID    sql.NullString
-	DivisionName  sql.NullString
+	Email         string
+	Telegram      sql.NullString
 	PositionID    sql.NullString
-	PositionName  sql.NullString
-	HouseName     sql.NullString
-	ContactNumber sql.NullString
+	CommitteeID   sql.NullString
 	College       sql.NullString
 	Program       sql.NullString
-	Interests     sql.NullString
 	Discord       sql.NullString
+	Interests     sql.NullString
+	ContactNumber sql.NullString
 	FbLink        sql.NullString
-	Telegram      sql.NullString
+	HouseName     sql.NullString
 }
 
-func (q *Queries) GetMemberInfoById(ctx context.Context, id int32) (GetMemberInfoByIdRow, error) {
-	row := q.db.QueryRowContext(ctx, getMemberInfoById, id)
-	var i GetMemberInfoByIdRow
-	err := row.Scan(
-		&i.ID,
-		&i.Email,
-		&i.FullName,
-		&i.Nickname,
-		&i.CommitteeID,
-		&i.CommitteeName,
-		&i.DivisionID,
-		&i.DivisionName,
-		&i.PositionID,
-		&i.PositionName,
-		&i.HouseName,
-		&i.ContactNumber,
-		&i.College,
-		&i.Program,
-		&i.Interests,
-		&i.Discord,
-		&i.FbLink,
-		&i.Telegram,
+// Members are ordered by sort_key, then id, so that a page can start after the (sort_key, id)
+// of the last member on the previous page.
+func (q *Queries) SearchMembers(ctx context.Context, arg SearchMembersParams) ([]SearchMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchMembers,
+		arg.SortBy,
+		arg.CommitteeID,
+		arg.CommitteeID,
+		arg.DivisionID,
+		arg.DivisionID,
+		arg.PositionID,
+		arg.PositionID,
+		arg.House,
+		arg.House,
+		arg.College,
+		arg.College,
+		arg.Program,
+		arg.Program,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+		arg.AfterKey,
+		arg.Descending,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.Descending,
+		arg.Descending,
+		arg.Limit,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchMembersRow
+	for rows.Next() {
+		var i SearchMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FullName,
+			&i.Nickname,
+			&i.Email,
+			&i.Telegram,
+			&i.PositionID,
+			&i.CommitteeID,
+			&i.College,
+			&i.Program,
+			&i.Discord,
+			&i.Interests,
+			&i.ContactNumber,
+			&i.FbLink,
+			&i.HouseName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const listMembers = `-- name: ListMembers :many
-SELECT
-    m.id,
-    m.full_name,
-    m.nickname,
-    m.email,
-    m.telegram,
-    m.position_id,
-    m.committee_id,
-    m.college,
-    m.program,
-    m.discord,
-    m.interests,
-    m.contact_number,
-    m.fb_link,
-    h.name as house_name
-FROM members m
-LEFT JOIN houses h ON m.house_id = h.id
-ORDER BY m.email
+const searchTermMembers = `-- name: SearchTermMembers :many
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program,
+    discord, interests, contact_number, fb_link, house_name
+FROM (
+    SELECT
+        m.id,
+        m.full_name,
+        m.nickname,
+        m.email,
+        m.telegram,
+        mt.position_id,
+        mt.committee_id,
+        m.college,
+        m.program,
+        m.discord,
+        m.interests,
+        m.contact_number,
+        m.fb_link,
+        h.name as house_name,
+        CASE ?
+            WHEN 'email' THEN m.email
+            WHEN 'id' THEN LPAD(m.id, 10, '0')
+            ELSE m.full_name
+        END AS sort_key
+    FROM member_terms mt
+    JOIN members m ON mt.member_id = m.id
+    LEFT JOIN committees c ON mt.committee_id = c.committee_id
+    LEFT JOIN houses h ON mt.house_id = h.id
+    WHERE mt.term_id = ?
+      AND (? IS NULL OR mt.committee_id = ?)
+      AND (? IS NULL OR c.division_id = ?)
+      AND (? IS NULL OR mt.position_id = ?)
+      AND (? IS NULL OR h.name = ?)
+      AND (? IS NULL OR m.college = ?)
+      AND (? IS NULL OR m.program = ?)
+      AND (? IS NULL
+        OR m.full_name LIKE ?
+        OR m.nickname LIKE ?
+        OR m.email LIKE ?)
+) AS matched
+WHERE ? IS NULL
+    OR IF(?,
+        (sort_key, id) < (?, ?),
+        (sort_key, id) > (?, ?))
+ORDER BY
+    CASE WHEN ? THEN sort_key END DESC,
+    CASE WHEN ? THEN id END DESC,
+    sort_key,
+    id
+LIMIT ?
 `
 
-type ListMembersRow struct {
+type SearchTermMembersParams struct {
+	SortBy      string
+	TermID      int32
+	CommitteeID sql.NullString
+	DivisionID  sql.NullString
+	PositionID  sql.NullString
+	House       sql.NullString
+	College     sql.NullString
+	Program     sql.NullString
+	Search      sql.NullString
+	AfterKey    sql.NullString
+	Descending  bool
+	AfterID     sql.NullInt32
+	Limit       int32
+}
+
+type SearchTermMembersRow struct {
 	ID            int32
 	FullName      string
 	Nickname      sql.NullString
@@ -371,15 +2766,45 @@ type ListMembersRow struct {
 	HouseName     sql.NullString
 }
 
-func (q *Queries) ListMembers(ctx context.Context) ([]ListMembersRow, error) {
-	rows, err := q.db.QueryContext(ctx, listMembers)
+// SearchMembers for the members recorded in a past term, with the committee, position and house
+// each one had then.
+func (q *Queries) SearchTermMembers(ctx context.Context, arg SearchTermMembersParams) ([]SearchTermMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTermMembers,
+		arg.SortBy,
+		arg.TermID,
+		arg.CommitteeID,
+		arg.CommitteeID,
+		arg.DivisionID,
+		arg.DivisionID,
+		arg.PositionID,
+		arg.PositionID,
+		arg.House,
+		arg.House,
+		arg.College,
+		arg.College,
+		arg.Program,
+		arg.Program,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+		arg.Search,
+		arg.AfterKey,
+		arg.Descending,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.AfterKey,
+		arg.AfterID,
+		arg.Descending,
+		arg.Descending,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListMembersRow
+	var items []SearchTermMembersRow
 	for rows.Next() {
-		var i ListMembersRow
+		var i SearchTermMembersRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.FullName,
@@ -409,7 +2834,44 @@ func (q *Queries) ListMembers(ctx context.Context) ([]ListMembersRow, error) {
 	return items, nil
 }
 
-const storeAPIKey = `-- name: StoreAPIKey :exec
+const setMemberPrivacy = `-- name: SetMemberPrivacy :exec
+INSERT INTO member_privacy (member_id, field, visibility) VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE visibility = VALUES(visibility)
+`
+
+type SetMemberPrivacyParams struct {
+	MemberID   int32
+	Field      string
+	Visibility MemberPrivacyVisibility
+}
+
+func (q *Queries) SetMemberPrivacy(ctx context.Context, arg SetMemberPrivacyParams) error {
+	_, err := q.db.ExecContext(ctx, setMemberPrivacy, arg.MemberID, arg.Field, arg.Visibility)
+	return err
+}
+
+const snapshotMemberTerms = `-- name: SnapshotMemberTerms :execrows
+INSERT INTO member_terms (member_id, term_id, committee_id, position_id, house_id, started_on, ended_on)
+SELECT id, ?, committee_id, position_id, house_id, ?, ?
+FROM members
+WHERE status = 'ACTIVE'
+`
+
+type SnapshotMemberTermsParams struct {
+	TermID    int32
+	StartedOn sql.NullTime
+	EndedOn   sql.NullTime
+}
+
+func (q *Queries) SnapshotMemberTerms(ctx context.Context, arg SnapshotMemberTermsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, snapshotMemberTerms, arg.TermID, arg.StartedOn, arg.EndedOn)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const storeAPIKey = `-- name: StoreAPIKey :execlastid
 INSERT INTO api_keys (
     member_email,
     api_key_hash,
@@ -417,9 +2879,10 @@ INSERT INTO api_keys (
     allowed_origin,
     is_dev,
     is_admin,
+    scopes,
     expires_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?
 )
 `
 
@@ -430,18 +2893,167 @@ type StoreAPIKeyParams struct {
 	AllowedOrigin sql.NullString
 	IsDev         bool
 	IsAdmin       bool
+	Scopes        string
 	ExpiresAt     sql.NullTime
 }
 
-func (q *Queries) StoreAPIKey(ctx context.Context, arg StoreAPIKeyParams) error {
-	_, err := q.db.ExecContext(ctx, storeAPIKey,
+func (q *Queries) StoreAPIKey(ctx context.Context, arg StoreAPIKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, storeAPIKey,
 		arg.MemberEmail,
 		arg.ApiKeyHash,
 		arg.Project,
 		arg.AllowedOrigin,
 		arg.IsDev,
 		arg.IsAdmin,
+		arg.Scopes,
+		arg.ExpiresAt,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+const storeOAuthCode = `-- name: StoreOAuthCode :exec
+INSERT INTO oauth_auth_codes (code_hash, client_id, member_email, redirect_uri, scope, nonce, code_challenge, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type StoreOAuthCodeParams struct {
+	CodeHash      string
+	ClientID      string
+	MemberEmail   string
+	RedirectUri   string
+	Scope         string
+	Nonce         sql.NullString
+	CodeChallenge string
+	ExpiresAt     time.Time
+}
+
+func (q *Queries) StoreOAuthCode(ctx context.Context, arg StoreOAuthCodeParams) error {
+	_, err := q.db.ExecContext(ctx, storeOAuthCode,
+		arg.CodeHash,
+		arg.ClientID,
+		arg.MemberEmail,
+		arg.RedirectUri,
+		arg.Scope,
+		arg.Nonce,
+		arg.CodeChallenge,
 		arg.ExpiresAt,
 	)
 	return err
 }
+
+const touchAPIKey = `-- name: TouchAPIKey :exec
+UPDATE api_keys SET last_used_at = ?, request_count = request_count + ? WHERE api_key_id = ?
+`
+
+type TouchAPIKeyParams struct {
+	LastUsedAt   sql.NullTime
+	RequestCount int64
+	ApiKeyID     int32
+}
+
+// Adds requests counted in memory to a key's request_count and sets when it was last used.
+func (q *Queries) TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, touchAPIKey, arg.LastUsedAt, arg.RequestCount, arg.ApiKeyID)
+	return err
+}
+
+const updateMember = `-- name: UpdateMember :exec
+UPDATE members
+SET full_name = ?, nickname = ?, email = ?, telegram = ?, position_id = ?, committee_id = ?, college = ?, program = ?,
+    discord = ?, interests = ?, contact_number = ?, fb_link = ?, house_id = ?
+WHERE id = ?
+`
+
+type UpdateMemberParams struct {
+	FullName      string
+	Nickname      sql.NullString
+	Email         string
+	Telegram      sql.NullString
+	PositionID    sql.NullString
+	CommitteeID   sql.NullString
+	College       sql.NullString
+	Program       sql.NullString
+	Discord       sql.NullString
+	Interests     sql.NullString
+	ContactNumber sql.NullString
+	FbLink        sql.NullString
+	HouseID       sql.NullInt32
+	ID            int32
+}
+
+func (q *Queries) UpdateMember(ctx context.Context, arg UpdateMemberParams) error {
+	_, err := q.db.ExecContext(ctx, updateMember,
+		arg.FullName,
+		arg.Nickname,
+		arg.Email,
+		arg.Telegram,
+		arg.PositionID,
+		arg.CommitteeID,
+		arg.College,
+		arg.Program,
+		arg.Discord,
+		arg.Interests,
+		arg.ContactNumber,
+		arg.FbLink,
+		arg.HouseID,
+		arg.ID,
+	)
+	return err
+}
+
+const updateMemberProfile = `-- name: UpdateMemberProfile :exec
+UPDATE members
+SET nickname = ?, telegram = ?, discord = ?, interests = ?, contact_number = ?, fb_link = ?
+WHERE id = ?
+`
+
+type UpdateMemberProfileParams struct {
+	Nickname      sql.NullString
+	Telegram      sql.NullString
+	Discord       sql.NullString
+	Interests     sql.NullString
+	ContactNumber sql.NullString
+	FbLink        sql.NullString
+	ID            int32
+}
+
+func (q *Queries) UpdateMemberProfile(ctx context.Context, arg UpdateMemberProfileParams) error {
+	_, err := q.db.ExecContext(ctx, updateMemberProfile,
+		arg.Nickname,
+		arg.Telegram,
+		arg.Discord,
+		arg.Interests,
+		arg.ContactNumber,
+		arg.FbLink,
+		arg.ID,
+	)
+	return err
+}
+
+const updateTerm = `-- name: UpdateTerm :exec
+UPDATE terms SET term = ?, start_year = ?, end_year = ?, starts_on = ?, ends_on = ? WHERE id = ?
+`
+
+type UpdateTermParams struct {
+	Term      int32
+	StartYear int32
+	EndYear   int32
+	StartsOn  sql.NullTime
+	EndsOn    sql.NullTime
+	ID        int32
+}
+
+func (q *Queries) UpdateTerm(ctx context.Context, arg UpdateTermParams) error {
+	_, err := q.db.ExecContext(ctx, updateTerm,
+		arg.Term,
+		arg.StartYear,
+		arg.EndYear,
+		arg.StartsOn,
+		arg.EndsOn,
+		arg.ID,
+	)
+	return err
+}
@@ -0,0 +1,566 @@
+package roster
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/member"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// fields are the members columns a roster can set, in the order changes are reported.
+var fields = []string{
+	"id", "full_name", "email", "nickname", "telegram", "discord", "interests", "contact_number",
+	"fb_link", "college", "program", "position_id", "committee_id", "house_id",
+}
+
+// columnAliases maps normalized column headers to the field they hold, so that exports of the
+// Google Form can be imported without renaming their columns.
+var columnAliases = map[string]string{
+	"id": "id", "idnumber": "id", "idno": "id", "studentid": "id", "studentnumber": "id",
+	"fullname": "full_name", "name": "full_name",
+	"email": "email", "emailaddress": "email", "dlsuemail": "email",
+	"nickname": "nickname", "interests": "interests",
+	"telegram": "telegram", "telegramusername": "telegram", "telegramhandle": "telegram",
+	"discord": "discord", "discordusername": "discord", "discordhandle": "discord",
+	"contactnumber": "contact_number", "mobilenumber": "contact_number", "phonenumber": "contact_number", "phone": "contact_number", "mobile": "contact_number",
+	"fblink": "fb_link", "facebook": "fb_link", "facebooklink": "fb_link", "facebookprofile": "fb_link",
+	"college": "college",
+	"program": "program", "course": "program", "degreeprogram": "program",
+	"position": "position_id", "positionid": "position_id",
+	"committee": "committee_id", "committeeid": "committee_id",
+	"house": "house_id", "houseid": "house_id", "housename": "house_id",
+}
+
+// requiredFields must have a column in every roster.
+var requiredFields = []string{"id", "full_name", "email"}
+
+// Mobile numbers read from a number-formatted spreadsheet cell lose their leading 0
+var bareMobileNumber = regexp.MustCompile(`^9\d{9}$`)
+
+// MaxRemovedShare is the largest share of the active members an import may deactivate without
+// allowMassRemoval. A roster that removes more is more likely a truncated file or the wrong sheet
+// than a real turnover.
+const MaxRemovedShare = 0.5
+
+// ErrMassRemoval is returned by Apply for a roster with no rows, or one that deactivates more
+// than MaxRemovedShare of the active members, unless mass removal is allowed.
+var ErrMassRemoval = errors.New("roster would deactivate too many members")
+
+// FileError is a problem with a roster file as a whole, such as a missing column.
+type FileError struct {
+	Message string
+}
+
+func (e *FileError) Error() string {
+	return e.Message
+}
+
+// Change is the value of a field before and after the import. Nil means empty.
+type Change struct {
+	From *string `json:"from"`
+	To   *string `json:"to"`
+}
+
+// Entry is a member the import adds, changes or deactivates.
+type Entry struct {
+	Row         int               `json:"row,omitempty"`
+	ID          int32             `json:"id"`
+	Email       string            `json:"email"`
+	FullName    string            `json:"full_name"`
+	Reactivated bool              `json:"reactivated,omitempty"`
+	Changes     map[string]Change `json:"changes,omitempty"`
+}
+
+// RowError is a problem with one row of a roster.
+type RowError struct {
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// Diff is what importing a roster would do: members to add, members to change, and active
+// members missing from the roster, who are deactivated. A roster with errors cannot be applied.
+type Diff struct {
+	New            []Entry    `json:"new"`
+	Changed        []Entry    `json:"changed"`
+	Removed        []Entry    `json:"removed"`
+	Unchanged      int        `json:"unchanged"`
+	Errors         []RowError `json:"errors"`
+	IgnoredColumns []string   `json:"ignored_columns"`
+
+	rows        int
+	active      int
+	creates     []repository.Member
+	updates     []repository.Member
+	reactivates []int32
+	deactivates []repository.Member
+}
+
+// HasErrors reports whether any row of the roster is invalid.
+func (d *Diff) HasErrors() bool {
+	return len(d.Errors) > 0
+}
+
+// MassRemoval reports whether the roster has no rows, or deactivates more than MaxRemovedShare
+// of the active members.
+func (d *Diff) MassRemoval() bool {
+	return d.rows == 0 || float64(len(d.Removed)) > MaxRemovedShare*float64(d.active)
+}
+
+// references resolves the committee, position and house of a row, given as either an id or a name.
+type references struct {
+	committees map[string]string
+	positions  map[string]string
+	houses     map[string]string
+}
+
+func loadReferences(ctx context.Context, q *repository.Queries) (*references, error) {
+	refs := &references{
+		committees: map[string]string{},
+		positions:  map[string]string{},
+		houses:     map[string]string{},
+	}
+
+	committees, err := q.GetAllCommittees(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list committees: %w", err)
+	}
+	for _, c := range committees {
+		refs.committees[strings.ToLower(c.CommitteeID)] = c.CommitteeID
+		refs.committees[strings.ToLower(c.CommitteeName)] = c.CommitteeID
+	}
+
+	positions, err := q.ListPositions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list positions: %w", err)
+	}
+	for _, p := range positions {
+		refs.positions[strings.ToLower(p.PositionID)] = p.PositionID
+		refs.positions[strings.ToLower(p.PositionName)] = p.PositionID
+	}
+
+	houses, err := q.ListHouses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list houses: %w", err)
+	}
+	for _, h := range houses {
+		id := strconv.Itoa(int(h.ID))
+		refs.houses[id] = id
+		if h.Name.Valid {
+			refs.houses[strings.ToLower(h.Name.String)] = id
+		}
+	}
+	return refs, nil
+}
+
+// normalizeHeader lowercases a column header and drops everything but letters and digits, so
+// "Full Name", "full_name" and "FULL NAME:" are the same column.
+func normalizeHeader(h string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, h)
+}
+
+// mapColumns returns the column index of each field in the header, and the columns that are not imported.
+func mapColumns(header []string) (map[string]int, []string, error) {
+	columns := map[string]int{}
+	ignored := []string{}
+	for i, h := range header {
+		field, ok := columnAliases[normalizeHeader(h)]
+		if !ok {
+			if strings.TrimSpace(h) != "" {
+				ignored = append(ignored, h)
+			}
+			continue
+		}
+		if _, dup := columns[field]; dup {
+			return nil, nil, &FileError{Message: fmt.Sprintf("more than one column holds %s", field)}
+		}
+		columns[field] = i
+	}
+	for _, field := range requiredFields {
+		if _, ok := columns[field]; !ok {
+			return nil, nil, &FileError{Message: fmt.Sprintf("missing a column for %s", field)}
+		}
+	}
+	return columns, ignored, nil
+}
+
+// parseValue validates and normalizes the value of field in a roster cell.
+func (refs *references) parseValue(field, cell string) (sql.NullString, error) {
+	cell = strings.TrimSpace(cell)
+	if cell == "" {
+		if field == "id" || field == "full_name" || field == "email" {
+			return sql.NullString{}, fmt.Errorf("%s is required", field)
+		}
+		return sql.NullString{}, nil
+	}
+
+	value := cell
+	switch field {
+	case "id":
+		// Number cells of spreadsheets may come out as 12345678.0 or 1.2345678E7
+		n, err := strconv.ParseFloat(cell, 64)
+		if err != nil || n <= 0 || n > math.MaxInt32 || n != math.Trunc(n) {
+			return sql.NullString{}, fmt.Errorf("id must be an ID number")
+		}
+		value = strconv.Itoa(int(n))
+	case "full_name", "college", "program":
+		if utf8.RuneCountInString(cell) > 255 {
+			return sql.NullString{}, fmt.Errorf("%s must be at most 255 characters", field)
+		}
+	case "email":
+		value = strings.ToLower(cell)
+		if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+			return sql.NullString{}, fmt.Errorf("invalid email")
+		}
+	case "contact_number":
+		if bareMobileNumber.MatchString(cell) {
+			cell = "0" + cell
+		}
+		fallthrough
+	case "nickname", "telegram", "discord", "interests", "fb_link":
+		v, err := member.ValidateProfileField(field, cell)
+		if err != nil {
+			return sql.NullString{}, err
+		}
+		value = v
+	case "committee_id":
+		id, ok := refs.committees[strings.ToLower(cell)]
+		if !ok {
+			return sql.NullString{}, fmt.Errorf("committee %q does not exist", cell)
+		}
+		value = id
+	case "position_id":
+		id, ok := refs.positions[strings.ToLower(cell)]
+		if !ok {
+			return sql.NullString{}, fmt.Errorf("position %q does not exist", cell)
+		}
+		value = id
+	case "house_id":
+		id, ok := refs.houses[strings.ToLower(cell)]
+		if !ok {
+			return sql.NullString{}, fmt.Errorf("house %q does not exist", cell)
+		}
+		value = id
+	}
+	return sql.NullString{String: value, Valid: true}, nil
+}
+
+// getField returns a field of m as a string.
+func getField(m repository.Member, field string) sql.NullString {
+	switch field {
+	case "id":
+		return sql.NullString{String: strconv.Itoa(int(m.ID)), Valid: true}
+	case "full_name":
+		return sql.NullString{String: m.FullName, Valid: true}
+	case "email":
+		return sql.NullString{String: m.Email, Valid: true}
+	case "nickname":
+		return m.Nickname
+	case "telegram":
+		return m.Telegram
+	case "discord":
+		return m.Discord
+	case "interests":
+		return m.Interests
+	case "contact_number":
+		return m.ContactNumber
+	case "fb_link":
+		return m.FbLink
+	case "college":
+		return m.College
+	case "program":
+		return m.Program
+	case "position_id":
+		return m.PositionID
+	case "committee_id":
+		return m.CommitteeID
+	case "house_id":
+		if !m.HouseID.Valid {
+			return sql.NullString{}
+		}
+		return sql.NullString{String: strconv.Itoa(int(m.HouseID.Int32)), Valid: true}
+	}
+	return sql.NullString{}
+}
+
+// setField sets a field of m from a value returned by parseValue.
+func setField(m *repository.Member, field string, v sql.NullString) {
+	switch field {
+	case "id":
+		id, _ := strconv.Atoi(v.String)
+		m.ID = int32(id)
+	case "full_name":
+		m.FullName = v.String
+	case "email":
+		m.Email = v.String
+	case "nickname":
+		m.Nickname = v
+	case "telegram":
+		m.Telegram = v
+	case "discord":
+		m.Discord = v
+	case "interests":
+		m.Interests = v
+	case "contact_number":
+		m.ContactNumber = v
+	case "fb_link":
+		m.FbLink = v
+	case "college":
+		m.College = v
+	case "program":
+		m.Program = v
+	case "position_id":
+		m.PositionID = v
+	case "committee_id":
+		m.CommitteeID = v
+	case "house_id":
+		id, _ := strconv.Atoi(v.String)
+		m.HouseID = sql.NullInt32{Int32: int32(id), Valid: v.Valid}
+	}
+}
+
+func ptr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+// Compare works out what importing t would change, without changing anything. Columns that are
+// not in the roster are left as they are.
+func Compare(ctx context.Context, q *repository.Queries, t *Table) (*Diff, error) {
+	columns, ignored, err := mapColumns(t.Header)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := loadReferences(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := q.AdminListMembers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members: %w", err)
+	}
+	byID := make(map[int32]repository.Member, len(members))
+	emailOwners := make(map[string]int32, len(members))
+	for _, m := range members {
+		byID[m.ID] = m
+		emailOwners[m.Email] = m.ID
+	}
+	secondary, err := q.ListAllMemberEmails(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list member emails: %w", err)
+	}
+	for _, e := range secondary {
+		emailOwners[e.Email] = e.MemberID
+	}
+
+	d := &Diff{
+		New:            []Entry{},
+		Changed:        []Entry{},
+		Removed:        []Entry{},
+		Errors:         []RowError{},
+		IgnoredColumns: ignored,
+		rows:           len(t.Rows),
+	}
+	rowOfID := map[int32]int{}
+	rowOfEmail := map[string]int{}
+
+	for _, row := range t.Rows {
+		values := map[string]sql.NullString{}
+		valid := true
+		for _, field := range fields {
+			i, ok := columns[field]
+			if !ok {
+				continue
+			}
+			cell := ""
+			if i < len(row.Cells) {
+				cell = row.Cells[i]
+			}
+			v, err := refs.parseValue(field, cell)
+			if err != nil {
+				d.Errors = append(d.Errors, RowError{Row: row.Number, Column: t.Header[i], Error: err.Error()})
+				valid = false
+				continue
+			}
+			values[field] = v
+		}
+		if !valid {
+			continue
+		}
+
+		var imported repository.Member
+		setField(&imported, "id", values["id"])
+		email := values["email"].String
+
+		if first, dup := rowOfID[imported.ID]; dup {
+			d.Errors = append(d.Errors, RowError{Row: row.Number, Error: fmt.Sprintf("id %d is also on row %d", imported.ID, first)})
+			continue
+		}
+		rowOfID[imported.ID] = row.Number
+		if first, dup := rowOfEmail[email]; dup {
+			d.Errors = append(d.Errors, RowError{Row: row.Number, Error: fmt.Sprintf("email %s is also on row %d", email, first)})
+			continue
+		}
+		rowOfEmail[email] = row.Number
+		if owner, ok := emailOwners[email]; ok && owner != imported.ID {
+			d.Errors = append(d.Errors, RowError{Row: row.Number, Error: fmt.Sprintf("email %s belongs to member %d", email, owner)})
+			continue
+		}
+
+		existing, ok := byID[imported.ID]
+		if !ok {
+			for field, v := range values {
+				setField(&imported, field, v)
+			}
+			d.creates = append(d.creates, imported)
+			d.New = append(d.New, Entry{Row: row.Number, ID: imported.ID, Email: imported.Email, FullName: imported.FullName})
+			continue
+		}
+
+		updated := existing
+		changes := map[string]Change{}
+		for _, field := range fields {
+			v, ok := values[field]
+			if !ok {
+				continue
+			}
+			if before := getField(existing, field); before != v {
+				changes[field] = Change{From: ptr(before), To: ptr(v)}
+				setField(&updated, field, v)
+			}
+		}
+		reactivated := existing.Status == repository.MembersStatusINACTIVE
+		if len(changes) == 0 && !reactivated {
+			d.Unchanged++
+			continue
+		}
+		if len(changes) == 0 {
+			changes = nil
+		} else {
+			d.updates = append(d.updates, updated)
+		}
+		if reactivated {
+			d.reactivates = append(d.reactivates, existing.ID)
+		}
+		d.Changed = append(d.Changed, Entry{
+			Row:         row.Number,
+			ID:          updated.ID,
+			Email:       updated.Email,
+			FullName:    updated.FullName,
+			Reactivated: reactivated,
+			Changes:     changes,
+		})
+	}
+
+	for _, m := range members {
+		if m.Status != repository.MembersStatusACTIVE {
+			continue
+		}
+		d.active++
+		if _, ok := rowOfID[m.ID]; ok {
+			continue
+		}
+		d.deactivates = append(d.deactivates, m)
+		d.Removed = append(d.Removed, Entry{ID: m.ID, Email: m.Email, FullName: m.FullName})
+	}
+
+	return d, nil
+}
+
+// Apply makes the changes of d using q. Run Compare and Apply in one transaction so that the whole
+// roster is imported or none of it is. A roster for which d.MassRemoval is true is only applied
+// with allowMassRemoval.
+func Apply(ctx context.Context, q *repository.Queries, d *Diff, allowMassRemoval bool) error {
+	if d.HasErrors() {
+		return fmt.Errorf("roster has %d errors", len(d.Errors))
+	}
+	if d.MassRemoval() && !allowMassRemoval {
+		return ErrMassRemoval
+	}
+
+	for _, m := range d.creates {
+		if err := q.CreateMember(ctx, repository.CreateMemberParams{
+			ID:            m.ID,
+			FullName:      m.FullName,
+			Nickname:      m.Nickname,
+			Email:         m.Email,
+			Telegram:      m.Telegram,
+			PositionID:    m.PositionID,
+			CommitteeID:   m.CommitteeID,
+			College:       m.College,
+			Program:       m.Program,
+			Discord:       m.Discord,
+			Interests:     m.Interests,
+			ContactNumber: m.ContactNumber,
+			FbLink:        m.FbLink,
+			HouseID:       m.HouseID,
+		}); err != nil {
+			return fmt.Errorf("failed to add member %d: %w", m.ID, err)
+		}
+	}
+
+	for _, id := range d.reactivates {
+		if _, err := q.ReactivateMember(ctx, id); err != nil {
+			return fmt.Errorf("failed to reactivate member %d: %w", id, err)
+		}
+	}
+
+	for _, m := range d.updates {
+		if err := q.UpdateMember(ctx, repository.UpdateMemberParams{
+			FullName:      m.FullName,
+			Nickname:      m.Nickname,
+			Email:         m.Email,
+			Telegram:      m.Telegram,
+			PositionID:    m.PositionID,
+			CommitteeID:   m.CommitteeID,
+			College:       m.College,
+			Program:       m.Program,
+			Discord:       m.Discord,
+			Interests:     m.Interests,
+			ContactNumber: m.ContactNumber,
+			FbLink:        m.FbLink,
+			HouseID:       m.HouseID,
+			ID:            m.ID,
+		}); err != nil {
+			return fmt.Errorf("failed to update member %d: %w", m.ID, err)
+		}
+	}
+
+	for _, m := range d.deactivates {
+		if _, err := q.DeactivateMember(ctx, m.ID); err != nil {
+			return fmt.Errorf("failed to deactivate member %d: %w", m.ID, err)
+		}
+		if _, err := q.RevokeMemberSessions(ctx, m.Email); err != nil {
+			return fmt.Errorf("failed to revoke sessions of member %d: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// Summary returns the number of members the import adds, changes and deactivates, for the audit log.
+func (d *Diff) Summary() map[string]any {
+	return map[string]any{
+		"new":       len(d.New),
+		"changed":   len(d.Changed),
+		"removed":   len(d.Removed),
+		"unchanged": d.Unchanged,
+	}
+}
@@ -0,0 +1,206 @@
+package roster
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+var memberColumns = []string{
+	"id", "full_name", "nickname", "email", "telegram", "position_id", "committee_id", "college", "program",
+	"discord", "interests", "contact_number", "fb_link", "house_id", "status", "deactivated_at",
+}
+
+// expectLookups sets up the queries Compare makes before reading the rows.
+func expectLookups(mock sqlmock.Sqlmock, members *sqlmock.Rows) {
+	mock.ExpectQuery("SELECT (.+) FROM committees").
+		WillReturnRows(sqlmock.NewRows([]string{"committee_id", "committee_name", "committee_head", "division_id"}).
+			AddRow("RND", "Research and Development", nil, "INT").
+			AddRow("PUB", "Publicity", nil, "EXT"))
+	mock.ExpectQuery("SELECT position_id, position_name FROM positions").
+		WillReturnRows(sqlmock.NewRows([]string{"position_id", "position_name"}).
+			AddRow("MEM", "Member").
+			AddRow("VP", "Vice President"))
+	mock.ExpectQuery("SELECT id, name, description FROM houses").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).
+			AddRow(1, "Gell-Mann", nil))
+	mock.ExpectQuery("SELECT (.+) FROM members\\s+ORDER BY id").WillReturnRows(members)
+	mock.ExpectQuery("SELECT email, member_id FROM member_emails").
+		WillReturnRows(sqlmock.NewRows([]string{"email", "member_id"}).AddRow("pedro@gmail.com", 11111113))
+}
+
+func readTable(t *testing.T, data string) *Table {
+	t.Helper()
+	table, err := Read(strings.NewReader(data), FormatCSV)
+	assert.NoError(t, err)
+	return table
+}
+
+func TestCompare(t *testing.T) {
+	t.Run("new, changed, removed", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectLookups(mock, sqlmock.NewRows(memberColumns).
+			AddRow(11111111, "Ana Santos", nil, "ana@dlsu.edu.ph", nil, "MEM", "RND", nil, nil, nil, nil, nil, nil, 1, "ACTIVE", nil).
+			AddRow(11111112, "Ben Reyes", nil, "ben@dlsu.edu.ph", nil, "MEM", "PUB", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil).
+			AddRow(11111113, "Pedro Penduko", nil, "pedro@dlsu.edu.ph", nil, "MEM", "PUB", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil).
+			AddRow(11111114, "Carla Lim", nil, "carla@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "INACTIVE", nil))
+
+		table := readTable(t, "Timestamp,ID Number,Full Name,Email,Committee,Position,House\n"+
+			"x,11111111,Ana Santos,ana@dlsu.edu.ph,RND,MEM,Gell-Mann\n"+
+			"x,11111112,Ben Reyes,ben@dlsu.edu.ph,Research and Development,Vice President,\n"+
+			"x,11111114,Carla Lim,carla@dlsu.edu.ph,,,\n"+
+			"x,12345678,Juan Dela Cruz,Juan@DLSU.edu.ph,rnd,member,1\n")
+
+		diff, err := Compare(context.Background(), repository.New(db), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Empty(t, diff.Errors)
+		assert.Equal(t, []string{"Timestamp"}, diff.IgnoredColumns)
+		assert.Equal(t, 1, diff.Unchanged)
+
+		if assert.Len(t, diff.New, 1) {
+			assert.Equal(t, int32(12345678), diff.New[0].ID)
+			assert.Equal(t, "juan@dlsu.edu.ph", diff.New[0].Email)
+		}
+		if assert.Len(t, diff.Changed, 2) {
+			ben := diff.Changed[0]
+			assert.Equal(t, int32(11111112), ben.ID)
+			assert.Equal(t, "VP", *ben.Changes["position_id"].To)
+			assert.Equal(t, "RND", *ben.Changes["committee_id"].To)
+			assert.NotContains(t, ben.Changes, "house_id")
+
+			carla := diff.Changed[1]
+			assert.True(t, carla.Reactivated)
+			assert.Nil(t, carla.Changes)
+		}
+		if assert.Len(t, diff.Removed, 1) {
+			assert.Equal(t, int32(11111113), diff.Removed[0].ID)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		// Applying writes exactly the diff
+		mock.ExpectExec("INSERT INTO members").
+			WithArgs(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, "MEM", "RND", nil, nil, nil, nil, nil, nil, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE members SET status = 'ACTIVE'").WithArgs(11111114).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE members").
+			WithArgs("Ben Reyes", nil, "ben@dlsu.edu.ph", nil, "VP", "RND", nil, nil, nil, nil, nil, nil, nil, 11111112).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE members SET status = 'INACTIVE'").WithArgs(11111113).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at").WithArgs("pedro@dlsu.edu.ph").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.False(t, diff.MassRemoval())
+		assert.NoError(t, Apply(context.Background(), repository.New(db), diff, false))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("row errors", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectLookups(mock, sqlmock.NewRows(memberColumns).
+			AddRow(11111113, "Pedro Penduko", nil, "pedro@dlsu.edu.ph", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+
+		table := readTable(t, "id,full_name,email,committee,contact_number\n"+
+			"abc,Juan,juan@dlsu.edu.ph,RND,\n"+
+			"12345678,Maria,maria@dlsu.edu.ph,XYZ,12345\n"+
+			"12345679,Jose,pedro@gmail.com,,\n"+
+			"12345680,Lia,lia@dlsu.edu.ph,,9171234567\n"+
+			"12345680,Lia Again,lia2@dlsu.edu.ph,,\n")
+
+		diff, err := Compare(context.Background(), repository.New(db), table)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.True(t, diff.HasErrors())
+		assert.Equal(t, []RowError{
+			{Row: 2, Column: "id", Error: "id must be an ID number"},
+			{Row: 3, Column: "contact_number", Error: "contact_number must be a Philippine mobile number, such as 0917 123 4567"},
+			{Row: 3, Column: "committee", Error: `committee "XYZ" does not exist`},
+			{Row: 4, Error: "email pedro@gmail.com belongs to member 11111113"},
+			{Row: 6, Error: "id 12345680 is also on row 5"},
+		}, diff.Errors)
+		if assert.Len(t, diff.New, 1) {
+			assert.Equal(t, int32(12345680), diff.New[0].ID)
+		}
+
+		assert.Error(t, Apply(context.Background(), repository.New(db), diff, false))
+	})
+
+	t.Run("fail - empty roster", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectLookups(mock, sqlmock.NewRows(memberColumns).
+			AddRow(11111111, "Ana Santos", nil, "ana@dlsu.edu.ph", nil, "AVP", "RND", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil).
+			AddRow(11111112, "Ben Reyes", nil, "ben@dlsu.edu.ph", nil, "MEM", "PUB", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+
+		diff, err := Compare(context.Background(), repository.New(db), readTable(t, "id,full_name,email\n"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, diff.Removed, 2)
+		assert.True(t, diff.MassRemoval())
+
+		// Nothing is deactivated unless mass removal is allowed
+		assert.ErrorIs(t, Apply(context.Background(), repository.New(db), diff, false), ErrMassRemoval)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		mock.ExpectExec("UPDATE members SET status = 'INACTIVE'").WithArgs(11111111).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at").WithArgs("ana@dlsu.edu.ph").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("UPDATE members SET status = 'INACTIVE'").WithArgs(11111112).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at").WithArgs("ben@dlsu.edu.ph").WillReturnResult(sqlmock.NewResult(0, 0))
+		assert.NoError(t, Apply(context.Background(), repository.New(db), diff, true))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fail - removes most members", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectLookups(mock, sqlmock.NewRows(memberColumns).
+			AddRow(11111111, "Ana Santos", nil, "ana@dlsu.edu.ph", nil, "AVP", "RND", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil).
+			AddRow(11111112, "Ben Reyes", nil, "ben@dlsu.edu.ph", nil, "MEM", "PUB", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil).
+			AddRow(11111113, "Pedro Penduko", nil, "pedro@dlsu.edu.ph", nil, "MEM", "PUB", nil, nil, nil, nil, nil, nil, nil, "ACTIVE", nil))
+
+		diff, err := Compare(context.Background(), repository.New(db), readTable(t, "id,full_name,email\n11111112,Ben Reyes,ben@dlsu.edu.ph\n"))
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Len(t, diff.Removed, 2)
+		assert.True(t, diff.MassRemoval())
+		assert.ErrorIs(t, Apply(context.Background(), repository.New(db), diff, false), ErrMassRemoval)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fail - missing column", func(t *testing.T) {
+		db, _, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		_, err = Compare(context.Background(), repository.New(db), readTable(t, "id,name\n1,Juan\n"))
+		var fileErr *FileError
+		assert.ErrorAs(t, err, &fileErr)
+	})
+}
+
+func TestGetSetField(t *testing.T) {
+	var m repository.Member
+	for _, field := range fields {
+		v := sql.NullString{String: "7", Valid: true}
+		setField(&m, field, v)
+		assert.Equal(t, v, getField(m, field), field)
+	}
+}
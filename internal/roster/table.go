@@ -0,0 +1,113 @@
+// Package roster imports the member roster from a spreadsheet, such as the export of the Google
+// Form members fill in every term.
+package roster
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Formats of a roster file.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// MaxFileSize is the largest roster file that is read.
+const MaxFileSize = 5 << 20
+
+// Table is the contents of a roster file: a header row, then one row per member.
+type Table struct {
+	Header []string
+	Rows   []Row
+}
+
+// Row is a row of a roster file. Number is its row number in the spreadsheet, where the header is row 1.
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// FormatOf guesses the format of a file from its name or content type. It returns "" if it cannot tell.
+func FormatOf(filename, contentType string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV
+	case ".xlsx":
+		return FormatXLSX
+	}
+	switch strings.TrimSpace(strings.Split(contentType, ";")[0]) {
+	case "text/csv":
+		return FormatCSV
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FormatXLSX
+	}
+	return ""
+}
+
+// Read reads a roster file in the given format. For XLSX files, only the first sheet is read.
+func Read(r io.Reader, format string) (*Table, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("file is larger than %d MB", MaxFileSize>>20)
+	}
+
+	var rows []Row
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(data)
+	case FormatXLSX:
+		rows, err = readXLSX(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q, expected csv or xlsx", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop blank rows, which spreadsheets tend to have at the end
+	t := &Table{}
+	for _, row := range rows {
+		if strings.TrimSpace(strings.Join(row.Cells, "")) == "" {
+			continue
+		}
+		if t.Header == nil {
+			t.Header = row.Cells
+			continue
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if t.Header == nil {
+		return nil, errors.New("file is empty")
+	}
+	return t, nil
+}
+
+func readCSV(data []byte) ([]Row, error) {
+	// Excel saves CSV files with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	var rows []Row
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		rows = append(rows, Row{Number: line, Cells: record})
+	}
+}
@@ -0,0 +1,95 @@
+package roster
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// buildXLSX returns a minimal XLSX file whose first sheet is sheet.
+func buildXLSX(t *testing.T, sharedStrings, sheet string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets><sheet name="Form Responses 1" sheetId="1" r:id="rId3"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/responses.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml":        sharedStrings,
+		"xl/worksheets/responses.xml": sheet,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		assert.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		data := "\ufeffTimestamp,ID Number,Full Name,Email Address\n" +
+			"2026/08/01,12345678,Juan Dela Cruz,juan@dlsu.edu.ph\n" +
+			",,,\n" +
+			"2026/08/02,12345679,\"Cruz, Maria\",maria@dlsu.edu.ph\n"
+
+		table, err := Read(strings.NewReader(data), FormatCSV)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"Timestamp", "ID Number", "Full Name", "Email Address"}, table.Header)
+			assert.Len(t, table.Rows, 2)
+			assert.Equal(t, 2, table.Rows[0].Number)
+			assert.Equal(t, 4, table.Rows[1].Number)
+			assert.Equal(t, "Cruz, Maria", table.Rows[1].Cells[2])
+		}
+	})
+
+	t.Run("xlsx", func(t *testing.T) {
+		data := buildXLSX(t,
+			`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>ID Number</t></si>
+  <si><t>Full Name</t></si>
+  <si><r><t>Juan </t></r><r><t>Dela Cruz</t></r></si>
+</sst>`,
+			`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+  <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="D1" t="inlineStr"><is><t>Email</t></is></c></row>
+  <row r="3"><c r="A3"><v>12345678</v></c><c r="B3" t="s"><v>2</v></c><c r="D3" t="str"><v>juan@dlsu.edu.ph</v></c></row>
+</sheetData></worksheet>`)
+
+		table, err := Read(bytes.NewReader(data), FormatXLSX)
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"ID Number", "Full Name", "", "Email"}, table.Header)
+			if assert.Len(t, table.Rows, 1) {
+				assert.Equal(t, 3, table.Rows[0].Number)
+				assert.Equal(t, []string{"12345678", "Juan Dela Cruz", "", "juan@dlsu.edu.ph"}, table.Rows[0].Cells)
+			}
+		}
+	})
+
+	t.Run("fail - not an xlsx file", func(t *testing.T) {
+		_, err := Read(strings.NewReader("id,email\n"), FormatXLSX)
+		assert.Error(t, err)
+	})
+
+	t.Run("fail - unknown format", func(t *testing.T) {
+		_, err := Read(strings.NewReader("id,email\n"), "ods")
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatCSV, FormatOf("Roster (Responses).CSV", ""))
+	assert.Equal(t, FormatXLSX, FormatOf("roster.xlsx", "application/octet-stream"))
+	assert.Equal(t, FormatCSV, FormatOf("", "text/csv; charset=utf-8"))
+	assert.Equal(t, "", FormatOf("roster.ods", ""))
+}
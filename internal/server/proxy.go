@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ipExtractorFromEnv decides where c.RealIP() takes the client IP from, which audit events and
+// usage analytics record. Without TRUSTED_PROXIES it is the address of the connection, and
+// X-Forwarded-For is ignored, since any client could set it. TRUSTED_PROXIES is a comma-separated
+// list of CIDR ranges (or single IPs) of the reverse proxies in front of the API; X-Forwarded-For
+// is then read from the right, skipping those proxies.
+func ipExtractorFromEnv() (echo.IPExtractor, error) {
+	v := strings.TrimSpace(os.Getenv("TRUSTED_PROXIES"))
+	if v == "" {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	// Only the configured ranges are trusted, not echo's defaults of every private network
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "/") {
+			if ip := net.ParseIP(s); ip != nil && ip.To4() != nil {
+				s += "/32"
+			} else {
+				s += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", s, err)
+		}
+		options = append(options, echo.TrustIPRange(ipNet))
+	}
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
@@ -3,8 +3,10 @@ package server
 import (
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/dlsu-lscs/lscs-core-api/internal/member"
 	"github.com/dlsu-lscs/lscs-core-api/internal/middlewares"
 	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
@@ -12,39 +14,157 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
+// auditActions names the audit log action of each audited route, by method and full path. Admin
+// revocations and applied roster imports are recorded by the handler, in the same transaction as
+// the change; the audit middleware records their previews and failures.
+var auditActions = map[string]string{
+	"POST /request-key":      "api_keys.issue",
+	"DELETE /keys/:id":       "api_keys.revoke",
+	"POST /keys/:id/rotate":  "api_keys.rotate",
+	"POST /auth/session":     "sessions.create",
+	"POST /auth/logout-all":  "sessions.revoke_all",
+	"GET /me":                "members.read",
+	"PATCH /me":              "members.update_self",
+	"PATCH /me/privacy":      "members.update_privacy",
+	"GET /members/export":    "members.export",
+	"GET /members":           "members.list",
+	"GET /members/:id/terms": "members.history",
+	"GET /terms/:id/roster":  "terms.roster",
+	"GET /org":               "org.read",
+	"POST /member":           "members.read",
+	"POST /member-id":        "members.read",
+	"POST /check-email":      "members.check",
+	"POST /check-id":         "members.check",
+
+	"GET /admin/keys":                         "api_keys.list",
+	"DELETE /admin/keys/:id":                  "api_keys.revoke",
+	"GET /admin/keys/:id/usage":               "api_keys.usage",
+	"GET /admin/usage":                        "api_keys.usage",
+	"GET /admin/analytics":                    "analytics.read",
+	"GET /admin/audit":                        "audit.list",
+	"POST /admin/members/import":              "members.import",
+	"POST /admin/members":                     "members.create",
+	"GET /admin/members/:id":                  "members.read",
+	"PATCH /admin/members/:id":                "members.update",
+	"DELETE /admin/members/:id":               "members.deactivate",
+	"POST /admin/members/:id/reactivate":      "members.reactivate",
+	"GET /admin/members/:id/emails":           "member_emails.list",
+	"POST /admin/members/:id/emails":          "member_emails.add",
+	"DELETE /admin/members/:id/emails/:email": "member_emails.delete",
+	"POST /admin/terms":                       "terms.create",
+	"PATCH /admin/terms/:id":                  "terms.update",
+	"DELETE /admin/terms/:id":                 "terms.delete",
+	"POST /admin/oauth/clients":               "oauth_clients.create",
+	"GET /admin/oauth/clients":                "oauth_clients.list",
+	"DELETE /admin/oauth/clients/:id":         "oauth_clients.delete",
+}
+
 func (s *Server) RegisterRoutes(e *echo.Echo) {
 
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"https://*", "http://*"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentLength, echo.HeaderAcceptEncoding, echo.HeaderContentType, echo.HeaderAuthorization},
+		AllowOriginFunc: middlewares.CORSAllowOriginFunc(s.db, strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",")),
+		AllowMethods:    []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:    []string{echo.HeaderOrigin, echo.HeaderContentLength, echo.HeaderAcceptEncoding, echo.HeaderContentType, echo.HeaderAuthorization},
+		ExposeHeaders:   []string{echo.HeaderRetryAfter, "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", member.HeaderTotalCount, member.HeaderNextCursor},
 	}))
 
 	// Public routes
 	e.GET("/", func(c echo.Context) error {
 		return c.String(http.StatusOK, "it works")
 	})
+	e.GET("/.well-known/jwks.json", auth.JWKSHandler(s.jwtKeys))
+
+	// "Sign in with LSCS" (OpenID Connect)
+	if s.oidcHandler != nil {
+		e.GET("/.well-known/openid-configuration", s.oidcHandler.DiscoveryHandler)
+		e.GET("/oauth/authorize", s.oidcHandler.AuthorizeHandler, middlewares.Audit(s.db, "oauth.authorize"))
+		e.GET("/oauth/callback", s.oidcHandler.CallbackHandler)
+		e.POST("/oauth/token", s.oidcHandler.TokenHandler, middlewares.Audit(s.db, "oauth.token"))
+		e.GET("/oauth/userinfo", s.oidcHandler.UserInfoHandler)
+		e.POST("/oauth/userinfo", s.oidcHandler.UserInfoHandler)
+	}
+
+	// Audited groups put the audit log first, so that requests their authentication rejects are recorded too
+	audited := middlewares.AuditRoutes(s.db, auditActions)
 
 	// Google OAuth protected routes
 	googleAuthProtected := e.Group("")
-	googleAuthProtected.Use(middlewares.GoogleAuthMiddleware)
+	googleAuthProtected.Use(audited, middlewares.GoogleAuthMiddleware(s.db, s.identity, s.signIn))
 	googleAuthProtected.POST("/request-key", s.authHandler.RequestKeyHandler)
+	googleAuthProtected.GET("/keys", s.authHandler.ListKeysHandler)
+	googleAuthProtected.DELETE("/keys/:id", s.authHandler.RevokeKeyHandler)
+	googleAuthProtected.POST("/keys/:id/rotate", s.authHandler.RotateKeyHandler)
+	googleAuthProtected.POST("/auth/session", s.sessionHandler.CreateSessionHandler)
+
+	// Member sessions, for frontends calling the API on behalf of a signed-in member
+	e.POST("/auth/refresh", s.sessionHandler.RefreshHandler)
+	e.POST("/auth/logout", s.sessionHandler.LogoutHandler)
+	memberSession := e.Group("")
+	memberSession.Use(audited, middlewares.MemberSessionMiddleware(s.db, s.sessions))
+	memberSession.POST("/auth/logout-all", s.sessionHandler.LogoutAllHandler)
+	memberSession.GET("/me", s.memberHandler.GetProfileHandler)
+	memberSession.PATCH("/me", s.memberHandler.UpdateProfileHandler)
+	memberSession.GET("/me/privacy", s.memberHandler.GetPrivacyHandler)
+	memberSession.PATCH("/me/privacy", s.memberHandler.UpdatePrivacyHandler)
 
 	// --- Protected routes ----
+	apiKeyAuth := []echo.MiddlewareFunc{
+		echojwt.WithConfig(echojwt.Config{
+			NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(auth.JwtCustomClaims) },
+			KeyFunc:       auth.Keyfunc(os.Getenv("JWT_SECRET"), s.jwtKeys),
+			TokenLookup:   "header:Authorization:Bearer ",
+		}),
+		middlewares.APIKeyMiddleware(s.db, s.usage),
+		middlewares.UsageAnalyticsMiddleware(s.usage),
+		middlewares.RateLimitMiddleware(s.rateLimiter, s.usage),
+		middlewares.OriginMiddleware,
+	}
 	protected := e.Group("")
-	protected.Use(echojwt.WithConfig(echojwt.Config{
-		NewClaimsFunc: func(c echo.Context) jwt.Claims { return new(auth.JwtCustomClaims) },
-		SigningKey:    []byte(os.Getenv("JWT_SECRET")),
-		TokenLookup:   "header:Authorization:Bearer ",
-		SigningMethod: "HS256",
-	}))
+	protected.Use(audited)
+	protected.Use(apiKeyAuth...)
+
+	// Read routes also take a member access token, with the signed-in member as the caller
+	reads := e.Group("")
+	reads.Use(audited, middlewares.MemberSessionOr(s.db, s.sessions, apiKeyAuth...))
+
+	protected.GET("/members/export", s.memberHandler.ExportMembersHandler, middlewares.RequireScope(auth.ScopeMembersRead))
+	reads.GET("/members", s.memberHandler.GetAllMembersHandler, middlewares.RequireScope(auth.ScopeMembersPII))
+	protected.GET("/members/:id/terms", s.termHandler.MemberHistoryHandler, middlewares.RequireScope(auth.ScopeMembersRead))
+	reads.GET("/terms", s.termHandler.ListTermsHandler, middlewares.RequireScope(auth.ScopeCommitteesRead))
+	reads.GET("/terms/:id", s.termHandler.GetTermHandler, middlewares.RequireScope(auth.ScopeCommitteesRead))
+	protected.GET("/terms/:id/roster", s.termHandler.TermRosterHandler, middlewares.RequireScope(auth.ScopeMembersRead))
+	protected.GET("/committees", s.committeeHandler.GetAllCommitteesHandler, middlewares.RequireScope(auth.ScopeCommitteesRead))
+	reads.GET("/org", s.committeeHandler.OrgHandler, middlewares.RequireScope(auth.ScopeCommitteesRead))
+	reads.POST("/member", s.memberHandler.GetMemberInfo, middlewares.RequireScope(auth.ScopeMembersPII))
+	reads.POST("/member-id", s.memberHandler.GetMemberInfoByID, middlewares.RequireScope(auth.ScopeMembersPII))
+	protected.POST("/check-email", s.memberHandler.CheckEmailHandler, middlewares.RequireScope(auth.ScopeMembersRead))
+	protected.POST("/check-id", s.memberHandler.CheckIDIfMember, middlewares.RequireScope(auth.ScopeMembersRead))
 
-	protected.GET("/members", s.memberHandler.GetAllMembersHandler)
-	protected.GET("/committees", s.committeeHandler.GetAllCommitteesHandler)
-	protected.POST("/member", s.memberHandler.GetMemberInfo)
-	protected.POST("/member-id", s.memberHandler.GetMemberInfoByID)
-	protected.POST("check-email", s.memberHandler.CheckEmailHandler)
-	protected.POST("check-id", s.memberHandler.CheckIDIfMember)
+	// --- Admin routes (admin API keys only) ---
+	adminRoutes := protected.Group("/admin", middlewares.RequireAdminKey(s.db, s.policy))
+	adminRoutes.GET("/keys", s.adminHandler.ListKeysHandler)
+	adminRoutes.DELETE("/keys/:id", s.adminHandler.RevokeKeyHandler)
+	adminRoutes.GET("/keys/:id/usage", s.adminHandler.KeyUsageHandler)
+	adminRoutes.GET("/usage", s.adminHandler.DailyUsageHandler)
+	adminRoutes.GET("/analytics", s.adminHandler.AnalyticsHandler)
+	adminRoutes.GET("/audit", s.adminHandler.ListAuditHandler)
+	adminRoutes.POST("/members/import", s.adminHandler.ImportMembersHandler)
+	adminRoutes.POST("/members", s.adminHandler.CreateMemberHandler)
+	adminRoutes.GET("/members/:id", s.adminHandler.GetMemberHandler)
+	adminRoutes.PATCH("/members/:id", s.adminHandler.UpdateMemberHandler)
+	adminRoutes.DELETE("/members/:id", s.adminHandler.DeactivateMemberHandler)
+	adminRoutes.POST("/members/:id/reactivate", s.adminHandler.ReactivateMemberHandler)
+	adminRoutes.GET("/members/:id/emails", s.adminHandler.ListMemberEmailsHandler)
+	adminRoutes.POST("/members/:id/emails", s.adminHandler.AddMemberEmailHandler)
+	adminRoutes.DELETE("/members/:id/emails/:email", s.adminHandler.DeleteMemberEmailHandler)
+	adminRoutes.POST("/terms", s.termHandler.CreateTermHandler)
+	adminRoutes.PATCH("/terms/:id", s.termHandler.UpdateTermHandler)
+	adminRoutes.DELETE("/terms/:id", s.termHandler.DeleteTermHandler)
+	if s.oidcHandler != nil {
+		adminRoutes.POST("/oauth/clients", s.oidcHandler.CreateClientHandler)
+		adminRoutes.GET("/oauth/clients", s.oidcHandler.ListClientsHandler)
+		adminRoutes.DELETE("/oauth/clients/:id", s.oidcHandler.DeleteClientHandler)
+	}
 }
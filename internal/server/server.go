@@ -1,43 +1,110 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/dlsu-lscs/lscs-core-api/internal/admin"
+	"github.com/dlsu-lscs/lscs-core-api/internal/analytics"
 	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
 	"github.com/dlsu-lscs/lscs-core-api/internal/committee"
 	"github.com/dlsu-lscs/lscs-core-api/internal/database"
 	"github.com/dlsu-lscs/lscs-core-api/internal/member"
+	"github.com/dlsu-lscs/lscs-core-api/internal/oidc"
+	"github.com/dlsu-lscs/lscs-core-api/internal/policy"
+	"github.com/dlsu-lscs/lscs-core-api/internal/ratelimit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/dlsu-lscs/lscs-core-api/internal/session"
+	"github.com/dlsu-lscs/lscs-core-api/internal/term"
 	"github.com/labstack/echo/v4"
 )
 
 type Server struct {
 	port int
 
-	db database.Service
+	db          database.Service
+	jwtKeys     *auth.KeySet
+	identity    auth.IdentityVerifier
+	signIn      auth.SignInRules
+	policy      *policy.Engine
+	rateLimiter *ratelimit.Limiter
+	usage       *analytics.Recorder
+	sessions    *session.Tokens
 
 	authHandler      *auth.Handler
+	adminHandler     *admin.Handler
 	memberHandler    *member.Handler
 	committeeHandler *committee.Handler
+	sessionHandler   *session.Handler
+	termHandler      *term.Handler
+	oidcHandler      *oidc.Handler // nil when the OIDC provider is disabled
 }
 
-func NewServer() *http.Server {
+// NewServer creates the HTTP server. The returned cleanup function must be called once the server
+// has shut down, to write out state that is still held in memory.
+func NewServer() (*http.Server, func(context.Context)) {
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 	dbService := database.New()
+	policyEngine, err := policy.NewFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	jwtKeys, err := auth.KeySetFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	identityVerifier, err := auth.IdentityVerifierFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	signInRules, err := auth.SignInRulesFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	sessionTokens := session.NewTokens(os.Getenv("JWT_SECRET"), jwtKeys)
+	oidcConfig, oidcEnabled, err := oidc.ConfigFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ipExtractor, err := ipExtractorFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	NewServer := &Server{
 		port:             port,
 		db:               dbService,
-		authHandler:      auth.NewHandler(auth.NewService(os.Getenv("JWT_SECRET")), dbService),
-		memberHandler:    member.NewHandler(dbService),
+		jwtKeys:          jwtKeys,
+		identity:         identityVerifier,
+		signIn:           signInRules,
+		policy:           policyEngine,
+		rateLimiter:      ratelimit.New(ratelimit.LimitsFromEnv()),
+		usage:            analytics.NewRecorder(analytics.BucketSizeFromEnv()),
+		sessions:         sessionTokens,
+		authHandler:      auth.NewHandler(auth.NewService(os.Getenv("JWT_SECRET"), jwtKeys, auth.KeyLifetimesFromEnv()), dbService, policyEngine),
+		adminHandler:     admin.NewHandler(dbService),
+		memberHandler:    member.NewHandler(dbService, policyEngine),
 		committeeHandler: committee.NewHandler(dbService),
+		sessionHandler:   session.NewHandler(dbService, sessionTokens),
+		termHandler:      term.NewHandler(dbService),
+	}
+
+	if oidcEnabled {
+		// ID tokens must be verifiable by clients, so they are never signed with JWT_SECRET
+		if jwtKeys == nil {
+			log.Fatal("the OIDC provider requires JWT_KEYS_DIR")
+		}
+		NewServer.oidcHandler = oidc.NewHandler(dbService, jwtKeys, oidcConfig, oidc.NewGoogleUpstream(oidcConfig, identityVerifier), signInRules)
 	}
 
 	// Declare Server config
 	e := echo.New()
+	e.IPExtractor = ipExtractor
 	NewServer.RegisterRoutes(e)
 
 	server := &http.Server{
@@ -48,5 +115,17 @@ func NewServer() *http.Server {
 		WriteTimeout: 30 * time.Second,
 	}
 
-	return server
+	// Usage analytics are written in batches in the background
+	q := repository.New(dbService.GetConnection())
+	ctx, stopUsage := context.WithCancel(context.Background())
+	go NewServer.usage.Run(ctx, q, analytics.FlushInterval)
+
+	cleanup := func(ctx context.Context) {
+		stopUsage()
+		if err := NewServer.usage.Flush(ctx, q); err != nil {
+			log.Printf("failed to flush usage analytics: %v", err)
+		}
+	}
+
+	return server, cleanup
 }
@@ -0,0 +1,231 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// tokenResponse issues an access token for the session and pairs it with its refresh token.
+func (h *Handler) tokenResponse(email string, sessionID int64, refreshToken string, refreshExpiresAt time.Time) (TokenResponse, error) {
+	accessToken, expiresAt, err := h.tokens.Issue(email, sessionID)
+	if err != nil {
+		return TokenResponse{}, err
+	}
+	return TokenResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(time.Until(expiresAt).Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// CreateSessionHandler signs a member in. It runs behind GoogleAuthMiddleware and exchanges the
+// Google ID token for a short-lived access token and a refresh token.
+func (h *Handler) CreateSessionHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	email := c.Get("user_email").(string)
+
+	if _, err := q.CheckEmailIfMember(ctx, email); err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "Not an LSCS member"})
+		}
+		slog.Error("error checking email", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		slog.Error("failed to create session", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	// MySQL TIMESTAMP has second precision
+	expiresAt := time.Now().Add(refreshTokenLifetime).Truncate(time.Second)
+
+	userAgent := c.Request().UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	sessionID, err := q.CreateMemberSession(ctx, repository.CreateMemberSessionParams{
+		MemberEmail:      email,
+		RefreshTokenHash: hashToken(refreshToken),
+		UserAgent:        userAgent,
+		ClientIp:         c.RealIP(),
+		ExpiresAt:        expiresAt,
+	})
+	if err != nil {
+		slog.Error("failed to store session", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error creating session"})
+	}
+	audit.SetTarget(c, audit.TargetSession, strconv.FormatInt(sessionID, 10))
+
+	response, err := h.tokenResponse(email, sessionID, refreshToken, expiresAt)
+	if err != nil {
+		slog.Error("failed to issue access token", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error creating session"})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new refresh token.
+// A refresh token that was already used means it was copied, so the whole session is revoked.
+func (h *Handler) RefreshHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+	}
+	hash := hashToken(req.RefreshToken)
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error refreshing session"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	session, err := qtx.GetMemberSessionByRefreshTokenForUpdate(ctx, hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			tx.Rollback()
+			h.revokeReusedToken(c, q, hash)
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid refresh token"})
+		}
+		slog.Error("failed to get session", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error refreshing session"})
+	}
+
+	if session.RevokedAt.Valid {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid refresh token"})
+	}
+	if !session.ExpiresAt.After(time.Now()) {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Session has expired"})
+	}
+
+	refreshToken, err := newRefreshToken()
+	if err != nil {
+		slog.Error("failed to refresh session", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+	expiresAt := time.Now().Add(refreshTokenLifetime).Truncate(time.Second)
+
+	if err := qtx.RotateMemberSessionToken(ctx, repository.RotateMemberSessionTokenParams{
+		RefreshTokenHash: hashToken(refreshToken),
+		ExpiresAt:        expiresAt,
+		ID:               session.ID,
+	}); err != nil {
+		slog.Error("failed to rotate refresh token", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error refreshing session"})
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit session refresh", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error refreshing session"})
+	}
+
+	response, err := h.tokenResponse(session.MemberEmail, session.ID, refreshToken, expiresAt)
+	if err != nil {
+		slog.Error("failed to issue access token", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error refreshing session"})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// revokeReusedToken revokes the session a refresh token belonged to if it has since been rotated,
+// and records the reuse in the audit log. Unknown tokens are ignored.
+func (h *Handler) revokeReusedToken(c echo.Context, q *repository.Queries, hash string) {
+	ctx := context.WithoutCancel(c.Request().Context())
+
+	session, err := q.GetMemberSessionByPreviousToken(ctx, sql.NullString{String: hash, Valid: true})
+	if err != nil {
+		if err != sql.ErrNoRows {
+			slog.Error("failed to check for refresh token reuse", "error", err)
+		}
+		return
+	}
+
+	revoked, err := q.RevokeMemberSession(ctx, session.ID)
+	if err != nil {
+		slog.Error("failed to revoke session", "error", err, "session_id", session.ID)
+		return
+	}
+	if revoked == 0 {
+		return
+	}
+
+	slog.Warn("refresh token reused, session revoked", "session_id", session.ID, "member_email", session.MemberEmail)
+	if err := audit.Record(ctx, q, audit.Event{
+		ActorEmail: session.MemberEmail,
+		Action:     "sessions.reuse_detected",
+		Route:      c.Request().Method + " " + c.Path(),
+		TargetType: audit.TargetSession,
+		TargetID:   strconv.FormatInt(session.ID, 10),
+		Outcome:    audit.OutcomeDenied,
+		StatusCode: http.StatusUnauthorized,
+		ClientIP:   c.RealIP(),
+	}); err != nil {
+		slog.Error("failed to record audit event", "error", err)
+	}
+}
+
+// LogoutHandler revokes the session of a refresh token. Unknown tokens are not an error, so
+// logging out twice is harmless.
+func (h *Handler) LogoutHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	var req RefreshRequest
+	if err := c.Bind(&req); err != nil || req.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "refresh_token is required"})
+	}
+
+	session, err := q.GetMemberSessionByRefreshTokenForUpdate(ctx, hashToken(req.RefreshToken))
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("failed to get session", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error logging out"})
+	}
+	if err == nil {
+		if _, err := q.RevokeMemberSession(ctx, session.ID); err != nil {
+			slog.Error("failed to revoke session", "error", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error logging out"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"success": "Logged out"})
+}
+
+// LogoutAllHandler revokes every session of the signed-in member. It runs behind
+// MemberSessionMiddleware, so it also ends the session the request was made with.
+func (h *Handler) LogoutAllHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	email := c.Get("user_email").(string)
+
+	revoked, err := q.RevokeMemberSessions(c.Request().Context(), email)
+	if err != nil {
+		slog.Error("failed to revoke sessions", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error logging out"})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": "Logged out everywhere",
+		"revoked": revoked,
+	})
+}
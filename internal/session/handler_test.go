@@ -0,0 +1,211 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDBService is a mock implementation of the database.Service interface.
+type mockDBService struct {
+	db *sql.DB
+}
+
+func (m *mockDBService) Health() map[string]string {
+	return nil
+}
+
+func (m *mockDBService) Close() error {
+	return nil
+}
+
+func (m *mockDBService) GetConnection() *sql.DB {
+	return m.db
+}
+
+var sessionColumns = []string{"id", "member_email", "refresh_token_hash", "previous_token_hash", "user_agent", "client_ip", "created_at", "refreshed_at", "expires_at", "revoked_at"}
+
+func postJSON(path, body string) (*http.Request, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	return req, httptest.NewRecorder()
+}
+
+func TestCreateSessionHandler(t *testing.T) {
+	tokens := NewTokens("test-secret", nil)
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WithArgs("test@dlsu.edu.ph").
+			WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("test@dlsu.edu.ph"))
+		mock.ExpectExec("INSERT INTO member_sessions").
+			WithArgs("test@dlsu.edu.ph", sqlmock.AnyArg(), "test-agent", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+
+		req, rec := postJSON("/auth/session", "")
+		req.Header.Set("User-Agent", "test-agent")
+		c := echo.New().NewContext(req, rec)
+		c.Set("user_email", "test@dlsu.edu.ph")
+
+		h := NewHandler(&mockDBService{db: db}, tokens)
+		if assert.NoError(t, h.CreateSessionHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var resp TokenResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, "Bearer", resp.TokenType)
+			assert.NotEmpty(t, resp.RefreshToken)
+
+			claims, err := tokens.Verify(resp.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "test@dlsu.edu.ph", claims.Email)
+			assert.Equal(t, int64(7), claims.SessionID)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - not a member", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT email FROM members WHERE email = ?").
+			WithArgs("outsider@dlsu.edu.ph").
+			WillReturnError(sql.ErrNoRows)
+
+		req, rec := postJSON("/auth/session", "")
+		c := echo.New().NewContext(req, rec)
+		c.Set("user_email", "outsider@dlsu.edu.ph")
+
+		h := NewHandler(&mockDBService{db: db}, tokens)
+		if assert.NoError(t, h.CreateSessionHandler(c)) {
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
+
+func TestRefreshHandler(t *testing.T) {
+	tokens := NewTokens("test-secret", nil)
+	const refreshToken = "old-refresh-token"
+
+	t.Run("success - token is rotated", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM member_sessions WHERE refresh_token_hash = ?").
+			WithArgs(hashToken(refreshToken)).
+			WillReturnRows(sqlmock.NewRows(sessionColumns).
+				AddRow(7, "test@dlsu.edu.ph", hashToken(refreshToken), nil, "", "", time.Now(), nil, time.Now().Add(time.Hour), nil))
+		mock.ExpectExec("UPDATE member_sessions SET previous_token_hash = refresh_token_hash").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		req, rec := postJSON("/auth/refresh", `{"refresh_token": "`+refreshToken+`"}`)
+		h := NewHandler(&mockDBService{db: db}, tokens)
+		if assert.NoError(t, h.RefreshHandler(echo.New().NewContext(req, rec))) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var resp TokenResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.NotEqual(t, refreshToken, resp.RefreshToken)
+			_, err := tokens.Verify(resp.AccessToken)
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - reused token revokes the session", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM member_sessions WHERE refresh_token_hash = ?").
+			WithArgs(hashToken(refreshToken)).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+		mock.ExpectQuery("SELECT (.+) FROM member_sessions WHERE previous_token_hash = ?").
+			WithArgs(hashToken(refreshToken)).
+			WillReturnRows(sqlmock.NewRows(sessionColumns).
+				AddRow(7, "test@dlsu.edu.ph", "newer-hash", hashToken(refreshToken), "", "", time.Now(), time.Now(), time.Now().Add(time.Hour), nil))
+		mock.ExpectExec("UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = ?").
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO audit_events").
+			WithArgs("test@dlsu.edu.ph", nil, "sessions.reuse_detected", sqlmock.AnyArg(), "member_session", "7", "denied", http.StatusUnauthorized, sqlmock.AnyArg(), nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		req, rec := postJSON("/auth/refresh", `{"refresh_token": "`+refreshToken+`"}`)
+		h := NewHandler(&mockDBService{db: db}, tokens)
+		if assert.NoError(t, h.RefreshHandler(echo.New().NewContext(req, rec))) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - revoked session", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM member_sessions WHERE refresh_token_hash = ?").
+			WithArgs(hashToken(refreshToken)).
+			WillReturnRows(sqlmock.NewRows(sessionColumns).
+				AddRow(7, "test@dlsu.edu.ph", hashToken(refreshToken), nil, "", "", time.Now(), nil, time.Now().Add(time.Hour), time.Now()))
+		mock.ExpectRollback()
+
+		req, rec := postJSON("/auth/refresh", `{"refresh_token": "`+refreshToken+`"}`)
+		h := NewHandler(&mockDBService{db: db}, tokens)
+		if assert.NoError(t, h.RefreshHandler(echo.New().NewContext(req, rec))) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - missing token", func(t *testing.T) {
+		req, rec := postJSON("/auth/refresh", `{}`)
+		h := NewHandler(&mockDBService{}, tokens)
+		if assert.NoError(t, h.RefreshHandler(echo.New().NewContext(req, rec))) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestLogoutAllHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE member_email = ?").
+		WithArgs("test@dlsu.edu.ph").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	req, rec := postJSON("/auth/logout-all", "")
+	c := echo.New().NewContext(req, rec)
+	c.Set("user_email", "test@dlsu.edu.ph")
+
+	h := NewHandler(&mockDBService{db: db}, NewTokens("test-secret", nil))
+	if assert.NoError(t, h.LogoutAllHandler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"success": "Logged out everywhere", "revoked": 3}`, rec.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	}
+}
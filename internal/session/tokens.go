@@ -0,0 +1,111 @@
+package session
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Audience is the aud claim of member access tokens. API keys carry no audience, so neither kind of
+// token is accepted in place of the other.
+const Audience = "lscs-core-api/member"
+
+// Claims are the claims of a member access token.
+type Claims struct {
+	Email     string `json:"email"`
+	SessionID int64  `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Tokens signs and verifies member access tokens, with the same keys as API keys.
+type Tokens struct {
+	secret string
+	keys   *auth.KeySet
+}
+
+// NewTokens creates a Tokens that signs with the signing key of keys, or with the HS256 secret if
+// keys is nil.
+func NewTokens(secret string, keys *auth.KeySet) *Tokens {
+	return &Tokens{secret: secret, keys: keys}
+}
+
+// Issue returns an access token for the member signed in to session sessionID, and when it expires.
+func (t *Tokens) Issue(email string, sessionID int64) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(accessTokenLifetime).Truncate(time.Second)
+	claims := &Claims{
+		Email:     email,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   email,
+			Audience:  jwt.ClaimStrings{Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	var tokenString string
+	var err error
+	switch {
+	case t.keys != nil:
+		tokenString, err = t.keys.Sign(claims)
+	case t.secret != "":
+		tokenString, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(t.secret))
+	default:
+		err = errors.New("neither JWT_KEYS_DIR nor JWT_SECRET is set")
+	}
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return tokenString, expiresAt, nil
+}
+
+// Verify checks the signature, audience and expiry of a member access token and returns its claims.
+func (t *Tokens) Verify(tokenString string) (*Claims, error) {
+	claims := new(Claims)
+	_, err := jwt.ParseWithClaims(tokenString, claims, auth.Keyfunc(t.secret, t.keys),
+		jwt.WithAudience(Audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// IsAccessToken reports whether tokenString claims to be a member access token, going by its
+// audience only. It does not verify the token; use Verify for that.
+func IsAccessToken(tokenString string) bool {
+	claims := new(Claims)
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+	for _, aud := range claims.Audience {
+		if aud == Audience {
+			return true
+		}
+	}
+	return false
+}
+
+// newRefreshToken returns a random refresh token. Only its hash is stored.
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken returns the hex-encoded SHA-256 hash of a refresh token.
+func hashToken(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
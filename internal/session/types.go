@@ -0,0 +1,42 @@
+package session
+
+import (
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+)
+
+const (
+	// accessTokenLifetime is how long a member access token is valid.
+	accessTokenLifetime = 15 * time.Minute
+	// refreshTokenLifetime is how long a session lasts without being refreshed.
+	refreshTokenLifetime = 30 * 24 * time.Hour
+	// maxUserAgentLength is the size of member_sessions.user_agent.
+	maxUserAgentLength = 255
+)
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse is returned when a session is created or refreshed. The refresh token is only
+// valid once: every refresh returns a new one.
+type TokenResponse struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int       `json:"expires_in"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type Handler struct {
+	dbService database.Service
+	tokens    *Tokens
+}
+
+func NewHandler(dbService database.Service, tokens *Tokens) *Handler {
+	return &Handler{
+		dbService: dbService,
+		tokens:    tokens,
+	}
+}
@@ -0,0 +1,129 @@
+package term
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// Errors returned by Lookup.
+var (
+	ErrInvalidTerm   = errors.New(`term must be a term id or "current"`)
+	ErrTermNotFound  = errors.New("term not found")
+	ErrNoCurrentTerm = errors.New("no term has started yet")
+)
+
+// Name is how a term is shown, such as "AY 2025-2026 Term 1".
+func Name(t repository.Term) string {
+	return fmt.Sprintf("AY %d-%d Term %d", t.StartYear, t.EndYear, t.Term)
+}
+
+// Current returns the term that started most recently. A term stays current through the break
+// after it, until the next term starts.
+func Current(ctx context.Context, q *repository.Queries) (repository.Term, error) {
+	t, err := q.GetCurrentTerm(ctx)
+	if err == sql.ErrNoRows {
+		return t, ErrNoCurrentTerm
+	}
+	return t, err
+}
+
+// Lookup finds the term named by s, which is a term id or "current". An empty s is the current term.
+func Lookup(ctx context.Context, q *repository.Queries, s string) (repository.Term, error) {
+	if s == "" || s == "current" {
+		return Current(ctx, q)
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return repository.Term{}, ErrInvalidTerm
+	}
+	t, err := q.GetTerm(ctx, int32(id))
+	if err == sql.ErrNoRows {
+		return t, ErrTermNotFound
+	}
+	return t, err
+}
+
+// FromQuery resolves the `term` query parameter, defaulting to the current term, so that routes
+// can be scoped with ?term=12 or ?term=current. On failure it returns the status and message to
+// respond with.
+func FromQuery(c echo.Context, q *repository.Queries) (repository.Term, int, string) {
+	return lookupParam(c, q, c.QueryParam("term"))
+}
+
+// PastFromQuery resolves the `term` query parameter for routes that show live data for the
+// current term and the roster recorded in member_terms for any other. past is true when the
+// roster should be read from member_terms: the term was recorded, as the current term is during
+// the break after a rollover, or it is an earlier term. past is false when there is no term
+// parameter. A term that has not started yet has no roster and responds with 400. On failure it
+// returns the status and message to respond with.
+func PastFromQuery(c echo.Context, q *repository.Queries) (t repository.Term, past bool, status int, msg string) {
+	s := c.QueryParam("term")
+	if s == "" {
+		return t, false, http.StatusOK, ""
+	}
+	t, status, msg = lookupParam(c, q, s)
+	if status != http.StatusOK {
+		return t, false, status, msg
+	}
+	if t.StartsOn.Valid && t.StartsOn.Time.After(time.Now()) {
+		return t, false, http.StatusBadRequest, "Term has not started yet"
+	}
+
+	ctx := c.Request().Context()
+	recorded, err := q.CountTermSnapshot(ctx, t.ID)
+	if err != nil {
+		slog.Error("failed to count term snapshot", "error", err)
+		return t, false, http.StatusInternalServerError, "Internal server error"
+	}
+	if recorded > 0 {
+		return t, true, http.StatusOK, ""
+	}
+	if s == "current" {
+		return t, false, http.StatusOK, ""
+	}
+	current, err := Current(ctx, q)
+	if err != nil && !errors.Is(err, ErrNoCurrentTerm) {
+		slog.Error("failed to get current term", "error", err)
+		return t, false, http.StatusInternalServerError, "Internal server error"
+	}
+	return t, err != nil || t.ID != current.ID, http.StatusOK, ""
+}
+
+func lookupParam(c echo.Context, q *repository.Queries, s string) (repository.Term, int, string) {
+	t, err := Lookup(c.Request().Context(), q, s)
+	switch {
+	case err == nil:
+		return t, http.StatusOK, ""
+	case errors.Is(err, ErrInvalidTerm):
+		return t, http.StatusBadRequest, "Invalid term; use a term id or \"current\""
+	case errors.Is(err, ErrTermNotFound):
+		return t, http.StatusNotFound, "Term not found"
+	case errors.Is(err, ErrNoCurrentTerm):
+		return t, http.StatusNotFound, "No term has started yet"
+	}
+	slog.Error("failed to get term", "error", err)
+	return t, http.StatusInternalServerError, "Internal server error"
+}
+
+// Window returns the time from the start of the term's first day to the end of its last day, for
+// scoping reports by term. A term that has not ended runs until now.
+func Window(t repository.Term) (since, until time.Time, err error) {
+	if !t.StartsOn.Valid {
+		return since, until, fmt.Errorf("%s has no start date", Name(t))
+	}
+	since = t.StartsOn.Time
+	until = time.Now()
+	if t.EndsOn.Valid && t.EndsOn.Time.AddDate(0, 0, 1).Before(until) {
+		until = t.EndsOn.Time.AddDate(0, 0, 1)
+	}
+	return since, until, nil
+}
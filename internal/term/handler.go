@@ -0,0 +1,178 @@
+package term
+
+import (
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/database"
+	"github.com/dlsu-lscs/lscs-core-api/internal/helpers"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler struct {
+	dbService database.Service
+}
+
+func NewHandler(dbService database.Service) *Handler {
+	return &Handler{
+		dbService: dbService,
+	}
+}
+
+// TermResponse is a term of an academic year, such as term 1 of AY 2025-2026.
+type TermResponse struct {
+	ID        int32                `json:"id"`
+	Term      int32                `json:"term"`
+	StartYear int32                `json:"start_year"`
+	EndYear   int32                `json:"end_year"`
+	Name      string               `json:"name"`
+	StartsOn  helpers.NullableDate `json:"starts_on"`
+	EndsOn    helpers.NullableDate `json:"ends_on"`
+}
+
+func toTermResponse(t repository.Term) TermResponse {
+	return TermResponse{
+		ID:        t.ID,
+		Term:      t.Term,
+		StartYear: t.StartYear,
+		EndYear:   t.EndYear,
+		Name:      Name(t),
+		StartsOn:  helpers.NullableDate{NullTime: t.StartsOn},
+		EndsOn:    helpers.NullableDate{NullTime: t.EndsOn},
+	}
+}
+
+// MemberTermResponse is a member's committee, position and house in one term.
+type MemberTermResponse struct {
+	Term          TermResponse           `json:"term"`
+	CommitteeID   helpers.NullableString `json:"committee_id"`
+	CommitteeName helpers.NullableString `json:"committee_name"`
+	PositionID    helpers.NullableString `json:"position_id"`
+	PositionName  helpers.NullableString `json:"position_name"`
+	HouseName     helpers.NullableString `json:"house_name"`
+	StartedOn     helpers.NullableDate   `json:"started_on"`
+	EndedOn       helpers.NullableDate   `json:"ended_on"`
+}
+
+// MemberHistoryResponse is a member's org history, from the latest term.
+type MemberHistoryResponse struct {
+	ID       int32                `json:"id"`
+	FullName string               `json:"full_name"`
+	Terms    []MemberTermResponse `json:"terms"`
+}
+
+// RosterMemberResponse is a member as they were in a past term.
+type RosterMemberResponse struct {
+	ID            int32                  `json:"id"`
+	FullName      string                 `json:"full_name"`
+	Nickname      helpers.NullableString `json:"nickname"`
+	CommitteeID   helpers.NullableString `json:"committee_id"`
+	CommitteeName helpers.NullableString `json:"committee_name"`
+	DivisionID    helpers.NullableString `json:"division_id"`
+	DivisionName  helpers.NullableString `json:"division_name"`
+	PositionID    helpers.NullableString `json:"position_id"`
+	PositionName  helpers.NullableString `json:"position_name"`
+	HouseName     helpers.NullableString `json:"house_name"`
+	StartedOn     helpers.NullableDate   `json:"started_on"`
+	EndedOn       helpers.NullableDate   `json:"ended_on"`
+}
+
+// RosterResponse lists the members of a past term.
+type RosterResponse struct {
+	Term    TermResponse           `json:"term"`
+	Members []RosterMemberResponse `json:"members"`
+}
+
+// MemberHistoryHandler returns the committee, position and house a member had in each past term.
+// Deactivated members still have their history.
+func (h *Handler) MemberHistoryHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid member id"})
+	}
+	audit.SetTarget(c, audit.TargetMember, strconv.Itoa(id))
+
+	m, err := q.AdminGetMember(ctx, int32(id))
+	if err == sql.ErrNoRows {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Member not found"})
+	}
+	if err != nil {
+		slog.Error("failed to get member", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	rows, err := q.ListMemberTerms(ctx, m.ID)
+	if err != nil {
+		slog.Error("failed to list member terms", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	response := MemberHistoryResponse{ID: m.ID, FullName: m.FullName, Terms: make([]MemberTermResponse, 0, len(rows))}
+	for _, r := range rows {
+		t := repository.Term{
+			ID:        r.TermID,
+			Term:      r.Term,
+			StartYear: r.StartYear,
+			EndYear:   r.EndYear,
+			StartsOn:  r.StartsOn,
+			EndsOn:    r.EndsOn,
+		}
+		response.Terms = append(response.Terms, MemberTermResponse{
+			Term:          toTermResponse(t),
+			CommitteeID:   helpers.NullableString{NullString: r.CommitteeID},
+			CommitteeName: helpers.NullableString{NullString: r.CommitteeName},
+			PositionID:    helpers.NullableString{NullString: r.PositionID},
+			PositionName:  helpers.NullableString{NullString: r.PositionName},
+			HouseName:     helpers.NullableString{NullString: r.HouseName},
+			StartedOn:     helpers.NullableDate{NullTime: r.StartedOn},
+			EndedOn:       helpers.NullableDate{NullTime: r.EndedOn},
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// TermRosterHandler returns the members of a past term, with the committee, position and house
+// each one had then. The id may be "current".
+func (h *Handler) TermRosterHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	t, status, msg := lookupParam(c, q, c.Param("id"))
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	audit.SetTarget(c, audit.TargetTerm, strconv.Itoa(int(t.ID)))
+
+	rows, err := q.ListTermRoster(c.Request().Context(), t.ID)
+	if err != nil {
+		slog.Error("failed to list term roster", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	response := RosterResponse{Term: toTermResponse(t), Members: make([]RosterMemberResponse, 0, len(rows))}
+	for _, r := range rows {
+		response.Members = append(response.Members, RosterMemberResponse{
+			ID:            r.ID,
+			FullName:      r.FullName,
+			Nickname:      helpers.NullableString{NullString: r.Nickname},
+			CommitteeID:   helpers.NullableString{NullString: r.CommitteeID},
+			CommitteeName: helpers.NullableString{NullString: r.CommitteeName},
+			DivisionID:    helpers.NullableString{NullString: r.DivisionID},
+			DivisionName:  helpers.NullableString{NullString: r.DivisionName},
+			PositionID:    helpers.NullableString{NullString: r.PositionID},
+			PositionName:  helpers.NullableString{NullString: r.PositionName},
+			HouseName:     helpers.NullableString{NullString: r.HouseName},
+			StartedOn:     helpers.NullableDate{NullTime: r.StartedOn},
+			EndedOn:       helpers.NullableDate{NullTime: r.EndedOn},
+		})
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
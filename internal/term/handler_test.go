@@ -0,0 +1,176 @@
+package term
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDBService is a mock implementation of the database.Service interface.
+type mockDBService struct {
+	db *sql.DB
+}
+
+func (m *mockDBService) Health() map[string]string {
+	return nil
+}
+
+func (m *mockDBService) Close() error {
+	return nil
+}
+
+func (m *mockDBService) GetConnection() *sql.DB {
+	return m.db
+}
+
+var adminMemberColumns = []string{
+	"id", "full_name", "nickname", "email", "telegram", "position_id", "committee_id", "college", "program",
+	"discord", "interests", "contact_number", "fb_link", "house_id", "status", "deactivated_at",
+}
+
+var termColumns = []string{"id", "term", "start_year", "end_year", "starts_on", "ends_on"}
+
+func date(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func newTermContext(path, id string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	return c, rec
+}
+
+func TestMemberHistoryHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = ?").WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows(adminMemberColumns).
+				AddRow(12345678, "Juan Dela Cruz", nil, "juan@dlsu.edu.ph", nil, "VP", "RND", nil, nil, nil, nil, nil, nil, 1, "INACTIVE", time.Now()))
+		mock.ExpectQuery("SELECT (.+) FROM member_terms mt").WithArgs(12345678).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"term_id", "term", "start_year", "end_year", "starts_on", "ends_on", "committee_id", "committee_name",
+				"position_id", "position_name", "house_name", "started_on", "ended_on",
+			}).
+				AddRow(12, 3, 2025, 2026, date("2026-05-04"), date("2026-08-15"), "RND", "Research and Development",
+					"MEM", "Member", "Gell-Mann", date("2026-05-04"), date("2026-08-15")).
+				AddRow(11, 2, 2025, 2026, nil, nil, "RND", "Research and Development", "CT", "Committee Trainee", nil, nil, nil))
+
+		c, rec := newTermContext("/members/12345678/terms", "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.MemberHistoryHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{
+				"id": 12345678,
+				"full_name": "Juan Dela Cruz",
+				"terms": [
+					{
+						"term": {"id": 12, "term": 3, "start_year": 2025, "end_year": 2026, "name": "AY 2025-2026 Term 3", "starts_on": "2026-05-04", "ends_on": "2026-08-15"},
+						"committee_id": "RND", "committee_name": "Research and Development",
+						"position_id": "MEM", "position_name": "Member", "house_name": "Gell-Mann",
+						"started_on": "2026-05-04", "ended_on": "2026-08-15"
+					},
+					{
+						"term": {"id": 11, "term": 2, "start_year": 2025, "end_year": 2026, "name": "AY 2025-2026 Term 2", "starts_on": null, "ends_on": null},
+						"committee_id": "RND", "committee_name": "Research and Development",
+						"position_id": "CT", "position_name": "Committee Trainee", "house_name": "",
+						"started_on": null, "ended_on": null
+					}
+				]
+			}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - member not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM members WHERE id = ?").WithArgs(12345678).WillReturnError(sql.ErrNoRows)
+
+		c, rec := newTermContext("/members/12345678/terms", "12345678")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.MemberHistoryHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - invalid id", func(t *testing.T) {
+		c, rec := newTermContext("/members/abc/terms", "abc")
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.MemberHistoryHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestTermRosterHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(12, 3, 2025, 2026, date("2026-05-04"), nil))
+		mock.ExpectQuery("SELECT (.+) FROM member_terms mt").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "full_name", "nickname", "committee_id", "committee_name", "division_id", "division_name",
+				"position_id", "position_name", "house_name", "started_on", "ended_on",
+			}).AddRow(12345678, "Juan Dela Cruz", "Juan", "RND", "Research and Development", "INT", "Internals",
+				"MEM", "Member", "Gell-Mann", nil, date("2026-08-15")))
+
+		c, rec := newTermContext("/terms/12/roster", "12")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.TermRosterHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{
+				"term": {"id": 12, "term": 3, "start_year": 2025, "end_year": 2026, "name": "AY 2025-2026 Term 3", "starts_on": "2026-05-04", "ends_on": null},
+				"members": [
+					{
+						"id": 12345678, "full_name": "Juan Dela Cruz", "nickname": "Juan",
+						"committee_id": "RND", "committee_name": "Research and Development",
+						"division_id": "INT", "division_name": "Internals",
+						"position_id": "MEM", "position_name": "Member", "house_name": "Gell-Mann",
+						"started_on": null, "ended_on": "2026-08-15"
+					}
+				]
+			}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - term not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(99).WillReturnError(sql.ErrNoRows)
+
+		c, rec := newTermContext("/terms/99/roster", "99")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.TermRosterHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package term
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+)
+
+// Rollover records the committee, position and house of every active member as their assignment
+// in the term. It fails if the term was already recorded, unless replace is set, in which case the
+// old records of the term are replaced. It returns the number of members recorded.
+//
+// Run it in a transaction, so that a replaced term is never left half recorded.
+func Rollover(ctx context.Context, q *repository.Queries, termID int32, startedOn, endedOn sql.NullTime, replace bool) (int64, error) {
+	if _, err := q.GetTerm(ctx, termID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("term %d does not exist", termID)
+		}
+		return 0, err
+	}
+
+	recorded, err := q.CountTermSnapshot(ctx, termID)
+	if err != nil {
+		return 0, err
+	}
+	if recorded > 0 {
+		if !replace {
+			return 0, fmt.Errorf("term %d already has %d members recorded", termID, recorded)
+		}
+		if _, err := q.DeleteTermSnapshot(ctx, termID); err != nil {
+			return 0, err
+		}
+	}
+
+	return q.SnapshotMemberTerms(ctx, repository.SnapshotMemberTermsParams{
+		TermID:    termID,
+		StartedOn: startedOn,
+		EndedOn:   endedOn,
+	})
+}
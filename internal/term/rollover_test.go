@@ -0,0 +1,63 @@
+package term
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollover(t *testing.T) {
+	endedOn := sql.NullTime{Time: date("2026-08-15"), Valid: true}
+
+	expectTerm := func(mock sqlmock.Sqlmock, recorded int) {
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(12, 3, 2025, 2026, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(recorded))
+	}
+
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectTerm(mock, 0)
+		mock.ExpectExec("INSERT INTO member_terms").WithArgs(12, nil, endedOn.Time).WillReturnResult(sqlmock.NewResult(0, 42))
+
+		n, err := Rollover(context.Background(), repository.New(db), 12, sql.NullTime{}, endedOn, false)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 42, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success - replace", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectTerm(mock, 40)
+		mock.ExpectExec("DELETE FROM member_terms").WithArgs(12).WillReturnResult(sqlmock.NewResult(0, 40))
+		mock.ExpectExec("INSERT INTO member_terms").WithArgs(12, nil, endedOn.Time).WillReturnResult(sqlmock.NewResult(0, 42))
+
+		n, err := Rollover(context.Background(), repository.New(db), 12, sql.NullTime{}, endedOn, true)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 42, n)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("fail - already recorded", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		expectTerm(mock, 40)
+
+		_, err = Rollover(context.Background(), repository.New(db), 12, sql.NullTime{}, endedOn, false)
+		assert.ErrorContains(t, err, "already has 40 members recorded")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,357 @@
+package term
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dlsu-lscs/lscs-core-api/internal/audit"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/go-sql-driver/mysql"
+	"github.com/labstack/echo/v4"
+)
+
+// MySQL error numbers
+const (
+	errDuplicateEntry  = 1062
+	errRowIsReferenced = 1451
+)
+
+// termsPerYear is the number of terms in an academic year.
+const termsPerYear = 3
+
+// termFieldError is a field of a term request that cannot be applied, with the status to respond with.
+type termFieldError struct {
+	status  int
+	message string
+}
+
+func (e *termFieldError) Error() string {
+	return e.message
+}
+
+func badField(format string, args ...any) error {
+	return &termFieldError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+// decodeDate reads a field that is a "2006-01-02" date or null.
+func decodeDate(field string, raw json.RawMessage) (sql.NullTime, error) {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return sql.NullTime{}, badField("%s must be a date or null", field)
+	}
+	if value == nil {
+		return sql.NullTime{}, nil
+	}
+	t, err := time.Parse(time.DateOnly, *value)
+	if err != nil {
+		return sql.NullTime{}, badField("%s must be a date as 2006-01-02", field)
+	}
+	return sql.NullTime{Time: t, Valid: true}, nil
+}
+
+// applyTermFields sets the fields present in a request body on t. Fields that are left out are
+// not changed. The term as a whole is checked by validateTerm.
+func applyTermFields(t *repository.Term, raw map[string]json.RawMessage) error {
+	// Sorted so the first error reported does not depend on map order
+	fields := make([]string, 0, len(raw))
+	for field := range raw {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		value := raw[field]
+		switch field {
+		case "term", "start_year", "end_year":
+			var n int32
+			if err := json.Unmarshal(value, &n); err != nil {
+				return badField("%s must be a number", field)
+			}
+			switch field {
+			case "term":
+				t.Term = n
+			case "start_year":
+				t.StartYear = n
+			case "end_year":
+				t.EndYear = n
+			}
+
+		case "starts_on", "ends_on":
+			d, err := decodeDate(field, value)
+			if err != nil {
+				return err
+			}
+			if field == "starts_on" {
+				t.StartsOn = d
+			} else {
+				t.EndsOn = d
+			}
+
+		default:
+			return badField("unknown field %s", field)
+		}
+	}
+	return nil
+}
+
+// validateTerm checks that t is a term of a real academic year, and that its dates do not overlap
+// those of another term, since the current term is found by date.
+func validateTerm(ctx context.Context, q *repository.Queries, t repository.Term) error {
+	if t.Term < 1 || t.Term > termsPerYear {
+		return badField("term must be from 1 to %d", termsPerYear)
+	}
+	if t.StartYear < 1000 || t.StartYear > 9999 {
+		return badField("start_year must be a year")
+	}
+	if t.EndYear != t.StartYear+1 {
+		return badField("end_year must be the year after start_year")
+	}
+	if !t.StartsOn.Valid {
+		if t.EndsOn.Valid {
+			return badField("ends_on needs starts_on")
+		}
+		return nil
+	}
+	endsOn := t.EndsOn
+	if !endsOn.Valid {
+		endsOn = t.StartsOn
+	} else if endsOn.Time.Before(t.StartsOn.Time) {
+		return badField("ends_on must not be before starts_on")
+	}
+
+	overlapping, err := q.CountOverlappingTerms(ctx, repository.CountOverlappingTermsParams{
+		ID:       t.ID,
+		EndsOn:   endsOn,
+		StartsOn: t.StartsOn,
+	})
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return &termFieldError{status: http.StatusConflict, message: "The term's dates overlap another term"}
+	}
+	return nil
+}
+
+// readTermBody reads the JSON object of a create or update request. On failure it has already
+// written the response.
+func readTermBody(c echo.Context) (map[string]json.RawMessage, bool, error) {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, false, c.JSON(http.StatusBadRequest, map[string]string{"error": "cannot read body"})
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil || raw == nil {
+		return nil, false, c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request format"})
+	}
+	return raw, true, nil
+}
+
+// termError writes the response for an error from applying or saving term fields.
+func termError(c echo.Context, err error, action string) error {
+	var fieldErr *termFieldError
+	if errors.As(err, &fieldErr) {
+		return c.JSON(fieldErr.status, map[string]string{"error": fieldErr.message})
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case errDuplicateEntry:
+			return c.JSON(http.StatusConflict, map[string]string{"error": "The academic year already has this term"})
+		case errRowIsReferenced:
+			return c.JSON(http.StatusConflict, map[string]string{"error": "The term still has events"})
+		}
+	}
+	slog.Error("failed to "+action+" term", "error", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error saving term"})
+}
+
+// ListTermsHandler returns every term, latest first.
+func (h *Handler) ListTermsHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	terms, err := q.ListTerms(c.Request().Context())
+	if err != nil {
+		slog.Error("failed to list terms", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Internal server error"})
+	}
+
+	response := make([]TermResponse, 0, len(terms))
+	for _, t := range terms {
+		response = append(response, toTermResponse(t))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetTermHandler returns a term. The id may be "current", for the term that started most recently.
+func (h *Handler) GetTermHandler(c echo.Context) error {
+	q := repository.New(h.dbService.GetConnection())
+
+	t, status, msg := lookupParam(c, q, c.Param("id"))
+	if status != http.StatusOK {
+		return c.JSON(status, map[string]string{"error": msg})
+	}
+	return c.JSON(http.StatusOK, toTermResponse(t))
+}
+
+// CreateTermHandler adds a term. term and start_year are required; end_year defaults to the year
+// after start_year.
+func (h *Handler) CreateTermHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	q := repository.New(h.dbService.GetConnection())
+
+	raw, ok, err := readTermBody(c)
+	if !ok {
+		return err
+	}
+	if _, ok := raw["term"]; !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "term is required"})
+	}
+	if _, ok := raw["start_year"]; !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "start_year is required"})
+	}
+
+	var t repository.Term
+	if err := applyTermFields(&t, raw); err != nil {
+		return termError(c, err, "validate")
+	}
+	if _, ok := raw["end_year"]; !ok {
+		t.EndYear = t.StartYear + 1
+	}
+	if err := validateTerm(ctx, q, t); err != nil {
+		return termError(c, err, "validate")
+	}
+
+	id, err := q.CreateTerm(ctx, repository.CreateTermParams{
+		Term:      t.Term,
+		StartYear: t.StartYear,
+		EndYear:   t.EndYear,
+		StartsOn:  t.StartsOn,
+		EndsOn:    t.EndsOn,
+	})
+	if err != nil {
+		return termError(c, err, "create")
+	}
+	audit.SetTarget(c, audit.TargetTerm, strconv.FormatInt(id, 10))
+
+	t.ID = int32(id)
+	return c.JSON(http.StatusCreated, toTermResponse(t))
+}
+
+// UpdateTermHandler changes the fields of a term present in the body. starts_on and ends_on set to
+// null are cleared.
+func (h *Handler) UpdateTermHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid term id"})
+	}
+	audit.SetTarget(c, audit.TargetTerm, strconv.Itoa(id))
+
+	raw, ok, err := readTermBody(c)
+	if !ok {
+		return err
+	}
+	if _, ok := raw["id"]; ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "id cannot be changed"})
+	}
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error saving term"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	t, err := qtx.GetTermForUpdate(ctx, int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Term not found"})
+		}
+		return termError(c, err, "get")
+	}
+
+	if err := applyTermFields(&t, raw); err != nil {
+		return termError(c, err, "validate")
+	}
+	if err := validateTerm(ctx, qtx, t); err != nil {
+		return termError(c, err, "validate")
+	}
+
+	if err := qtx.UpdateTerm(ctx, repository.UpdateTermParams{
+		Term:      t.Term,
+		StartYear: t.StartYear,
+		EndYear:   t.EndYear,
+		StartsOn:  t.StartsOn,
+		EndsOn:    t.EndsOn,
+		ID:        t.ID,
+	}); err != nil {
+		return termError(c, err, "update")
+	}
+	if err := tx.Commit(); err != nil {
+		return termError(c, err, "commit")
+	}
+
+	return c.JSON(http.StatusOK, toTermResponse(t))
+}
+
+// DeleteTermHandler removes a term that was added by mistake. Terms with recorded members or with
+// events are kept, since deleting them would lose that history.
+func (h *Handler) DeleteTermHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	dbconn := h.dbService.GetConnection()
+	q := repository.New(dbconn)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid term id"})
+	}
+	audit.SetTarget(c, audit.TargetTerm, strconv.Itoa(id))
+
+	tx, err := dbconn.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to begin transaction", "error", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error deleting term"})
+	}
+	defer tx.Rollback()
+	qtx := q.WithTx(tx)
+
+	if _, err := qtx.GetTermForUpdate(ctx, int32(id)); err != nil {
+		if err == sql.ErrNoRows {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Term not found"})
+		}
+		return termError(c, err, "get")
+	}
+	recorded, err := qtx.CountTermSnapshot(ctx, int32(id))
+	if err != nil {
+		return termError(c, err, "check")
+	}
+	if recorded > 0 {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "The term has recorded members"})
+	}
+
+	if _, err := qtx.DeleteTerm(ctx, int32(id)); err != nil {
+		return termError(c, err, "delete")
+	}
+	if err := tx.Commit(); err != nil {
+		return termError(c, err, "commit")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": "Term deleted",
+		"term_id": id,
+	})
+}
@@ -0,0 +1,286 @@
+package term
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/dlsu-lscs/lscs-core-api/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTermRequest(method, body, id string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/admin/terms", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+	return c, rec
+}
+
+func TestGetTermHandler(t *testing.T) {
+	t.Run("success - current", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE starts_on <= CURDATE()").
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(13, 1, 2026, 2027, date("2026-09-07"), date("2026-12-12")))
+
+		c, rec := newTermContext("/terms/current", "current")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.GetTermHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"id": 13, "term": 1, "start_year": 2026, "end_year": 2027, "name": "AY 2026-2027 Term 1", "starts_on": "2026-09-07", "ends_on": "2026-12-12"}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - no term has started", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE starts_on <= CURDATE()").WillReturnError(sql.ErrNoRows)
+
+		c, rec := newTermContext("/terms/current", "current")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.GetTermHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - invalid id", func(t *testing.T) {
+		c, rec := newTermContext("/terms/latest", "latest")
+		h := NewHandler(&mockDBService{})
+
+		if assert.NoError(t, h.GetTermHandler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestCreateTermHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM terms").WithArgs(0, date("2026-12-12"), date("2026-09-07")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("INSERT INTO terms").WithArgs(1, 2026, 2027, date("2026-09-07"), date("2026-12-12")).
+			WillReturnResult(sqlmock.NewResult(13, 1))
+
+		c, rec := newTermRequest(http.MethodPost, `{"term": 1, "start_year": 2026, "starts_on": "2026-09-07", "ends_on": "2026-12-12"}`, "")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.CreateTermHandler(c)) {
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.JSONEq(t, `{"id": 13, "term": 1, "start_year": 2026, "end_year": 2027, "name": "AY 2026-2027 Term 1", "starts_on": "2026-09-07", "ends_on": "2026-12-12"}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - overlaps another term", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM terms").WithArgs(0, date("2026-12-12"), date("2026-08-01")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		c, rec := newTermRequest(http.MethodPost, `{"term": 1, "start_year": 2026, "starts_on": "2026-08-01", "ends_on": "2026-12-12"}`, "")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.CreateTermHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	fails := []struct {
+		name, body string
+	}{
+		{"missing start_year", `{"term": 1}`},
+		{"term out of range", `{"term": 4, "start_year": 2026}`},
+		{"end_year not after start_year", `{"term": 1, "start_year": 2026, "end_year": 2028}`},
+		{"ends before it starts", `{"term": 1, "start_year": 2026, "starts_on": "2026-12-12", "ends_on": "2026-09-07"}`},
+		{"invalid date", `{"term": 1, "start_year": 2026, "starts_on": "09/07/2026"}`},
+		{"unknown field", `{"term": 1, "start_year": 2026, "active": true}`},
+	}
+	for _, tc := range fails {
+		t.Run("fail - "+tc.name, func(t *testing.T) {
+			c, rec := newTermRequest(http.MethodPost, tc.body, "")
+			h := NewHandler(&mockDBService{})
+
+			if assert.NoError(t, h.CreateTermHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+			}
+		})
+	}
+}
+
+func TestUpdateTermHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = \\? FOR UPDATE").WithArgs(13).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(13, 1, 2026, 2027, date("2026-09-07"), nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM terms").WithArgs(13, date("2026-12-19"), date("2026-09-07")).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("UPDATE terms SET").WithArgs(1, 2026, 2027, date("2026-09-07"), date("2026-12-19"), 13).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		c, rec := newTermRequest(http.MethodPatch, `{"ends_on": "2026-12-19"}`, "13")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.UpdateTermHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"id": 13, "term": 1, "start_year": 2026, "end_year": 2027, "name": "AY 2026-2027 Term 1", "starts_on": "2026-09-07", "ends_on": "2026-12-19"}`, rec.Body.String())
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - term not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = \\? FOR UPDATE").WithArgs(99).WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		c, rec := newTermRequest(http.MethodPatch, `{"ends_on": null}`, "99")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.UpdateTermHandler(c)) {
+			assert.Equal(t, http.StatusNotFound, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
+
+func TestDeleteTermHandler(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = \\? FOR UPDATE").WithArgs(13).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(13, 1, 2026, 2027, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms").WithArgs(13).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("DELETE FROM terms").WithArgs(13).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		c, rec := newTermRequest(http.MethodDelete, "", "13")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeleteTermHandler(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+
+	t.Run("fail - term has recorded members", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = \\? FOR UPDATE").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(12, 3, 2025, 2026, nil, nil))
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms").WithArgs(12).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(40))
+		mock.ExpectRollback()
+
+		c, rec := newTermRequest(http.MethodDelete, "", "12")
+		h := NewHandler(&mockDBService{db: db})
+
+		if assert.NoError(t, h.DeleteTermHandler(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		}
+	})
+}
+
+func TestPastFromQuery(t *testing.T) {
+	future := time.Now().AddDate(0, 1, 0)
+	byID := func(mock sqlmock.Sqlmock, id int, startsOn any) {
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE id = ?").WithArgs(id).
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(id, 1, 2026, 2027, startsOn, nil))
+	}
+	current := func(mock sqlmock.Sqlmock) {
+		mock.ExpectQuery("SELECT (.+) FROM terms WHERE starts_on <= CURDATE()").
+			WillReturnRows(sqlmock.NewRows(termColumns).AddRow(13, 1, 2026, 2027, date("2026-09-07"), nil))
+	}
+	snapshot := func(mock sqlmock.Sqlmock, id, n int) {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM member_terms").WithArgs(id).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(n))
+	}
+
+	tests := []struct {
+		name   string
+		query  string
+		expect func(sqlmock.Sqlmock)
+		past   bool
+		status int
+	}{
+		{name: "no term", query: "", expect: func(sqlmock.Sqlmock) {}, status: http.StatusOK},
+		{name: "earlier term", query: "12", expect: func(m sqlmock.Sqlmock) {
+			byID(m, 12, date("2026-05-04"))
+			snapshot(m, 12, 0)
+			current(m)
+		}, past: true, status: http.StatusOK},
+		{name: "current term by id", query: "13", expect: func(m sqlmock.Sqlmock) {
+			byID(m, 13, date("2026-09-07"))
+			snapshot(m, 13, 0)
+			current(m)
+		}, status: http.StatusOK},
+		{name: "current term", query: "current", expect: func(m sqlmock.Sqlmock) {
+			current(m)
+			snapshot(m, 13, 0)
+		}, status: http.StatusOK},
+		// After a rollover the current term is recorded, and the live roster may already be the next one
+		{name: "current term after rollover", query: "current", expect: func(m sqlmock.Sqlmock) {
+			current(m)
+			snapshot(m, 13, 85)
+		}, past: true, status: http.StatusOK},
+		{name: "future term", query: "14", expect: func(m sqlmock.Sqlmock) {
+			byID(m, 14, future)
+		}, status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			assert.NoError(t, err)
+			defer db.Close()
+			tt.expect(mock)
+
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/members?term="+tt.query, nil), httptest.NewRecorder())
+			_, past, status, _ := PastFromQuery(c, repository.New(db))
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.past, past)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -12,7 +12,7 @@ LEFT JOIN committees c ON m.committee_id = c.committee_id
 LEFT JOIN divisions d ON c.division_id = d.division_id
 LEFT JOIN positions p ON m.position_id = p.position_id
 LEFT JOIN houses h ON m.house_id = h.id
-WHERE m.email = ?;
+WHERE m.email = ? AND m.status = 'ACTIVE';
 
 -- name: GetMemberInfoById :one
 SELECT 
@@ -28,33 +28,283 @@ LEFT JOIN committees c ON m.committee_id = c.committee_id
 LEFT JOIN divisions d ON c.division_id = d.division_id
 LEFT JOIN positions p ON m.position_id = p.position_id
 LEFT JOIN houses h ON m.house_id = h.id
-WHERE m.id = ?;
+WHERE m.id = ? AND m.status = 'ACTIVE';
 
--- name: ListMembers :many
+-- name: SearchMembers :many
+-- Members are ordered by sort_key, then id, so that a page can start after the (sort_key, id)
+-- of the last member on the previous page.
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program,
+    discord, interests, contact_number, fb_link, house_name
+FROM (
+    SELECT
+        m.id,
+        m.full_name,
+        m.nickname,
+        m.email,
+        m.telegram,
+        m.position_id,
+        m.committee_id,
+        m.college,
+        m.program,
+        m.discord,
+        m.interests,
+        m.contact_number,
+        m.fb_link,
+        h.name as house_name,
+        CASE sqlc.arg(sort_by)
+            WHEN 'email' THEN m.email
+            WHEN 'id' THEN LPAD(m.id, 10, '0')
+            ELSE m.full_name
+        END AS sort_key
+    FROM members m
+    LEFT JOIN committees c ON m.committee_id = c.committee_id
+    LEFT JOIN houses h ON m.house_id = h.id
+    WHERE m.status = 'ACTIVE'
+      AND (sqlc.narg(committee_id) IS NULL OR m.committee_id = sqlc.narg(committee_id))
+      AND (sqlc.narg(division_id) IS NULL OR c.division_id = sqlc.narg(division_id))
+      AND (sqlc.narg(position_id) IS NULL OR m.position_id = sqlc.narg(position_id))
+      AND (sqlc.narg(house) IS NULL OR h.name = sqlc.narg(house))
+      AND (sqlc.narg(college) IS NULL OR m.college = sqlc.narg(college))
+      AND (sqlc.narg(program) IS NULL OR m.program = sqlc.narg(program))
+      AND (sqlc.narg(search) IS NULL
+        OR m.full_name LIKE sqlc.narg(search)
+        OR m.nickname LIKE sqlc.narg(search)
+        OR m.email LIKE sqlc.narg(search))
+) AS matched
+WHERE sqlc.narg(after_key) IS NULL
+    OR IF(sqlc.arg(descending),
+        (sort_key, id) < (sqlc.narg(after_key), sqlc.narg(after_id)),
+        (sort_key, id) > (sqlc.narg(after_key), sqlc.narg(after_id)))
+ORDER BY
+    CASE WHEN sqlc.arg(descending) THEN sort_key END DESC,
+    CASE WHEN sqlc.arg(descending) THEN id END DESC,
+    sort_key,
+    id
+LIMIT ?;
+
+-- name: CountMembers :one
+SELECT COUNT(*) AS total
+FROM members m
+LEFT JOIN committees c ON m.committee_id = c.committee_id
+LEFT JOIN houses h ON m.house_id = h.id
+WHERE m.status = 'ACTIVE'
+  AND (sqlc.narg(committee_id) IS NULL OR m.committee_id = sqlc.narg(committee_id))
+  AND (sqlc.narg(division_id) IS NULL OR c.division_id = sqlc.narg(division_id))
+  AND (sqlc.narg(position_id) IS NULL OR m.position_id = sqlc.narg(position_id))
+  AND (sqlc.narg(house) IS NULL OR h.name = sqlc.narg(house))
+  AND (sqlc.narg(college) IS NULL OR m.college = sqlc.narg(college))
+  AND (sqlc.narg(program) IS NULL OR m.program = sqlc.narg(program))
+  AND (sqlc.narg(search) IS NULL
+    OR m.full_name LIKE sqlc.narg(search)
+    OR m.nickname LIKE sqlc.narg(search)
+    OR m.email LIKE sqlc.narg(search));
+
+-- name: SearchTermMembers :many
+-- SearchMembers for the members recorded in a past term, with the committee, position and house
+-- each one had then.
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program,
+    discord, interests, contact_number, fb_link, house_name
+FROM (
+    SELECT
+        m.id,
+        m.full_name,
+        m.nickname,
+        m.email,
+        m.telegram,
+        mt.position_id,
+        mt.committee_id,
+        m.college,
+        m.program,
+        m.discord,
+        m.interests,
+        m.contact_number,
+        m.fb_link,
+        h.name as house_name,
+        CASE sqlc.arg(sort_by)
+            WHEN 'email' THEN m.email
+            WHEN 'id' THEN LPAD(m.id, 10, '0')
+            ELSE m.full_name
+        END AS sort_key
+    FROM member_terms mt
+    JOIN members m ON mt.member_id = m.id
+    LEFT JOIN committees c ON mt.committee_id = c.committee_id
+    LEFT JOIN houses h ON mt.house_id = h.id
+    WHERE mt.term_id = sqlc.arg(term_id)
+      AND (sqlc.narg(committee_id) IS NULL OR mt.committee_id = sqlc.narg(committee_id))
+      AND (sqlc.narg(division_id) IS NULL OR c.division_id = sqlc.narg(division_id))
+      AND (sqlc.narg(position_id) IS NULL OR mt.position_id = sqlc.narg(position_id))
+      AND (sqlc.narg(house) IS NULL OR h.name = sqlc.narg(house))
+      AND (sqlc.narg(college) IS NULL OR m.college = sqlc.narg(college))
+      AND (sqlc.narg(program) IS NULL OR m.program = sqlc.narg(program))
+      AND (sqlc.narg(search) IS NULL
+        OR m.full_name LIKE sqlc.narg(search)
+        OR m.nickname LIKE sqlc.narg(search)
+        OR m.email LIKE sqlc.narg(search))
+) AS matched
+WHERE sqlc.narg(after_key) IS NULL
+    OR IF(sqlc.arg(descending),
+        (sort_key, id) < (sqlc.narg(after_key), sqlc.narg(after_id)),
+        (sort_key, id) > (sqlc.narg(after_key), sqlc.narg(after_id)))
+ORDER BY
+    CASE WHEN sqlc.arg(descending) THEN sort_key END DESC,
+    CASE WHEN sqlc.arg(descending) THEN id END DESC,
+    sort_key,
+    id
+LIMIT ?;
+
+-- name: CountTermMembers :one
+SELECT COUNT(*) AS total
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.term_id = sqlc.arg(term_id)
+  AND (sqlc.narg(committee_id) IS NULL OR mt.committee_id = sqlc.narg(committee_id))
+  AND (sqlc.narg(division_id) IS NULL OR c.division_id = sqlc.narg(division_id))
+  AND (sqlc.narg(position_id) IS NULL OR mt.position_id = sqlc.narg(position_id))
+  AND (sqlc.narg(house) IS NULL OR h.name = sqlc.narg(house))
+  AND (sqlc.narg(college) IS NULL OR m.college = sqlc.narg(college))
+  AND (sqlc.narg(program) IS NULL OR m.program = sqlc.narg(program))
+  AND (sqlc.narg(search) IS NULL
+    OR m.full_name LIKE sqlc.narg(search)
+    OR m.nickname LIKE sqlc.narg(search)
+    OR m.email LIKE sqlc.narg(search));
+
+-- name: ExportMembers :many
 SELECT
-    m.id,
-    m.full_name,
-    m.nickname,
-    m.email,
-    m.telegram,
-    m.position_id,
-    m.committee_id,
-    m.college,
-    m.program,
-    m.discord,
-    m.interests,
-    m.contact_number,
-    m.fb_link,
-    h.name as house_name
+  m.id, m.email, m.full_name, m.nickname,
+  c.committee_id, c.committee_name,
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  m.contact_number, m.college, m.program,
+  m.interests, m.discord, m.fb_link, m.telegram
 FROM members m
+LEFT JOIN committees c ON m.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON m.position_id = p.position_id
 LEFT JOIN houses h ON m.house_id = h.id
-ORDER BY m.email;
+WHERE m.status = 'ACTIVE'
+  AND (sqlc.narg('committee_id') IS NULL OR m.committee_id = sqlc.narg('committee_id'))
+  AND (sqlc.narg('division_id') IS NULL OR c.division_id = sqlc.narg('division_id'))
+  AND (sqlc.narg('house') IS NULL OR h.name = sqlc.narg('house'))
+ORDER BY m.full_name, m.id;
+
+-- name: ExportTermMembers :many
+SELECT
+  m.id, m.email, m.full_name, m.nickname,
+  c.committee_id, c.committee_name,
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  m.contact_number, m.college, m.program,
+  m.interests, m.discord, m.fb_link, m.telegram
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.term_id = sqlc.arg('term_id')
+  AND (sqlc.narg('committee_id') IS NULL OR mt.committee_id = sqlc.narg('committee_id'))
+  AND (sqlc.narg('division_id') IS NULL OR c.division_id = sqlc.narg('division_id'))
+  AND (sqlc.narg('house') IS NULL OR h.name = sqlc.narg('house'))
+ORDER BY m.full_name, m.id;
+
+-- name: GetMemberProfileForUpdate :one
+SELECT id, nickname, telegram, discord, interests, contact_number, fb_link
+FROM members
+WHERE email = ? AND status = 'ACTIVE'
+FOR UPDATE;
+
+-- name: UpdateMemberProfile :exec
+UPDATE members
+SET nickname = ?, telegram = ?, discord = ?, interests = ?, contact_number = ?, fb_link = ?
+WHERE id = ?;
+
+-- name: AdminGetMember :one
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id, status, deactivated_at
+FROM members
+WHERE id = ?;
+
+-- name: AdminListMembers :many
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id, status, deactivated_at
+FROM members
+ORDER BY id;
+
+-- name: AdminGetMemberForUpdate :one
+SELECT id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id, status, deactivated_at
+FROM members
+WHERE id = ?
+FOR UPDATE;
+
+-- name: GetMemberIDByEmail :one
+SELECT id FROM members WHERE email = ?;
+
+-- name: CreateMember :exec
+INSERT INTO members (
+    id, full_name, nickname, email, telegram, position_id, committee_id, college, program, discord, interests, contact_number, fb_link, house_id
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: UpdateMember :exec
+UPDATE members
+SET full_name = ?, nickname = ?, email = ?, telegram = ?, position_id = ?, committee_id = ?, college = ?, program = ?,
+    discord = ?, interests = ?, contact_number = ?, fb_link = ?, house_id = ?
+WHERE id = ?;
+
+-- name: DeactivateMember :execrows
+UPDATE members SET status = 'INACTIVE', deactivated_at = CURRENT_TIMESTAMP WHERE id = ? AND status = 'ACTIVE';
+
+-- name: ReactivateMember :execrows
+UPDATE members SET status = 'ACTIVE', deactivated_at = NULL WHERE id = ? AND status = 'INACTIVE';
+
+-- name: PositionExists :one
+SELECT EXISTS(SELECT 1 FROM positions WHERE position_id = ?);
+
+-- name: CommitteeExists :one
+SELECT EXISTS(SELECT 1 FROM committees WHERE committee_id = ?);
+
+-- name: HouseExists :one
+SELECT EXISTS(SELECT 1 FROM houses WHERE id = ?);
 
 -- name: CheckEmailIfMember :one
-SELECT email FROM members WHERE email = ?;
+SELECT email FROM members WHERE email = ? AND status = 'ACTIVE';
 
 -- name: CheckIdIfMember :one
-SELECT id FROM members WHERE id = ?;
+SELECT id FROM members WHERE id = ? AND status = 'ACTIVE';
+
+-- name: GetMemberEmailBySecondaryEmail :one
+SELECT m.email
+FROM member_emails e
+JOIN members m ON m.id = e.member_id
+WHERE e.email = ? AND m.status = 'ACTIVE';
+
+-- name: ListAllMemberEmails :many
+SELECT email, member_id FROM member_emails;
+
+-- name: ListMemberEmails :many
+SELECT email, created_at FROM member_emails WHERE member_id = ? ORDER BY created_at;
+
+-- name: AddMemberEmail :exec
+INSERT INTO member_emails (email, member_id) VALUES (?, ?);
+
+-- name: DeleteMemberEmail :execrows
+DELETE FROM member_emails WHERE member_id = ? AND email = ?;
+
+-- name: GetMemberPrivacy :many
+SELECT field, visibility FROM member_privacy WHERE member_id = ?;
+
+-- name: ListAllMemberPrivacy :many
+SELECT member_id, field, visibility FROM member_privacy;
+
+-- name: SetMemberPrivacy :exec
+INSERT INTO member_privacy (member_id, field, visibility) VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE visibility = VALUES(visibility);
+
+-- name: DeleteMemberPrivacy :exec
+DELETE FROM member_privacy WHERE member_id = ? AND field = ?;
 
 -- name: GetAllCommittees :many
 SELECT c.committee_id, c.committee_name, c.committee_head, c.division_id FROM committees c;
@@ -62,7 +312,128 @@ SELECT c.committee_id, c.committee_name, c.committee_head, c.division_id FROM co
 -- name: GetAllDivisions :many
 SELECT d.division_id, d.division_name, d.division_head FROM divisions d;
 
--- name: StoreAPIKey :exec
+-- name: ListOrgHeads :many
+-- Active members who head a division or a committee. A post whose head was deactivated shows as vacant.
+SELECT m.id, m.full_name, m.nickname, m.position_id, p.position_name
+FROM members m
+LEFT JOIN positions p ON m.position_id = p.position_id
+WHERE m.status = 'ACTIVE'
+  AND (m.id IN (SELECT division_head FROM divisions WHERE division_head IS NOT NULL)
+    OR m.id IN (SELECT committee_head FROM committees WHERE committee_head IS NOT NULL));
+
+-- name: CountMembersByCommittee :many
+SELECT committee_id, COUNT(*) AS member_count
+FROM members
+WHERE status = 'ACTIVE'
+GROUP BY committee_id;
+
+-- name: ListOrgMembers :many
+SELECT m.id, m.full_name, m.nickname, m.committee_id, m.position_id, p.position_name
+FROM members m
+LEFT JOIN positions p ON m.position_id = p.position_id
+WHERE m.status = 'ACTIVE'
+ORDER BY m.full_name, m.id;
+
+-- name: CountTermMembersByCommittee :many
+SELECT committee_id, COUNT(*) AS member_count
+FROM member_terms
+WHERE term_id = ?
+GROUP BY committee_id;
+
+-- name: ListTermOrgMembers :many
+SELECT m.id, m.full_name, m.nickname, mt.committee_id, mt.position_id, p.position_name
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+WHERE mt.term_id = ?
+ORDER BY m.full_name, m.id;
+
+-- name: ListPositions :many
+SELECT position_id, position_name FROM positions;
+
+-- name: ListHouses :many
+SELECT id, name, description FROM houses;
+
+-- name: GetTerm :one
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms WHERE id = ?;
+
+-- name: GetTermForUpdate :one
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms WHERE id = ? FOR UPDATE;
+
+-- name: GetCurrentTerm :one
+-- The term that started most recently, so a term stays current through the break until the next one starts.
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms
+WHERE starts_on <= CURDATE()
+ORDER BY starts_on DESC
+LIMIT 1;
+
+-- name: ListTerms :many
+SELECT id, term, start_year, end_year, starts_on, ends_on FROM terms
+ORDER BY start_year DESC, term DESC;
+
+-- name: CreateTerm :execlastid
+INSERT INTO terms (term, start_year, end_year, starts_on, ends_on) VALUES (?, ?, ?, ?, ?);
+
+-- name: UpdateTerm :exec
+UPDATE terms SET term = ?, start_year = ?, end_year = ?, starts_on = ?, ends_on = ? WHERE id = ?;
+
+-- name: DeleteTerm :execrows
+DELETE FROM terms WHERE id = ?;
+
+-- name: CountOverlappingTerms :one
+-- Terms other than id whose dates overlap starts_on to ends_on. A term without an end date is
+-- taken to end on the day it starts.
+SELECT COUNT(*) FROM terms
+WHERE id <> sqlc.arg(id)
+  AND starts_on IS NOT NULL
+  AND starts_on <= sqlc.arg(ends_on)
+  AND COALESCE(ends_on, starts_on) >= sqlc.arg(starts_on);
+
+-- name: ListMemberTerms :many
+SELECT
+  t.id AS term_id, t.term, t.start_year, t.end_year, t.starts_on, t.ends_on,
+  c.committee_id, c.committee_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  mt.started_on, mt.ended_on
+FROM member_terms mt
+JOIN terms t ON mt.term_id = t.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.member_id = ?
+ORDER BY t.start_year DESC, t.term DESC;
+
+-- name: ListTermRoster :many
+SELECT
+  m.id, m.full_name, m.nickname,
+  c.committee_id, c.committee_name,
+  d.division_id, d.division_name,
+  p.position_id, p.position_name,
+  h.name as house_name,
+  mt.started_on, mt.ended_on
+FROM member_terms mt
+JOIN members m ON mt.member_id = m.id
+LEFT JOIN committees c ON mt.committee_id = c.committee_id
+LEFT JOIN divisions d ON c.division_id = d.division_id
+LEFT JOIN positions p ON mt.position_id = p.position_id
+LEFT JOIN houses h ON mt.house_id = h.id
+WHERE mt.term_id = ?
+ORDER BY m.full_name, m.id;
+
+-- name: CountTermSnapshot :one
+SELECT COUNT(*) FROM member_terms WHERE term_id = ?;
+
+-- name: DeleteTermSnapshot :execrows
+DELETE FROM member_terms WHERE term_id = ?;
+
+-- name: SnapshotMemberTerms :execrows
+INSERT INTO member_terms (member_id, term_id, committee_id, position_id, house_id, started_on, ended_on)
+SELECT id, sqlc.arg(term_id), committee_id, position_id, house_id, sqlc.narg(started_on), sqlc.narg(ended_on)
+FROM members
+WHERE status = 'ACTIVE';
+
+-- name: StoreAPIKey :execlastid
 INSERT INTO api_keys (
     member_email,
     api_key_hash,
@@ -70,25 +441,201 @@ INSERT INTO api_keys (
     allowed_origin,
     is_dev,
     is_admin,
+    scopes,
     expires_at
 ) VALUES (
-    ?, ?, ?, ?, ?, ?, ?
+    ?, ?, ?, ?, ?, ?, ?, ?
 );
 
 -- name: GetAPIKeyInfo :one
-SELECT api_key_id, member_email, api_key_hash, project, allowed_origin, is_dev, is_admin, created_at, expires_at FROM api_keys WHERE api_key_hash = ?;
+SELECT k.api_key_id, k.member_email, k.api_key_hash, k.project, k.allowed_origin, k.is_dev, k.is_admin, k.scopes, k.created_at, k.expires_at, k.last_used_at, k.request_count
+FROM api_keys k
+JOIN members m ON m.email = k.member_email
+WHERE k.api_key_hash = ? AND m.status = 'ACTIVE';
+
+-- name: RevokeAPIKey :execrows
+DELETE FROM api_keys WHERE api_key_id = ? AND member_email = ?;
+
+-- name: ListAPIKeysByEmail :many
+SELECT api_key_id, member_email, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at
+FROM api_keys
+WHERE member_email = ?
+ORDER BY created_at DESC;
+
+-- name: GetAPIKeyByIDAndEmail :one
+SELECT api_key_id, member_email, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at
+FROM api_keys
+WHERE api_key_id = ? AND member_email = ?;
+
+-- name: TouchAPIKey :exec
+-- Adds requests counted in memory to a key's request_count and sets when it was last used.
+UPDATE api_keys SET last_used_at = ?, request_count = request_count + ? WHERE api_key_id = ?;
+
+-- name: ListAllAPIKeys :many
+SELECT api_key_id, member_email, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at, last_used_at, request_count
+FROM api_keys
+ORDER BY created_at DESC;
 
--- name: DeleteAPIKey :exec
-DELETE FROM api_keys WHERE member_email = ? LIMIT 1;
+-- name: GetAPIKeyUsage :one
+SELECT api_key_id, member_email, project, created_at, expires_at, last_used_at, request_count
+FROM api_keys
+WHERE api_key_id = ?;
+
+-- name: AdminRevokeAPIKey :execrows
+DELETE FROM api_keys WHERE api_key_id = ?;
+
+-- name: RecordAPIKeyDailyUsage :exec
+INSERT INTO api_key_daily_usage (api_key_id, usage_date, request_count, rate_limited_count)
+VALUES (?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    request_count = request_count + VALUES(request_count),
+    rate_limited_count = rate_limited_count + VALUES(rate_limited_count);
+
+-- name: ListAPIKeyDailyUsage :many
+SELECT usage_date, request_count, rate_limited_count
+FROM api_key_daily_usage
+WHERE api_key_id = ? AND usage_date >= ?
+ORDER BY usage_date DESC;
+
+-- name: ListDailyUsage :many
+SELECT u.usage_date, u.api_key_id, k.member_email, k.project, u.request_count, u.rate_limited_count
+FROM api_key_daily_usage u
+LEFT JOIN api_keys k ON k.api_key_id = u.api_key_id
+WHERE u.usage_date >= ?
+ORDER BY u.usage_date DESC, u.request_count DESC;
+
+-- name: RecordUsageBucket :exec
+INSERT INTO api_usage_buckets (bucket_start, api_key_id, project, route, request_count, error_count, total_latency_ms, max_latency_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON DUPLICATE KEY UPDATE
+    request_count = request_count + VALUES(request_count),
+    error_count = error_count + VALUES(error_count),
+    total_latency_ms = total_latency_ms + VALUES(total_latency_ms),
+    max_latency_ms = GREATEST(max_latency_ms, VALUES(max_latency_ms));
+
+-- name: ListUsageByRoute :many
+SELECT
+    project,
+    route,
+    CAST(COUNT(DISTINCT api_key_id) AS SIGNED) AS key_count,
+    CAST(SUM(request_count) AS SIGNED) AS request_count,
+    CAST(SUM(error_count) AS SIGNED) AS error_count,
+    CAST(SUM(total_latency_ms) AS SIGNED) AS total_latency_ms,
+    CAST(MAX(max_latency_ms) AS SIGNED) AS max_latency_ms,
+    CAST(MAX(bucket_start) AS DATETIME) AS last_seen
+FROM api_usage_buckets
+WHERE bucket_start >= sqlc.arg('since')
+  AND bucket_start < sqlc.arg('until')
+  AND (sqlc.narg('project') IS NULL OR project = sqlc.narg('project'))
+  AND (sqlc.narg('route') IS NULL OR route = sqlc.narg('route'))
+GROUP BY project, route
+ORDER BY request_count DESC, project, route;
+
+-- name: RecordAuditEvent :exec
+INSERT INTO audit_events (
+    actor_email,
+    actor_api_key_id,
+    action,
+    route,
+    target_type,
+    target_id,
+    outcome,
+    status_code,
+    client_ip,
+    details
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+);
+
+-- name: ListAuditEvents :many
+SELECT id, actor_email, actor_api_key_id, action, route, target_type, target_id, outcome, status_code, client_ip, details, created_at
+FROM audit_events
+WHERE (sqlc.narg('actor_email') IS NULL OR actor_email = sqlc.narg('actor_email'))
+  AND (sqlc.narg('target_type') IS NULL OR target_type = sqlc.narg('target_type'))
+  AND (sqlc.narg('target_id') IS NULL OR target_id = sqlc.narg('target_id'))
+  AND (sqlc.narg('since') IS NULL OR created_at >= sqlc.narg('since'))
+  AND (sqlc.narg('until') IS NULL OR created_at < sqlc.narg('until'))
+ORDER BY created_at DESC, id DESC
+LIMIT ? OFFSET ?;
+
+-- name: CreateOAuthClient :exec
+INSERT INTO oauth_clients (client_id, client_secret_hash, name, redirect_uris, owner_email)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetOAuthClient :one
+SELECT client_id, client_secret_hash, name, redirect_uris, owner_email, created_at
+FROM oauth_clients
+WHERE client_id = ?;
+
+-- name: ListOAuthClients :many
+SELECT client_id, client_secret_hash IS NOT NULL AS confidential, name, redirect_uris, owner_email, created_at
+FROM oauth_clients
+ORDER BY created_at DESC;
+
+-- name: DeleteOAuthClient :execrows
+DELETE FROM oauth_clients WHERE client_id = ?;
+
+-- name: StoreOAuthCode :exec
+INSERT INTO oauth_auth_codes (code_hash, client_id, member_email, redirect_uri, scope, nonce, code_challenge, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: GetOAuthCodeForUpdate :one
+SELECT code_hash, client_id, member_email, redirect_uri, scope, nonce, code_challenge, expires_at, created_at
+FROM oauth_auth_codes
+WHERE code_hash = ?
+FOR UPDATE;
+
+-- name: DeleteOAuthCode :exec
+DELETE FROM oauth_auth_codes WHERE code_hash = ?;
+
+-- name: DeleteExpiredOAuthCodes :execrows
+DELETE FROM oauth_auth_codes WHERE expires_at < CURRENT_TIMESTAMP;
+
+-- name: CreateMemberSession :execlastid
+INSERT INTO member_sessions (member_email, refresh_token_hash, user_agent, client_ip, expires_at)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetMemberSessionByRefreshTokenForUpdate :one
+SELECT id, member_email, refresh_token_hash, previous_token_hash, user_agent, client_ip, created_at, refreshed_at, expires_at, revoked_at
+FROM member_sessions
+WHERE refresh_token_hash = ?
+FOR UPDATE;
+
+-- name: GetMemberSessionByPreviousToken :one
+SELECT id, member_email, refresh_token_hash, previous_token_hash, user_agent, client_ip, created_at, refreshed_at, expires_at, revoked_at
+FROM member_sessions
+WHERE previous_token_hash = ?;
+
+-- name: GetActiveMemberSession :one
+SELECT id, member_email
+FROM member_sessions
+WHERE id = ? AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: RotateMemberSessionToken :exec
+UPDATE member_sessions
+SET previous_token_hash = refresh_token_hash,
+    refresh_token_hash = ?,
+    refreshed_at = CURRENT_TIMESTAMP,
+    expires_at = ?
+WHERE id = ?;
+
+-- name: RevokeMemberSession :execrows
+UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL;
+
+-- name: RevokeMemberSessions :execrows
+UPDATE member_sessions SET revoked_at = CURRENT_TIMESTAMP WHERE member_email = ? AND revoked_at IS NULL;
 
 -- name: GetAllAPIKeyHashes :many
 SELECT api_key_hash FROM api_keys;
 
 -- name: GetAPIKeyInfoWithEmail :one
-SELECT api_key_id, member_email, api_key_hash, project, allowed_origin, is_dev, is_admin, created_at, expires_at FROM api_keys WHERE member_email = ?;
+SELECT api_key_id, member_email, api_key_hash, project, allowed_origin, is_dev, is_admin, scopes, created_at, expires_at, last_used_at, request_count FROM api_keys WHERE member_email = ?;
 
 -- name: GetEmailsInAPIKey :many
 SELECT member_email FROM api_keys;
 
 -- name: CheckAllowedOriginExists :one
-SELECT EXISTS(SELECT 1 FROM api_keys WHERE allowed_origin = ? AND is_dev = false);
\ No newline at end of file
+SELECT EXISTS(SELECT 1 FROM api_keys WHERE allowed_origin = ? AND is_dev = false);
+
+-- name: ListAllowedOrigins :many
+SELECT DISTINCT allowed_origin FROM api_keys WHERE allowed_origin IS NOT NULL AND is_dev = false;
\ No newline at end of file
@@ -73,11 +73,16 @@ CREATE TABLE pub_req_status (
 );
 
 -- Table: terms
+-- A term of an academic year. The current term is the one that started most recently.
 CREATE TABLE terms (
     id INT AUTO_INCREMENT PRIMARY KEY,
     term INT NOT NULL,
     start_year INT NOT NULL,
-    end_year INT NOT NULL
+    end_year INT NOT NULL,
+    starts_on DATE,
+    ends_on DATE,
+    UNIQUE KEY uq_terms_year_term (start_year, term),
+    INDEX idx_terms_starts_on (starts_on)
 );
 
 -- Table: members
@@ -96,6 +101,9 @@ CREATE TABLE members (
     contact_number VARCHAR(32),
     fb_link VARCHAR(255),
     house_id INT,
+    -- Members are never deleted, so their events and API keys are kept; they are deactivated instead
+    status ENUM('ACTIVE','INACTIVE') NOT NULL DEFAULT 'ACTIVE',
+    deactivated_at TIMESTAMP NULL DEFAULT NULL,
     FOREIGN KEY (position_id) REFERENCES positions(position_id) ON DELETE SET NULL,
     FOREIGN KEY (house_id) REFERENCES houses(id) ON DELETE SET NULL
 );
@@ -120,20 +128,157 @@ CREATE TABLE committees (
 
 ALTER TABLE members ADD CONSTRAINT fk_committee FOREIGN KEY (committee_id) REFERENCES committees(committee_id) ON DELETE SET NULL;
 
+-- Table: member_emails
+-- Other Google accounts a member can sign in with, such as a personal Gmail address.
+CREATE TABLE member_emails (
+    email VARCHAR(255) PRIMARY KEY,
+    member_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE CASCADE
+);
+
+-- Table: member_terms
+-- A member's committee, position and house in a past term, recorded by cmd/term-rollover.
+CREATE TABLE member_terms (
+    member_id INT NOT NULL,
+    term_id INT NOT NULL,
+    committee_id VARCHAR(10),
+    position_id VARCHAR(10),
+    house_id INT,
+    started_on DATE,
+    ended_on DATE,
+    recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (member_id, term_id),
+    INDEX idx_member_terms_term (term_id),
+    FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE CASCADE,
+    FOREIGN KEY (term_id) REFERENCES terms(id) ON DELETE CASCADE,
+    FOREIGN KEY (committee_id) REFERENCES committees(committee_id) ON DELETE SET NULL,
+    FOREIGN KEY (position_id) REFERENCES positions(position_id) ON DELETE SET NULL,
+    FOREIGN KEY (house_id) REFERENCES houses(id) ON DELETE SET NULL
+);
+
+-- Table: member_privacy
+-- Contact fields a member hides from more callers than by default. Fields without a row use the default.
+CREATE TABLE member_privacy (
+    member_id INT NOT NULL,
+    field VARCHAR(32) NOT NULL,
+    visibility ENUM('COMMITTEE', 'OFFICERS', 'NOBODY') NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (member_id, field),
+    FOREIGN KEY (member_id) REFERENCES members(id) ON DELETE CASCADE
+);
+
 -- Table: api_keys
 CREATE TABLE api_keys (
     api_key_id INT AUTO_INCREMENT PRIMARY KEY,
     member_email VARCHAR(100) NOT NULL,
-    api_key_hash VARCHAR(255) NOT NULL,
+    api_key_hash VARCHAR(255) NOT NULL UNIQUE,
     project VARCHAR(255) DEFAULT NULL,
     allowed_origin VARCHAR(255) UNIQUE,
     is_dev BOOLEAN NOT NULL DEFAULT FALSE,
     is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    scopes VARCHAR(512) NOT NULL DEFAULT 'members:read members:pii committees:read',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     expires_at TIMESTAMP,
+    last_used_at TIMESTAMP NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    FOREIGN KEY (member_email) REFERENCES members(email) ON DELETE CASCADE
+);
+
+-- Table: api_key_daily_usage
+-- No foreign key to api_keys, so usage history outlives revoked keys
+CREATE TABLE api_key_daily_usage (
+    api_key_id INT NOT NULL,
+    usage_date DATE NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    rate_limited_count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (api_key_id, usage_date),
+    INDEX idx_api_key_daily_usage_date (usage_date)
+);
+
+-- Table: api_usage_buckets
+-- Requests per API key and route, aggregated into fixed time buckets.
+-- project is copied from api_keys so the history outlives revoked keys.
+CREATE TABLE api_usage_buckets (
+    bucket_start DATETIME NOT NULL,
+    api_key_id INT NOT NULL,
+    project VARCHAR(255) NOT NULL DEFAULT '',
+    route VARCHAR(255) NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    error_count BIGINT NOT NULL DEFAULT 0,
+    total_latency_ms BIGINT NOT NULL DEFAULT 0,
+    max_latency_ms BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (bucket_start, api_key_id, route),
+    INDEX idx_api_usage_buckets_project (project, bucket_start),
+    INDEX idx_api_usage_buckets_route (route, bucket_start)
+);
+
+-- Table: oauth_clients
+-- Apps that can "Sign in with LSCS". Public clients (SPAs, mobile apps) have no secret and rely on PKCE alone.
+CREATE TABLE oauth_clients (
+    client_id VARCHAR(64) PRIMARY KEY,
+    client_secret_hash VARCHAR(255),
+    name VARCHAR(255) NOT NULL,
+    redirect_uris TEXT NOT NULL,
+    owner_email VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (owner_email) REFERENCES members(email) ON DELETE CASCADE
+);
+
+-- Table: oauth_auth_codes
+CREATE TABLE oauth_auth_codes (
+    code_hash VARCHAR(255) PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    member_email VARCHAR(100) NOT NULL,
+    redirect_uri VARCHAR(2048) NOT NULL,
+    scope VARCHAR(255) NOT NULL,
+    nonce VARCHAR(255),
+    code_challenge VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_oauth_auth_codes_expires_at (expires_at),
+    FOREIGN KEY (client_id) REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
     FOREIGN KEY (member_email) REFERENCES members(email) ON DELETE CASCADE
 );
 
+-- Table: member_sessions
+-- Members signed in to a frontend. The refresh token rotates on every use; the one it replaced is
+-- kept so that a replayed token can be spotted and the session revoked.
+CREATE TABLE member_sessions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    member_email VARCHAR(100) NOT NULL,
+    refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    previous_token_hash VARCHAR(64),
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    client_ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    refreshed_at TIMESTAMP NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    INDEX idx_member_sessions_previous_token_hash (previous_token_hash),
+    INDEX idx_member_sessions_member_email (member_email),
+    FOREIGN KEY (member_email) REFERENCES members(email) ON DELETE CASCADE
+);
+
+-- Table: audit_events
+CREATE TABLE audit_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    actor_email VARCHAR(255) NOT NULL,
+    actor_api_key_id INT,
+    action VARCHAR(100) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    target_type VARCHAR(50),
+    target_id VARCHAR(255),
+    outcome VARCHAR(20) NOT NULL,
+    status_code INT NOT NULL,
+    client_ip VARCHAR(64),
+    details TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_events_created_at (created_at),
+    INDEX idx_audit_events_actor (actor_email, created_at),
+    INDEX idx_audit_events_target (target_type, target_id, created_at)
+);
+
 -- Table: events
 CREATE TABLE events (
     id INT AUTO_INCREMENT PRIMARY KEY,